@@ -1,6 +1,8 @@
 package main
 
 import (
+	"time"
+
 	"github.com/golang/be/internal/core_service/app"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxevent"
@@ -10,6 +12,10 @@ import (
 func main() {
 	fx.New(
 		app.PackageOptions, app.InternalOptions,
+		// must cover retries of first MongoDB ping, see connect_retries and connect_backoff of mongo config
+		fx.StartTimeout(2*time.Minute),
+		// must cover drain delay and shutdown timeout of http server
+		fx.StopTimeout(time.Minute),
 		fx.WithLogger(
 			func(logger *zap.Logger) fxevent.Logger {
 				return &fxevent.ZapLogger{Logger: logger}
@@ -0,0 +1,65 @@
+// Command media_gc deletes objects of uploaded media which no product references, then prints a report as JSON.
+//
+// Usage:
+//
+//	go run ./cmd/media_gc -dry-run=false
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/golang/be/internal/core_service/app"
+	"github.com/golang/be/internal/core_service/domain/media"
+	"go.uber.org/fx"
+)
+
+const stopTimeout = 30 * time.Second
+
+func main() {
+	dryRun := flag.Bool("dry-run", true, "only report orphaned objects, nothing is deleted")
+	flag.Parse()
+
+	if err := run(*dryRun); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(dryRun bool) error {
+	var mediaService media.UseCaseInterface
+
+	application := fx.New(
+		app.PackageOptions,
+		app.CoreOptions,
+		fx.Populate(&mediaService),
+		fx.NopLogger,
+	)
+
+	ctx := context.Background()
+	if err := application.Start(ctx); err != nil {
+		return err
+	}
+
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(ctx, stopTimeout)
+		defer cancel()
+
+		if err := application.Stop(stopCtx); err != nil {
+			log.Println("stop application fail:", err)
+		}
+	}()
+
+	report, err := mediaService.CollectGarbage(ctx, dryRun)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(report)
+}
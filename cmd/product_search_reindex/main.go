@@ -0,0 +1,61 @@
+// Command product_search_reindex sets search fields of products written without them, by older normalization
+// or whose name, tags or origin changed since, then prints number of products reindexed.
+// Server runs the same reindex periodically, see search.reindex_interval of config.
+//
+// Usage:
+//
+//	go run ./cmd/product_search_reindex
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/golang/be/internal/core_service/app"
+	"github.com/golang/be/internal/core_service/domain/product"
+	"go.uber.org/fx"
+)
+
+const stopTimeout = 30 * time.Second
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	var productService product.UseCaseInterface
+
+	application := fx.New(
+		app.PackageOptions,
+		app.CoreOptions,
+		fx.Populate(&productService),
+		fx.NopLogger,
+	)
+
+	ctx := context.Background()
+	if err := application.Start(ctx); err != nil {
+		return err
+	}
+
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(ctx, stopTimeout)
+		defer cancel()
+
+		if err := application.Stop(stopCtx); err != nil {
+			log.Println("stop application fail:", err)
+		}
+	}()
+
+	reindexed, err := productService.ReindexSearch(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("reindexed products:", reindexed)
+
+	return nil
+}
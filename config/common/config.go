@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
@@ -36,9 +37,47 @@ type (
 		Prod  bool   `yaml:"prod" env:"LOG_PROD"`
 	}
 
+	// Mongo specific mongo connection info, options which are not set keep values of ConnURI or driver defaults.
+	//
+	// Timeout specific time limit of operations whose context has no deadline, zero means no limit.
+	// If it is set, remaining time of operation is sent to server as maxTimeMS, so server stops working on it too.
+	// ReadPreference can be primary, primaryPreferred, secondary, secondaryPreferred or nearest,
+	// ListReadPreference specific read preference heavy list queries choose, they tolerate stale data.
+	// MaxStaleness specific how far behind primary a secondary can be to serve reads.
+	// ReadConcern can be local, available, majority, linearizable or snapshot.
+	// Compressors specific wire compression in order of preference, can be snappy, zlib or zstd.
+	// ConnectRetries specific how many times first ping is retried, waiting ConnectBackoff doubled each time
+	// up to ConnectMaxBackoff, so service can start while database is starting too.
 	Mongo struct {
-		DBName  string `yaml:"db_name"  env:"MONGO_DB_NAME"`
-		ConnURI string `yaml:"conn_uri" env:"MONGO_CONN_URI"`
+		DBName                 string            `yaml:"db_name"  env:"MONGO_DB_NAME"`
+		ConnURI                string            `yaml:"conn_uri" env:"MONGO_CONN_URI"`
+		AppName                string            `yaml:"app_name" env:"MONGO_APP_NAME"`
+		Timeout                time.Duration     `yaml:"timeout" env:"MONGO_TIMEOUT"`
+		ConnectTimeout         time.Duration     `yaml:"connect_timeout" env:"MONGO_CONNECT_TIMEOUT"`
+		ServerSelectionTimeout time.Duration     `yaml:"server_selection_timeout" env:"MONGO_SERVER_SELECTION_TIMEOUT"`
+		MinPoolSize            uint64            `yaml:"min_pool_size" env:"MONGO_MIN_POOL_SIZE"`
+		MaxPoolSize            uint64            `yaml:"max_pool_size" env:"MONGO_MAX_POOL_SIZE"`
+		MaxConnIdleTime        time.Duration     `yaml:"max_conn_idle_time" env:"MONGO_MAX_CONN_IDLE_TIME"`
+		RetryReads             *bool             `yaml:"retry_reads" env:"MONGO_RETRY_READS"`
+		RetryWrites            *bool             `yaml:"retry_writes" env:"MONGO_RETRY_WRITES"`
+		ReadPreference         string            `yaml:"read_preference" env:"MONGO_READ_PREFERENCE"`
+		ListReadPreference     string            `yaml:"list_read_preference" env:"MONGO_LIST_READ_PREFERENCE"`
+		MaxStaleness           time.Duration     `yaml:"max_staleness" env:"MONGO_MAX_STALENESS"`
+		ReadConcern            string            `yaml:"read_concern" env:"MONGO_READ_CONCERN"`
+		WriteConcern           MongoWriteConcern `yaml:"write_concern"`
+		Compressors            []string          `yaml:"compressors" env:"MONGO_COMPRESSORS"`
+		ConnectRetries         int               `yaml:"connect_retries" env:"MONGO_CONNECT_RETRIES"`
+		ConnectBackoff         time.Duration     `yaml:"connect_backoff" env:"MONGO_CONNECT_BACKOFF"`
+		ConnectMaxBackoff      time.Duration     `yaml:"connect_max_backoff" env:"MONGO_CONNECT_MAX_BACKOFF"`
+	}
+
+	// MongoWriteConcern specific acknowledgment of writes.
+	//
+	// W can be majority, number of members or name of tag set.
+	// Journal specific whether writes are acknowledged once they are written to journal.
+	MongoWriteConcern struct {
+		W       string `yaml:"w" env:"MONGO_WRITE_CONCERN_W"`
+		Journal *bool  `yaml:"journal" env:"MONGO_WRITE_CONCERN_JOURNAL"`
 	}
 
 	FirebaseStorage struct {
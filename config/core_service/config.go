@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
@@ -15,6 +16,18 @@ type (
 		Log             `yaml:"logger"`
 		Mongo           `yaml:"mongo"`
 		FirebaseStorage `yaml:"firebase_storage"`
+		Storage         `yaml:"storage"`
+		Media           `yaml:"media"`
+		Metrics         `yaml:"metrics"`
+		Tracing         `yaml:"tracing"`
+		RateLimit       `yaml:"rate_limit"`
+		Idempotency     `yaml:"idempotency"`
+		CORS            `yaml:"cors"`
+		Compression     `yaml:"compression"`
+		Request         `yaml:"request"`
+		Timeout         `yaml:"timeout"`
+		Cache           `yaml:"cache"`
+		Search          `yaml:"search"`
 	}
 
 	// App specific general information of service.
@@ -31,15 +44,210 @@ type (
 	// HTTP specific information of http rest api.s
 	//
 	// Port default running at 8080.
+	// DrainDelay specific how long server keeps accepting requests after readiness fails on shutdown,
+	// so load balancers have time to stop routing to it.
+	// ShutdownTimeout specific deadline for in-flight requests to finish on shutdown.
+	// HealthCheckTimeout specific timeout of each dependency check of readiness probe.
+	// HealthCheckCacheTTL specific how long result of checks of external services like Firebase is reused.
+	// ReadTimeout, ReadHeaderTimeout, WriteTimeout and IdleTimeout specific timeouts of http.Server,
+	// zero means no timeout, read and write timeouts must cover the largest upload.
+	// MaxHeaderBytes specific max size of request headers, zero means http.DefaultMaxHeaderBytes.
+	// H2C specific whether HTTP/2 without TLS is accepted, intended for internal traffic behind proxy.
+	// TrustedProxies specific CIDRs of proxies whose X-Forwarded-For is trusted to find client IP,
+	// client IP is remote address if empty.
 	HTTP struct {
-		Host string `yaml:"host" env:"HTTP_HOST"`
-		Port string `yaml:"port" env:"HTTP_PORT"`
+		Host                string        `yaml:"host" env:"HTTP_HOST"`
+		Port                string        `yaml:"port" env:"HTTP_PORT"`
+		DrainDelay          time.Duration `yaml:"drain_delay" env:"HTTP_DRAIN_DELAY"`
+		ShutdownTimeout     time.Duration `yaml:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT"`
+		HealthCheckTimeout  time.Duration `yaml:"health_check_timeout" env:"HTTP_HEALTH_CHECK_TIMEOUT"`
+		HealthCheckCacheTTL time.Duration `yaml:"health_check_cache_ttl" env:"HTTP_HEALTH_CHECK_CACHE_TTL"`
+		ReadTimeout         time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+		ReadHeaderTimeout   time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+		WriteTimeout        time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+		IdleTimeout         time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+		MaxHeaderBytes      int           `yaml:"max_header_bytes" env:"HTTP_MAX_HEADER_BYTES"`
+		H2C                 bool          `yaml:"h2c" env:"HTTP_H2C"`
+		TrustedProxies      []string      `yaml:"trusted_proxies" env:"HTTP_TRUSTED_PROXIES"`
+		TLS                 HTTPTLS       `yaml:"tls"`
 	}
 
-	// Log specific level of logger using in service.
+	// HTTPTLS specific TLS of http server, server serves HTTP/2 and HTTP/1.1 over TLS if CertFile is set.
+	//
+	// CertFile and KeyFile specific PEM encoded certificate and key, they are reloaded when changed on disk.
+	// ReloadInterval specific how often files are checked for changes, zero disables reload.
+	// ClientAuth specific client certificate verification for mTLS,
+	// can be none, request, require, verify_if_given or require_and_verify.
+	// ClientCAFile specific PEM encoded CAs client certificates are verified against.
+	HTTPTLS struct {
+		CertFile       string        `yaml:"cert_file" env:"HTTP_TLS_CERT_FILE"`
+		KeyFile        string        `yaml:"key_file" env:"HTTP_TLS_KEY_FILE"`
+		ReloadInterval time.Duration `yaml:"reload_interval" env:"HTTP_TLS_RELOAD_INTERVAL"`
+		ClientAuth     string        `yaml:"client_auth" env:"HTTP_TLS_CLIENT_AUTH"`
+		ClientCAFile   string        `yaml:"client_ca_file" env:"HTTP_TLS_CLIENT_CA_FILE"`
+	}
+
+	// Metrics specific Prometheus metrics endpoint.
+	//
+	// Port specific separate listener of metrics, so they aren't exposed with api,
+	// metrics are served by http server at Path if empty.
+	Metrics struct {
+		Host string `yaml:"host" env:"METRICS_HOST"`
+		Port string `yaml:"port" env:"METRICS_PORT"`
+		Path string `yaml:"path" env:"METRICS_PATH"`
+	}
+
+	// Tracing specific OpenTelemetry tracing.
+	//
+	// Exporter can be none, otlp, stdout or file, stdout and file are intended for local runs.
+	// Endpoint specific host:port of OTLP/HTTP collector, OTEL_EXPORTER_OTLP_ENDPOINT is used if empty.
+	// Insecure specific whether collector is reached without TLS.
+	// File specific file spans are appended to by file exporter.
+	// SampleRatio specific fraction of traces started by service that are sampled,
+	// traces started by callers follow their sampling decision.
+	Tracing struct {
+		Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER"`
+		Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT"`
+		Insecure    bool    `yaml:"insecure" env:"TRACING_INSECURE"`
+		File        string  `yaml:"file" env:"TRACING_FILE"`
+		SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+	}
+
+	// RateLimit specific token bucket limits of requests by client.
+	//
+	// Clients are identified by authenticated user, API key or IP in that order.
+	// APIKeyHeader specific header of API key, it must be verified by gateway in front of service
+	// since any value gets own bucket, API keys are ignored if empty.
+	// Groups specific limits of route groups: public, user and admin.
+	// Routes specific limits of single routes overriding limit of their group, keyed by method and path template,
+	// e.g. POST /api/v1/user/media/:mediaType.
+	RateLimit struct {
+		Enabled      bool                     `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+		APIKeyHeader string                   `yaml:"api_key_header" env:"RATE_LIMIT_API_KEY_HEADER"`
+		Groups       map[string]RateLimitRule `yaml:"groups"`
+		Routes       map[string]RateLimitRule `yaml:"routes"`
+	}
+
+	// CORS specific cross-origin policies of api.
+	//
+	// Default specific policy of routes outside groups and groups without own policy.
+	// Groups specific policies of route groups: public, user and admin,
+	// fields which are not set in group policy are taken from default policy.
+	CORS struct {
+		Default CORSPolicy            `yaml:"default"`
+		Groups  map[string]CORSPolicy `yaml:"groups"`
+	}
+
+	// CORSPolicy specific which origins can call api from browsers.
+	//
+	// AllowedOrigins can contain one wildcard per origin, e.g. https://*.example.com, * allows any origin.
+	// ExposedHeaders specific headers exposed in addition to request ID, rate limit and idempotency headers.
+	// AllowCredentials can't be set with * origin.
+	// MaxAge specific how long browsers cache preflight response.
+	CORSPolicy struct {
+		AllowedOrigins   []string      `yaml:"allowed_origins"`
+		AllowedMethods   []string      `yaml:"allowed_methods"`
+		AllowedHeaders   []string      `yaml:"allowed_headers"`
+		ExposedHeaders   []string      `yaml:"exposed_headers"`
+		AllowCredentials *bool         `yaml:"allow_credentials"`
+		MaxAge           time.Duration `yaml:"max_age"`
+	}
+
+	// Request specific limits of request bodies.
+	//
+	// MaxBodySize specific max size in bytes of request bodies of route groups: public, user and admin,
+	// zero means unlimited, upload routes replace it by limits of media.
+	// JSONMaxDepth specific max nesting of objects and arrays in JSON bodies, zero means unlimited.
+	// JSONMaxArrayLength specific max number of elements of a JSON array, zero means unlimited.
+	Request struct {
+		MaxBodySize        map[string]int64 `yaml:"max_body_size"`
+		JSONMaxDepth       int              `yaml:"json_max_depth" env:"REQUEST_JSON_MAX_DEPTH"`
+		JSONMaxArrayLength int              `yaml:"json_max_array_length" env:"REQUEST_JSON_MAX_ARRAY_LENGTH"`
+	}
+
+	// Timeout specific deadline of request context, operations honoring context such as Mongo queries fail after it.
+	//
+	// Default specific timeout of routes without own timeout, zero means no deadline.
+	// Routes specific timeouts of single routes keyed by method and path template,
+	// e.g. PATCH /api/v1/user/uploads/:uploadId.
+	Timeout struct {
+		Default time.Duration            `yaml:"default" env:"TIMEOUT_DEFAULT"`
+		Routes  map[string]time.Duration `yaml:"routes"`
+	}
+
+	// Cache specific in-process cache of read-heavy data, each instance keeps own cache.
+	//
+	// MaxEntries and MaxBytes specific bounds of cache, least recently used values are evicted first,
+	// zero means unbounded.
+	// Product specific caching of products by ID.
+	Cache struct {
+		MaxEntries int        `yaml:"max_entries" env:"CACHE_MAX_ENTRIES"`
+		MaxBytes   int64      `yaml:"max_bytes" env:"CACHE_MAX_BYTES"`
+		Product    CacheEntry `yaml:"product"`
+	}
+
+	// Search specific product search.
+	//
+	// ReindexInterval specific how often server reindexes products whose name, tags or origin were written
+	// by other services, first reindex runs at start, zero disables it.
+	Search struct {
+		ReindexInterval time.Duration `yaml:"reindex_interval" env:"SEARCH_REINDEX_INTERVAL"`
+	}
+
+	// CacheEntry specific how long values of a kind are cached.
+	//
+	// TTL specific how long a loaded value is kept, zero disables caching.
+	// NegativeTTL specific how long a value not found is kept, zero disables caching of not found.
+	CacheEntry struct {
+		TTL         time.Duration `yaml:"ttl"`
+		NegativeTTL time.Duration `yaml:"negative_ttl"`
+	}
+
+	// Compression specific gzip and brotli compression of api responses.
+	//
+	// MinSize specific min size in bytes of response to be compressed, smaller responses are not worth it.
+	// ContentTypes specific prefixes of content types compressed, e.g. text/ matches all text types.
+	// GzipLevel specific level from 1 to 9, BrotliLevel specific level from 0 to 11.
+	Compression struct {
+		Enabled      bool     `yaml:"enabled" env:"COMPRESSION_ENABLED"`
+		MinSize      int      `yaml:"min_size" env:"COMPRESSION_MIN_SIZE"`
+		ContentTypes []string `yaml:"content_types" env:"COMPRESSION_CONTENT_TYPES"`
+		GzipLevel    int      `yaml:"gzip_level" env:"COMPRESSION_GZIP_LEVEL"`
+		BrotliLevel  int      `yaml:"brotli_level" env:"COMPRESSION_BROTLI_LEVEL"`
+	}
+
+	// Idempotency specific Idempotency-Key handling of mutating requests.
+	//
+	// TTL specific how long response of a key is replayed.
+	// LockTimeout specific how long a request being processed holds its key, so key is freed if instance dies,
+	// it must be longer than the longest route timeout.
+	// MaxBodySize specific max size in bytes of request body buffered in memory to be hashed in fingerprint,
+	// larger bodies, e.g. uploads, are spooled to a temporary file up to MaxSpoolSize.
+	// Requests with bodies larger than MaxSpoolSize can't carry Idempotency-Key, they get 413.
+	// MaxResponseSize specific max size in bytes of response stored, key of larger response is released.
+	Idempotency struct {
+		Enabled         bool          `yaml:"enabled" env:"IDEMPOTENCY_ENABLED"`
+		TTL             time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL"`
+		LockTimeout     time.Duration `yaml:"lock_timeout" env:"IDEMPOTENCY_LOCK_TIMEOUT"`
+		MaxBodySize     int64         `yaml:"max_body_size" env:"IDEMPOTENCY_MAX_BODY_SIZE"`
+		MaxSpoolSize    int64         `yaml:"max_spool_size" env:"IDEMPOTENCY_MAX_SPOOL_SIZE"`
+		MaxResponseSize int           `yaml:"max_response_size" env:"IDEMPOTENCY_MAX_RESPONSE_SIZE"`
+	}
+
+	// RateLimitRule specific Requests allowed per Period with bursts of Burst requests, Burst is Requests if zero.
+	RateLimitRule struct {
+		Requests int           `yaml:"requests"`
+		Period   time.Duration `yaml:"period"`
+		Burst    int           `yaml:"burst"`
+	}
+
+	// Log specific level of logger using in service, level can be changed at runtime by admin api.
+	//
+	// RedactFields specific names of fields whose values are masked in addition to passwords and tokens.
 	Log struct {
-		Level string `yaml:"level" env:"LOG_LEVEL"`
-		Prod  bool   `yaml:"prod" env:"LOG_PROD"`
+		Level        string   `yaml:"level" env:"LOG_LEVEL"`
+		Prod         bool     `yaml:"prod" env:"LOG_PROD"`
+		RedactFields []string `yaml:"redact_fields" env:"LOG_REDACT_FIELDS"`
 	}
 
 	// Mongo specific mongo connection info.
@@ -51,6 +259,83 @@ type (
 	FirebaseStorage struct {
 		BucketName string `yaml:"bucket_name" env:"FIREBASE_STORAGE_BUCKET"`
 	}
+
+	// Storage specific object storage keeping uploaded media.
+	//
+	// Driver can be firebase or local, local driver keeps objects in filesystem and is intended for development.
+	Storage struct {
+		Driver string       `yaml:"driver" env:"STORAGE_DRIVER"`
+		Local  LocalStorage `yaml:"local"`
+	}
+
+	// Media specific limits of uploaded media, sizes are in bytes.
+	//
+	// MaxChunkSize specific max size of a chunk in resumable upload.
+	// UploadSessionTTL specific how long an unfinished resumable upload can be continued.
+	// SignedURLTTL specific how long a signed URL of private media is valid.
+	Media struct {
+		MaxImageSize          int64         `yaml:"max_image_size" env:"MEDIA_MAX_IMAGE_SIZE"`
+		MaxVideoSize          int64         `yaml:"max_video_size" env:"MEDIA_MAX_VIDEO_SIZE"`
+		MaxThreeDimensionSize int64         `yaml:"max_three_dimension_size" env:"MEDIA_MAX_THREE_DIMENSION_SIZE"`
+		MaxChunkSize          int64         `yaml:"max_chunk_size" env:"MEDIA_MAX_CHUNK_SIZE"`
+		UploadSessionTTL      time.Duration `yaml:"upload_session_ttl" env:"MEDIA_UPLOAD_SESSION_TTL"`
+		SignedURLTTL          time.Duration `yaml:"signed_url_ttl" env:"MEDIA_SIGNED_URL_TTL"`
+		Image                 ImageMedia    `yaml:"image"`
+		ThreeDimension        ModelMedia    `yaml:"three_dimension"`
+		GC                    MediaGC       `yaml:"gc"`
+	}
+
+	// MediaGC specific garbage collection of objects no product references.
+	//
+	// Prefixes specific where objects are listed, only objects created by upload must be there.
+	// GracePeriod specific how long an object is kept before it is collected, so it can be attached to product.
+	// Interval specific how often collection runs in server, zero disables it.
+	// DryRun specific whether scheduled collection only reports orphans without deleting them.
+	MediaGC struct {
+		Prefixes    []string      `yaml:"prefixes" env:"MEDIA_GC_PREFIXES"`
+		GracePeriod time.Duration `yaml:"grace_period" env:"MEDIA_GC_GRACE_PERIOD"`
+		Interval    time.Duration `yaml:"interval" env:"MEDIA_GC_INTERVAL"`
+		DryRun      bool          `yaml:"dry_run" env:"MEDIA_GC_DRY_RUN"`
+	}
+
+	// ModelMedia specific complexity limits of uploaded 3D models, zero means unlimited.
+	ModelMedia struct {
+		MaxTriangles int `yaml:"max_triangles" env:"MEDIA_3D_MAX_TRIANGLES"`
+		MaxVertices  int `yaml:"max_vertices" env:"MEDIA_3D_MAX_VERTICES"`
+		MaxMeshes    int `yaml:"max_meshes" env:"MEDIA_3D_MAX_MESHES"`
+		MaxMaterials int `yaml:"max_materials" env:"MEDIA_3D_MAX_MATERIALS"`
+		MaxTextures  int `yaml:"max_textures" env:"MEDIA_3D_MAX_TEXTURES"`
+	}
+
+	// ImageMedia specific variants generated from uploaded images in background.
+	//
+	// VariantWidths specific widths in pixels of variants, no variant is generated if empty.
+	// VariantFormats specific encoding of variants, can be webp or jpeg. WebP variants are lossless, they suit
+	// graphics such as logos, WebP variants of photos are often larger than JPEG ones.
+	// MaxPixels specific max width * height of image to be decoded, larger images get no variants.
+	// VariantWait specific how long upload waits for variants to return them with media, zero returns media at once.
+	// Variants generated later are kept by media record and upload session, they are copied to product attaching it.
+	// Workers specific number of images processed concurrently, QueueSize specific number of images waiting.
+	ImageMedia struct {
+		VariantWidths  []int         `yaml:"variant_widths" env:"MEDIA_IMAGE_VARIANT_WIDTHS"`
+		VariantFormats []string      `yaml:"variant_formats" env:"MEDIA_IMAGE_VARIANT_FORMATS"`
+		JPEGQuality    int           `yaml:"jpeg_quality" env:"MEDIA_IMAGE_JPEG_QUALITY"`
+		MaxPixels      int64         `yaml:"max_pixels" env:"MEDIA_IMAGE_MAX_PIXELS"`
+		VariantWait    time.Duration `yaml:"variant_wait" env:"MEDIA_IMAGE_VARIANT_WAIT"`
+		Workers        int           `yaml:"workers" env:"MEDIA_IMAGE_WORKERS"`
+		QueueSize      int           `yaml:"queue_size" env:"MEDIA_IMAGE_QUEUE_SIZE"`
+	}
+
+	// LocalStorage specific local filesystem storage.
+	//
+	// RootDir directory objects are written to.
+	// BaseURL public URL objects are served at by http server, e.g. http://localhost:8080/files.
+	// SigningKey secret of HMAC signed URLs, a random key is used if empty so URLs don't survive restarts.
+	LocalStorage struct {
+		RootDir    string `yaml:"root_dir" env:"STORAGE_LOCAL_ROOT_DIR"`
+		BaseURL    string `yaml:"base_url" env:"STORAGE_LOCAL_BASE_URL"`
+		SigningKey string `yaml:"signing_key" env:"STORAGE_LOCAL_SIGNING_KEY"`
+	}
 )
 
 const EnvProd = "production"
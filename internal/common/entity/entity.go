@@ -206,8 +206,69 @@ func (t *UnixTimestamp) SetBSON(raw bson.RawValue) error {
 	return nil
 }
 
+// Media types, each type accepts its own set of content types.
+const (
+	MediaTypeImage          = "image"
+	MediaTypeVideo          = "video"
+	MediaTypeThreeDimension = "three_dimension"
+)
+
+// Media define a file uploaded to object storage.
+//
+// Key specific location of file in object storage.
+// Private specific whether file can only be downloaded by signed URL.
+// Variants specific resized copies of image, they are generated in background after upload.
+// VideoInfo specific metadata read from container of video.
+// ModelInfo specific statistics of 3D model.
 type Media struct {
-	URL          string `bson:"url" json:"url"`
-	Type         string `bson:"type" json:"type"`
-	ThumbnailURL string `bson:"thumbnail_url" json:"thumbnail_url"`
+	URL          string         `bson:"url" json:"url"`
+	Type         string         `bson:"type" json:"type"`
+	ThumbnailURL string         `bson:"thumbnail_url" json:"thumbnail_url"`
+	ContentType  string         `bson:"content_type,omitempty" json:"content_type,omitempty"`
+	Size         int64          `bson:"size,omitempty" json:"size,omitempty"`
+	Key          string         `bson:"key,omitempty" json:"-"`
+	Private      bool           `bson:"private,omitempty" json:"private,omitempty"`
+	Variants     []MediaVariant `bson:"variants,omitempty" json:"variants,omitempty"`
+	VideoInfo    *VideoInfo     `bson:"video_info,omitempty" json:"video_info,omitempty"`
+	ModelInfo    *ModelInfo     `bson:"model_info,omitempty" json:"model_info,omitempty"`
+}
+
+// VideoInfo define metadata of video media.
+//
+// Duration specific length in seconds, zero if container doesn't declare it.
+// Bitrate specific average bits per second.
+type VideoInfo struct {
+	Container  string  `bson:"container" json:"container"`
+	Duration   float64 `bson:"duration" json:"duration"`
+	Width      int     `bson:"width" json:"width"`
+	Height     int     `bson:"height" json:"height"`
+	Codec      string  `bson:"codec" json:"codec"`
+	AudioCodec string  `bson:"audio_codec,omitempty" json:"audio_codec,omitempty"`
+	Bitrate    int64   `bson:"bitrate" json:"bitrate"`
+}
+
+// MediaVariant define a resized copy of image media, stored next to the original.
+type MediaVariant struct {
+	URL         string `bson:"url" json:"url"`
+	Width       int    `bson:"width" json:"width"`
+	Height      int    `bson:"height" json:"height"`
+	ContentType string `bson:"content_type" json:"content_type"`
+	Key         string `bson:"key" json:"-"`
+}
+
+// ModelInfo define statistics of 3D model media.
+//
+// BoundsMin and BoundsMax specific axis aligned bounding box of model in meters, as x, y, z.
+type ModelInfo struct {
+	Version    string     `bson:"version" json:"version"`
+	Generator  string     `bson:"generator,omitempty" json:"generator,omitempty"`
+	Meshes     int        `bson:"meshes" json:"meshes"`
+	Materials  int        `bson:"materials" json:"materials"`
+	Triangles  int        `bson:"triangles" json:"triangles"`
+	Vertices   int        `bson:"vertices" json:"vertices"`
+	Nodes      int        `bson:"nodes" json:"nodes"`
+	Textures   int        `bson:"textures" json:"textures"`
+	Animations int        `bson:"animations" json:"animations"`
+	BoundsMin  [3]float64 `bson:"bounds_min" json:"bounds_min"`
+	BoundsMax  [3]float64 `bson:"bounds_max" json:"bounds_max"`
 }
@@ -1,8 +1,13 @@
 package admin
 
-import depinjection "github.com/golang/be/pkg/common/dep_injection"
+import (
+	"github.com/golang/be/internal/core_service/api/handler/admin/logging"
+	depinjection "github.com/golang/be/pkg/common/dep_injection"
+)
 
 var Module = depinjection.BulkProvide(
-	[]any{},
+	[]any{
+		logging.NewController,
+	},
 	"admin-controller",
 )
@@ -0,0 +1,105 @@
+package logging
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/be/internal/core_service/api"
+	"github.com/golang/be/internal/core_service/api/middleware/authen"
+	logginghttp "github.com/golang/be/internal/core_service/entity/logging/http"
+	"github.com/golang/be/pkg/common/httpresp"
+	"github.com/golang/be/pkg/common/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+type Controller struct{}
+
+func NewController() api.Controller {
+	return &Controller{}
+}
+
+func (c *Controller) RegisterRoutes(route gin.IRoutes) {
+	route.GET("/log-level", authen.RequirePermission(authen.PermissionLoggingRead), c.GetLevel)
+	route.PUT("/log-level", authen.RequirePermission(authen.PermissionLoggingWrite), c.SetLevel)
+	route.DELETE("/log-level", authen.RequirePermission(authen.PermissionLoggingWrite), c.ResetLevel)
+}
+
+// GetLevel 	Get log level
+// @Summary 	Get log level
+// @Description Get level of logger and levels overridden for packages
+// @Tags        logging
+// @Produce     json
+// @Success     200  {object} httpresp.Response{data=logger.LevelState}
+// @Failure     403  {object} httpresp.Response
+// @Router      /admin/log-level [get].
+func (c *Controller) GetLevel(g *gin.Context) {
+	httpresp.Success(g, &httpresp.Response{Data: logger.Levels()})
+}
+
+// SetLevel 	Set log level
+// @Summary 	Set log level
+// @Description Set level of logger or of a package and its sub packages, level reverts after ttl if set
+// @Tags        logging
+// @Accept      json
+// @Produce     json
+// @Param       body  body     logginghttp.SetLevelReq true "Level"
+// @Success     200  {object} httpresp.Response{data=logger.LevelState}
+// @Failure     400  {object} httpresp.Response
+// @Failure     403  {object} httpresp.Response
+// @Router      /admin/log-level [put].
+func (c *Controller) SetLevel(g *gin.Context) {
+	var req logginghttp.SetLevelReq
+	if !httpresp.BindStrictJSON(g, &req) {
+		return
+	}
+
+	level, err := zapcore.ParseLevel(req.Level)
+	if err != nil {
+		httpresp.InvalidFieldTypeError(g, "level")
+
+		return
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl <= 0 {
+			httpresp.InvalidFieldTypeError(g, "ttl")
+
+			return
+		}
+	}
+
+	if req.Package == "" {
+		logger.SetLevel(level, ttl)
+	} else {
+		logger.SetPackageLevel(req.Package, level, ttl)
+	}
+
+	logger.WithContext(g).Infow(
+		"log level changed",
+		"level", level.String(),
+		"package", req.Package,
+		"ttl", ttl.String(),
+	)
+
+	httpresp.Success(g, &httpresp.Response{Data: logger.Levels()})
+}
+
+// ResetLevel 	Reset log level
+// @Summary 	Reset log level
+// @Description Revert level of logger to configured level, or remove level of package if package is set
+// @Tags        logging
+// @Produce     json
+// @Param       package  query    string false  "Package path"
+// @Success     200  {object} httpresp.Response{data=logger.LevelState}
+// @Failure     403  {object} httpresp.Response
+// @Router      /admin/log-level [delete].
+func (c *Controller) ResetLevel(g *gin.Context) {
+	if pkg := g.Query("package"); pkg != "" {
+		logger.ResetPackageLevel(pkg)
+	} else {
+		logger.ResetLevel()
+	}
+
+	httpresp.Success(g, &httpresp.Response{Data: logger.Levels()})
+}
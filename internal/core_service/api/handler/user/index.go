@@ -1,6 +1,7 @@
 package user
 
 import (
+	"github.com/golang/be/internal/core_service/api/handler/user/media"
 	"github.com/golang/be/internal/core_service/api/handler/user/product"
 	depinjection "github.com/golang/be/pkg/common/dep_injection"
 )
@@ -8,6 +9,7 @@ import (
 var Module = depinjection.BulkProvide(
 	[]any{
 		product.NewController,
+		media.NewController,
 	},
 	"user-controller",
 )
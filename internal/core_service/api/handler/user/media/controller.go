@@ -0,0 +1,295 @@
+package media
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	cmentity "github.com/golang/be/internal/common/entity"
+	"github.com/golang/be/internal/core_service/api"
+	"github.com/golang/be/internal/core_service/api/middleware"
+	"github.com/golang/be/internal/core_service/api/middleware/authen"
+	mediadomain "github.com/golang/be/internal/core_service/domain/media"
+	uploadhttp "github.com/golang/be/internal/core_service/entity/upload/http"
+	"github.com/golang/be/pkg/common/httpresp"
+	"github.com/golang/be/pkg/common/logger"
+)
+
+const (
+	// UploadOffsetHeader specific offset of chunk in request and offset of next chunk in response.
+	UploadOffsetHeader = "Upload-Offset"
+
+	// multipartOverhead specific room for multipart boundaries and form fields on top of file size.
+	multipartOverhead = 1 << 20
+)
+
+type Controller struct {
+	mediaService mediadomain.UseCaseInterface
+}
+
+func NewController(
+	mediaService mediadomain.UseCaseInterface,
+) api.Controller {
+	return &Controller{
+		mediaService: mediaService,
+	}
+}
+
+func (c *Controller) RegisterRoutes(route gin.IRoutes) {
+	route.POST("/media/:mediaType", c.Upload)
+	route.POST("/uploads", c.CreateUploadSession)
+	route.GET("/uploads/:uploadId", c.GetUploadSession)
+	route.PATCH("/uploads/:uploadId", c.AppendChunk)
+}
+
+// Upload 		Upload a media file
+// @Summary 	Upload a media file
+// @Description Upload image, video or 3D model, content type is detected from file content
+// @Tags        media
+// @Accept      multipart/form-data
+// @Produce     json
+// @Param       mediaType  path     string true "Media type" Enums(image, video, three_dimension)
+// @Param       file       formData file   true "File"
+// @Param       private    formData bool   false "Only allow download by signed URL"
+// @Param       poster     formData file   false "Poster image of video, it becomes thumbnail of video"
+// @Success     200  {object} httpresp.Response{data=cmentity.Media}
+// @Failure     400  {object} httpresp.Response
+// @Failure     413  {object} httpresp.Response
+// @Failure     415  {object} httpresp.Response
+// @Failure     422  {object} httpresp.Response
+// @Failure     500  {object} httpresp.Response
+// @Router      /user/media/{mediaType} [post].
+func (c *Controller) Upload(g *gin.Context) {
+	mediaType := g.Param("mediaType")
+	if !mediadomain.IsValidMediaType(mediaType) {
+		httpresp.Error(g, http.StatusBadRequest, httpresp.ErrKeyMediaInvalidType.Error(), nil)
+
+		return
+	}
+
+	maxSize := c.mediaService.MaxSize(mediaType)
+
+	// video may come with a poster image
+	maxBodySize := maxSize + multipartOverhead
+	if mediaType == cmentity.MediaTypeVideo {
+		maxBodySize += c.mediaService.MaxSize(cmentity.MediaTypeImage)
+	}
+
+	middleware.LimitBody(g, maxBodySize)
+
+	fileHeader, err := g.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			fileTooLargeError(g, maxSize)
+
+			return
+		}
+
+		httpresp.MissingRequiredFieldError(g, "file")
+
+		return
+	}
+
+	if fileHeader.Size > maxSize {
+		fileTooLargeError(g, maxSize)
+
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		httpresp.InternalServerError(g)
+
+		return
+	}
+	defer file.Close()
+
+	private, _ := strconv.ParseBool(g.PostForm("private"))
+
+	media, err := c.mediaService.Upload(
+		g,
+		mediadomain.FileInfo{MediaType: mediaType, FileName: fileHeader.Filename, Private: private},
+		file,
+	)
+	if err != nil {
+		c.handleError(g, mediaType, err)
+
+		return
+	}
+
+	if posterHeader, err := g.FormFile("poster"); err == nil && mediaType == cmentity.MediaTypeVideo {
+		if err = c.uploadPoster(g, media, posterHeader); err != nil {
+			c.handleError(g, cmentity.MediaTypeImage, err)
+
+			return
+		}
+	}
+
+	httpresp.Success(g, &httpresp.Response{Data: media})
+}
+
+func (c *Controller) uploadPoster(g *gin.Context, video *cmentity.Media, posterHeader *multipart.FileHeader) error {
+	if posterHeader.Size > c.mediaService.MaxSize(cmentity.MediaTypeImage) {
+		return mediadomain.ErrFileTooLarge
+	}
+
+	poster, err := posterHeader.Open()
+	if err != nil {
+		return err
+	}
+	defer poster.Close()
+
+	_, err = c.mediaService.UploadPoster(g, video, posterHeader.Filename, poster)
+
+	return err
+}
+
+// CreateUploadSession 	Start a resumable upload
+// @Summary 	Start a resumable upload
+// @Description Start a resumable upload, chunks are then sent by PATCH /user/uploads/{uploadId}
+// @Tags        media
+// @Accept      json
+// @Produce     json
+// @Param       body  body     uploadhttp.CreateSessionReq true "Upload information"
+// @Success     200  {object} httpresp.Response{data=upload.Session}
+// @Failure     400  {object} httpresp.Response
+// @Failure     413  {object} httpresp.Response
+// @Failure     500  {object} httpresp.Response
+// @Router      /user/uploads [post].
+func (c *Controller) CreateUploadSession(g *gin.Context) {
+	var req uploadhttp.CreateSessionReq
+	if !httpresp.BindStrictJSON(g, &req) {
+		return
+	}
+
+	session, err := c.mediaService.CreateUploadSession(
+		g,
+		authen.GetUserID(g),
+		mediadomain.FileInfo{MediaType: req.MediaType, FileName: req.FileName, Private: req.Private},
+		req.Size,
+	)
+	if err != nil {
+		c.handleError(g, req.MediaType, err)
+
+		return
+	}
+
+	g.Header(UploadOffsetHeader, strconv.FormatInt(session.UploadedSize, 10))
+	httpresp.Success(g, &httpresp.Response{Data: session})
+}
+
+// GetUploadSession 	Get status of a resumable upload
+// @Summary 	Get status of a resumable upload
+// @Description Get status of a resumable upload, uploaded_size is the offset to resume from
+// @Tags        media
+// @Produce     json
+// @Param       uploadId  path    string true  "Upload ID"
+// @Success     200  {object} httpresp.Response{data=upload.Session}
+// @Failure     404  {object} httpresp.Response
+// @Failure     500  {object} httpresp.Response
+// @Router      /user/uploads/{uploadId} [get].
+func (c *Controller) GetUploadSession(g *gin.Context) {
+	session, err := c.mediaService.GetUploadSession(g, authen.GetUserID(g), g.Param("uploadId"))
+	if err != nil {
+		c.handleError(g, "", err)
+
+		return
+	}
+
+	g.Header(UploadOffsetHeader, strconv.FormatInt(session.UploadedSize, 10))
+	httpresp.Success(g, &httpresp.Response{Data: session})
+}
+
+// AppendChunk 	Upload a chunk of a resumable upload
+// @Summary 	Upload a chunk of a resumable upload
+// @Description Upload a chunk starting at Upload-Offset, media is returned in session once the last chunk arrives
+// @Tags        media
+// @Accept      application/offset+octet-stream
+// @Produce     json
+// @Param       uploadId       path   string true "Upload ID"
+// @Param       Upload-Offset  header int    true "Offset of chunk"
+// @Success     200  {object} httpresp.Response{data=upload.Session}
+// @Failure     400  {object} httpresp.Response
+// @Failure     404  {object} httpresp.Response
+// @Failure     409  {object} httpresp.Response
+// @Failure     413  {object} httpresp.Response
+// @Failure     415  {object} httpresp.Response
+// @Failure     500  {object} httpresp.Response
+// @Router      /user/uploads/{uploadId} [patch].
+func (c *Controller) AppendChunk(g *gin.Context) {
+	offset, err := strconv.ParseInt(g.GetHeader(UploadOffsetHeader), 10, 64)
+	if err != nil || offset < 0 {
+		httpresp.InvalidFieldTypeError(g, UploadOffsetHeader)
+
+		return
+	}
+
+	// size of chunk is limited by session
+	middleware.LimitBody(g, 0)
+
+	session, err := c.mediaService.AppendChunk(g, authen.GetUserID(g), g.Param("uploadId"), offset, g.Request.Body)
+	if err != nil {
+		c.handleError(g, "", err)
+
+		return
+	}
+
+	g.Header(UploadOffsetHeader, strconv.FormatInt(session.UploadedSize, 10))
+	httpresp.Success(g, &httpresp.Response{Data: session})
+}
+
+func (c *Controller) handleError(g *gin.Context, mediaType string, err error) {
+	var limitErr *mediadomain.ModelLimitError
+
+	switch {
+	case errors.Is(err, mediadomain.ErrInvalidMediaType):
+		httpresp.Error(g, http.StatusBadRequest, httpresp.ErrKeyMediaInvalidType.Error(), nil)
+	case errors.Is(err, mediadomain.ErrUnsupportedContentType):
+		httpresp.Error(g, http.StatusUnsupportedMediaType, httpresp.ErrKeyMediaUnsupportedContentType.Error(), nil)
+	case errors.Is(err, mediadomain.ErrFileTooLarge):
+		fileTooLargeError(g, c.mediaService.MaxSize(mediaType))
+	case errors.Is(err, mediadomain.ErrUploadSessionNotFound):
+		httpresp.NotFound(g)
+	case errors.Is(err, mediadomain.ErrUploadSessionCompleted):
+		httpresp.Error(g, http.StatusConflict, httpresp.ErrKeyMediaUploadCompleted.Error(), nil)
+	case errors.Is(err, mediadomain.ErrUploadSessionFailed):
+		httpresp.Error(g, http.StatusConflict, httpresp.ErrKeyMediaUploadFailed.Error(), nil)
+	case errors.Is(err, mediadomain.ErrUploadOffsetMismatch):
+		httpresp.Error(g, http.StatusConflict, httpresp.ErrKeyMediaUploadOffsetMismatch.Error(), nil)
+	case errors.Is(err, mediadomain.ErrUploadChunkEmpty):
+		httpresp.Error(g, http.StatusBadRequest, httpresp.ErrKeyMediaUploadChunkEmpty.Error(), nil)
+	case errors.Is(err, mediadomain.ErrUploadChunkTooLarge):
+		httpresp.Error(g, http.StatusRequestEntityTooLarge, httpresp.ErrKeyMediaUploadChunkTooLarge.Error(), nil)
+	case errors.Is(err, mediadomain.ErrInvalidContainer):
+		httpresp.Error(g, http.StatusUnprocessableEntity, httpresp.ErrKeyMediaInvalidContainer.Error(), nil)
+	case errors.Is(err, mediadomain.ErrInvalidModel):
+		httpresp.Error(g, http.StatusUnprocessableEntity, httpresp.ErrKeyMediaInvalidModel.Error(), nil)
+	case errors.Is(err, mediadomain.ErrModelExternalReference):
+		httpresp.Error(g, http.StatusUnprocessableEntity, httpresp.ErrKeyMediaModelExternalReference.Error(), nil)
+	case errors.As(err, &limitErr):
+		httpresp.Error(
+			g,
+			http.StatusUnprocessableEntity,
+			httpresp.ErrKeyMediaModelTooComplex.Error(),
+			map[string]any{"limit": limitErr.Name, "max": limitErr.Max},
+		)
+	default:
+		logger.WithContext(g).Errorw(
+			"upload media fail",
+			"err", err,
+		)
+		httpresp.InternalServerError(g)
+	}
+}
+
+func fileTooLargeError(g *gin.Context, maxSize int64) {
+	httpresp.Error(
+		g,
+		http.StatusRequestEntityTooLarge,
+		httpresp.ErrKeyMediaFileTooLarge.Error(),
+		map[string]any{"max_size": maxSize},
+	)
+}
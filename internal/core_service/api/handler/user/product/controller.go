@@ -1,36 +1,66 @@
 package product
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
+	cmentity "github.com/golang/be/internal/common/entity"
 	"github.com/golang/be/internal/core_service/api"
+	"github.com/golang/be/internal/core_service/api/middleware"
+	"github.com/golang/be/internal/core_service/api/middleware/authen"
+	mediadomain "github.com/golang/be/internal/core_service/domain/media"
 	productdomain "github.com/golang/be/internal/core_service/domain/product"
+	"github.com/golang/be/internal/core_service/entity/product"
+	producthttp "github.com/golang/be/internal/core_service/entity/product/http"
 	"github.com/golang/be/pkg/common/httpresp"
+	"github.com/golang/be/pkg/common/pagination"
+	"github.com/golang/be/pkg/common/textsearch"
+)
+
+const (
+	// multipartOverhead specific room for multipart boundaries and form fields on top of file size.
+	multipartOverhead = 1 << 20
+	// maxSearchQueryLength specific max length in bytes of search query.
+	maxSearchQueryLength = 200
 )
 
 type Controller struct {
-	prodService productdomain.UseCaseInterface
+	prodService  productdomain.UseCaseInterface
+	mediaService mediadomain.UseCaseInterface
 }
 
 func NewController(
 	prodService productdomain.UseCaseInterface,
+	mediaService mediadomain.UseCaseInterface,
 ) api.Controller {
 	return &Controller{
-		prodService: prodService,
+		prodService:  prodService,
+		mediaService: mediaService,
 	}
 }
 
 func (c *Controller) RegisterRoutes(route gin.IRoutes) {
-	route.GET("/products/:productId", c.GetProduct)
+	route.GET("/products", middleware.Cache(middleware.CachePrivateRevalidate), c.ListProducts)
+	route.GET("/products/search", c.SearchProducts)
+	route.GET("/products/:productId", middleware.Cache(middleware.CachePrivateRevalidate), c.GetProduct)
+	route.GET("/products/:productId/media/:mediaType/signed-url", c.GetMediaSignedURL)
+	route.PUT("/products/:productId/media/image", c.AttachImage)
+	route.PUT("/products/:productId/media/video/poster", c.UploadVideoPoster)
 }
 
 // GetProduct 	Get product by id
 // @Summary 	Get product by id
-// @Description Get product by id
+// @Description Get product by id, product must belong to organization of user
 // @Tags        product
 // @Accept      json
 // @Produce     json
 // @Param       productId  path    string true  "Product ID"
 // @Success     200  {object} httpresp.Response{data=string}
+// @Failure     403  {object} httpresp.Response
+// @Failure     404  {object} httpresp.Response
 // @Failure     500  {object} httpresp.Response
 // @Router      /user/products/{productId} [get].
 func (c *Controller) GetProduct(g *gin.Context) {
@@ -41,8 +71,14 @@ func (c *Controller) GetProduct(g *gin.Context) {
 		return
 	}
 
-	curProduct, err := c.prodService.GetProduct(g, &productID)
+	curProduct, err := c.prodService.GetProduct(g, &productID, authen.GetOrganizationID(g))
 	if err != nil {
+		if errors.Is(err, productdomain.ErrNoPermission) {
+			httpresp.ForbiddenError(g, err.Error())
+
+			return
+		}
+
 		httpresp.InternalServerError(g)
 
 		return
@@ -59,3 +95,318 @@ func (c *Controller) GetProduct(g *gin.Context) {
 
 	httpresp.Success(g, &res)
 }
+
+// ListProducts 	List products
+// @Summary 	List products
+// @Description List products of organization of user matching filter with counts of type, origin, tags and
+// @Description rating buckets among all matching products in facets.
+// @Description Filter is given by filter[field]=value or filter[field][operator]=value, e.g. filter[rating_score][gte]=4,
+// @Description in, nin and all operators take values separated by comma, e.g. filter[tags][all]=organic,gift.
+// @Description Fields are type, origin, tags, product_name, rating_score, total_item, created_at and status.
+// @Tags        product
+// @Accept      json
+// @Produce     json
+// @Param       sort   query    string false "Fields to sort by, descending if prefixed by -" default(-created_at)
+// @Param       fields query    string false "Fields returned in addition to id, e.g. product_name,image"
+// @Param       page   query    int    false "Page" default(1)
+// @Param       limit  query    int    false "Page size" default(50)
+// @Success     200  {object} httpresp.Response{data=[]product.Product}
+// @Failure     400  {object} httpresp.Response
+// @Failure     500  {object} httpresp.Response
+// @Security    ApiKeyAuth
+// @Router      /user/products [get].
+func (c *Controller) ListProducts(g *gin.Context) {
+	query := httpresp.ParseQuery(g, product.QuerySchema)
+	if query == nil {
+		return
+	}
+
+	var paging pagination.Pagination
+	if err := g.ShouldBindQuery(&paging); err != nil {
+		httpresp.InvalidFieldTypeError(g, err.Error())
+
+		return
+	}
+
+	paging.Fulfill()
+
+	products, facets, err := c.prodService.ListProducts(g, authen.GetOrganizationID(g), query, &paging)
+	if err != nil {
+		if errors.Is(err, productdomain.ErrNoPermission) {
+			httpresp.ForbiddenError(g, err.Error())
+
+			return
+		}
+
+		httpresp.InternalServerError(g)
+
+		return
+	}
+
+	data := make([]any, len(products))
+	for i := range products {
+		if data[i], err = query.Select(&products[i]); err != nil {
+			httpresp.InternalServerError(g)
+
+			return
+		}
+	}
+
+	res := httpresp.Response{
+		Data:       data,
+		Pagination: &paging,
+		Facets:     facets,
+	}
+
+	httpresp.Success(g, &res)
+}
+
+// SearchProducts 	Search products
+// @Summary 	Search products
+// @Description Search products of organization of user by name, tags and origin regardless of case and
+// @Description Vietnamese diacritics, e.g. duoc matches Được. Products are ranked by relevance, with autocomplete
+// @Description last word matches words starting with it and products are ranked by rating.
+// @Tags        product
+// @Accept      json
+// @Produce     json
+// @Param       q             query    string true  "Search query"
+// @Param       autocomplete  query    bool   false "Match last word by prefix"
+// @Param       page          query    int    false "Page" default(1)
+// @Param       limit         query    int    false "Page size" default(50)
+// @Success     200  {object} httpresp.Response{data=[]producthttp.SearchProductResp}
+// @Failure     400  {object} httpresp.Response
+// @Failure     403  {object} httpresp.Response
+// @Failure     500  {object} httpresp.Response
+// @Security    ApiKeyAuth
+// @Router      /user/products/search [get].
+func (c *Controller) SearchProducts(g *gin.Context) {
+	query := g.Query("q")
+	if query == "" {
+		httpresp.MissingRequiredFieldError(g, "q")
+
+		return
+	}
+
+	if len(query) > maxSearchQueryLength {
+		httpresp.InvalidFieldTypeError(g, "q")
+
+		return
+	}
+
+	autocomplete, err := strconv.ParseBool(g.DefaultQuery("autocomplete", "false"))
+	if err != nil {
+		httpresp.InvalidFieldTypeError(g, "autocomplete")
+
+		return
+	}
+
+	var paging pagination.Pagination
+	if err = g.ShouldBindQuery(&paging); err != nil {
+		httpresp.InvalidFieldTypeError(g, err.Error())
+
+		return
+	}
+
+	paging.Fulfill()
+
+	hits, err := c.prodService.SearchProducts(
+		g,
+		authen.GetOrganizationID(g),
+		textsearch.ParseQuery(query, autocomplete),
+		&paging,
+	)
+	if err != nil {
+		if errors.Is(err, productdomain.ErrNoPermission) {
+			httpresp.ForbiddenError(g, err.Error())
+
+			return
+		}
+
+		httpresp.InternalServerError(g)
+
+		return
+	}
+
+	data := make([]producthttp.SearchProductResp, len(hits))
+	for i, hit := range hits {
+		data[i] = producthttp.SearchProductResp{Product: hit.Product, Highlights: hit.Highlights}
+	}
+
+	res := httpresp.Response{
+		Data:       data,
+		Pagination: &paging,
+	}
+
+	httpresp.Success(g, &res)
+}
+
+// GetMediaSignedURL 	Get signed URL of product media
+// @Summary 	Get signed URL of product media
+// @Description Get a short-lived URL to download private media of product, product must belong to organization of user
+// @Tags        product
+// @Accept      json
+// @Produce     json
+// @Param       productId  path    string true  "Product ID"
+// @Param       mediaType  path    string true  "Media type" Enums(image, video, three_dimension)
+// @Success     200  {object} httpresp.Response{data=producthttp.SignedURLResp}
+// @Failure     403  {object} httpresp.Response
+// @Failure     404  {object} httpresp.Response
+// @Failure     500  {object} httpresp.Response
+// @Security    ApiKeyAuth
+// @Router      /user/products/{productId}/media/{mediaType}/signed-url [get].
+func (c *Controller) GetMediaSignedURL(g *gin.Context) {
+	productID := g.Param("productId")
+
+	media, err := c.prodService.GetProductMedia(g, &productID, g.Param("mediaType"), authen.GetOrganizationID(g))
+	if err != nil {
+		if errors.Is(err, productdomain.ErrNoPermission) {
+			httpresp.ForbiddenError(g, err.Error())
+
+			return
+		}
+
+		httpresp.InternalServerError(g)
+
+		return
+	}
+
+	signedURL, expiresAt, err := c.mediaService.SignURL(g, media)
+	if err != nil {
+		if errors.Is(err, mediadomain.ErrMediaNotFound) {
+			httpresp.NotFound(g)
+
+			return
+		}
+
+		httpresp.InternalServerError(g)
+
+		return
+	}
+
+	res := httpresp.Response{
+		Data: producthttp.SignedURLResp{
+			URL:       signedURL,
+			ExpiresAt: cmentity.UnixTimestamp(expiresAt),
+		},
+	}
+
+	httpresp.Success(g, &res)
+}
+
+// AttachImage 	Attach image to product
+// @Summary 	Attach image to product
+// @Description Set an uploaded image as image of product with its thumbnail and variants, product must belong to
+// @Description organization of user. Variants generated after image is attached are set to product when done.
+// @Tags        product
+// @Accept      json
+// @Produce     json
+// @Param       productId  path     string                     true "Product ID"
+// @Param       body       body     producthttp.AttachImageReq true "Uploaded image"
+// @Success     200  {object} httpresp.Response{data=cmentity.Media}
+// @Failure     400  {object} httpresp.Response
+// @Failure     403  {object} httpresp.Response
+// @Failure     404  {object} httpresp.Response
+// @Failure     500  {object} httpresp.Response
+// @Security    ApiKeyAuth
+// @Router      /user/products/{productId}/media/image [put].
+func (c *Controller) AttachImage(g *gin.Context) {
+	var req producthttp.AttachImageReq
+	if !httpresp.BindStrictJSON(g, &req) {
+		return
+	}
+
+	productID := g.Param("productId")
+	orgID := authen.GetOrganizationID(g)
+
+	media, err := c.mediaService.AttachImage(
+		g, req.URL, func(ctx context.Context, media *cmentity.Media) error {
+			return c.prodService.SetMedia(ctx, &productID, cmentity.MediaTypeImage, orgID, media)
+		},
+	)
+
+	switch {
+	case err == nil:
+		httpresp.Success(g, &httpresp.Response{Data: media})
+	case errors.Is(err, productdomain.ErrNoPermission):
+		httpresp.ForbiddenError(g, err.Error())
+	case errors.Is(err, mediadomain.ErrMediaNotFound), errors.Is(err, productdomain.ErrProductNotFound):
+		httpresp.NotFound(g)
+	default:
+		httpresp.InternalServerError(g)
+	}
+}
+
+// UploadVideoPoster 	Upload poster of product video
+// @Summary 	Upload poster of product video
+// @Description Upload an image as poster of product video, it becomes thumbnail of the video
+// @Tags        product
+// @Accept      multipart/form-data
+// @Produce     json
+// @Param       productId  path     string true "Product ID"
+// @Param       poster     formData file   true "Poster image"
+// @Success     200  {object} httpresp.Response{data=cmentity.Media}
+// @Failure     403  {object} httpresp.Response
+// @Failure     404  {object} httpresp.Response
+// @Failure     413  {object} httpresp.Response
+// @Failure     415  {object} httpresp.Response
+// @Failure     500  {object} httpresp.Response
+// @Security    ApiKeyAuth
+// @Router      /user/products/{productId}/media/video/poster [put].
+func (c *Controller) UploadVideoPoster(g *gin.Context) {
+	productID := g.Param("productId")
+	orgID := authen.GetOrganizationID(g)
+
+	video, err := c.prodService.GetProductMedia(g, &productID, cmentity.MediaTypeVideo, orgID)
+	if err != nil {
+		if errors.Is(err, productdomain.ErrNoPermission) {
+			httpresp.ForbiddenError(g, err.Error())
+
+			return
+		}
+
+		httpresp.InternalServerError(g)
+
+		return
+	}
+
+	maxSize := c.mediaService.MaxSize(cmentity.MediaTypeImage)
+	middleware.LimitBody(g, maxSize+multipartOverhead)
+
+	posterHeader, err := g.FormFile("poster")
+	if err != nil {
+		httpresp.MissingRequiredFieldError(g, "poster")
+
+		return
+	}
+
+	poster, err := posterHeader.Open()
+	if err != nil {
+		httpresp.InternalServerError(g)
+
+		return
+	}
+	defer poster.Close()
+
+	posterMedia, err := c.mediaService.UploadPoster(g, video, posterHeader.Filename, poster)
+	if err == nil {
+		err = c.prodService.SetMediaThumbnail(g, &productID, cmentity.MediaTypeVideo, orgID, posterMedia.URL)
+	}
+
+	switch {
+	case err == nil:
+		httpresp.Success(g, &httpresp.Response{Data: video})
+	case errors.Is(err, mediadomain.ErrMediaNotFound), errors.Is(err, productdomain.ErrMediaNotFound):
+		httpresp.NotFound(g)
+	case errors.Is(err, mediadomain.ErrUnsupportedContentType):
+		httpresp.Error(g, http.StatusUnsupportedMediaType, httpresp.ErrKeyMediaUnsupportedContentType.Error(), nil)
+	case errors.Is(err, mediadomain.ErrFileTooLarge):
+		httpresp.Error(
+			g,
+			http.StatusRequestEntityTooLarge,
+			httpresp.ErrKeyMediaFileTooLarge.Error(),
+			map[string]any{"max_size": maxSize},
+		)
+	default:
+		httpresp.InternalServerError(g)
+	}
+}
@@ -1,17 +1,73 @@
 package authen
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"firebase.google.com/go/auth"
 	"github.com/gin-gonic/gin"
+	auditentity "github.com/golang/be/internal/core_service/entity/audit"
+	auditrepo "github.com/golang/be/internal/core_service/repo/audit"
+	"github.com/golang/be/pkg/common/httpresp"
+	"github.com/golang/be/pkg/common/logger"
+)
+
+const (
+	// ImpersonateHeader carries the UID of the user an admin wants to act as.
+	ImpersonateHeader = "X-Impersonate-User"
+	// ImpersonatedByHeader marks responses served to an impersonating admin.
+	ImpersonatedByHeader = "X-Impersonated-By"
+
+	// PermissionImpersonateUser is the permission required to impersonate users.
+	PermissionImpersonateUser = "user:impersonate"
+	// PermissionLoggingRead is the permission required to read log levels.
+	PermissionLoggingRead = "logging:read"
+	// PermissionLoggingWrite is the permission required to change log levels.
+	PermissionLoggingWrite = "logging:write"
+
+	auditTimeout = 5 * time.Second
 )
 
+// ErrUserNotFound is returned by UserLoader if user of UID doesn't exist.
+var ErrUserNotFound = errors.New("user not found")
+
+// UserLoader loads firebase users by UID.
+type UserLoader interface {
+	GetUser(ctx context.Context, uid string) (*auth.UserRecord, error)
+}
+
+// firebaseUsers loads users from firebase auth.
+type firebaseUsers struct {
+	client *auth.Client
+}
+
+func (u firebaseUsers) GetUser(ctx context.Context, uid string) (*auth.UserRecord, error) {
+	user, err := u.client.GetUser(ctx, uid)
+	if auth.IsUserNotFound(err) {
+		return nil, ErrUserNotFound
+	}
+
+	return user, err
+}
+
 type AdminAuthenticator struct {
-	decoder *AuthenticatorDecoder
+	decoder   *AuthenticatorDecoder
+	users     UserLoader
+	auditRepo auditrepo.RepoInterface
 }
 
 func NewAdminAuthenticator(
 	decoder *AuthenticatorDecoder,
-) AuthenticatorInterface {
-	return &AdminAuthenticator{decoder: decoder}
+	fbAuth *auth.Client,
+	auditRepo auditrepo.RepoInterface,
+) *AdminAuthenticator {
+	return &AdminAuthenticator{
+		decoder:   decoder,
+		users:     firebaseUsers{client: fbAuth},
+		auditRepo: auditRepo,
+	}
 }
 
 func (a *AdminAuthenticator) Authenticate(c *gin.Context) {
@@ -20,8 +76,105 @@ func (a *AdminAuthenticator) Authenticate(c *gin.Context) {
 		return
 	}
 
-	// TODO: verify admin
+	a.authenticate(c, tokenData)
+}
 
-	c.Set(userKey, tokenData.UID)
+// authenticate sets user of decoded token to request.
+//
+// Any valid token passes, each admin route must check permission of the token by RequirePermission.
+func (a *AdminAuthenticator) authenticate(c *gin.Context, tokenData *auth.Token) {
+	setUserID(c, tokenData.UID)
+	c.Set(tokenKey, tokenData)
 	c.Next()
 }
+
+// RequirePermission returns middleware aborting with 403 unless token of request grants permission.
+//
+// It must follow AdminAuthenticator.Authenticate, requests without token are rejected.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := c.Value(tokenKey).(*auth.Token)
+		if !ok || !HasPermission(token, permission) {
+			httpresp.ForbiddenError(c, "missing permission "+permission)
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Impersonate validates that the caller of token is allowed to act as targetUID.
+//
+// On success the request identity and organization are switched to those of targetUID and the response is marked
+// with ImpersonatedByHeader. On failure, including unknown targetUID, the request is aborted and false is returned.
+func (a *AdminAuthenticator) Impersonate(c *gin.Context, token *auth.Token, targetUID string) bool {
+	if !HasPermission(token, PermissionImpersonateUser) {
+		httpresp.Error(
+			c,
+			http.StatusForbidden,
+			httpresp.ErrKeyAuthenticationImpersonationNotAllowed.Error(),
+			nil,
+		)
+
+		return false
+	}
+
+	target, err := a.users.GetUser(c, targetUID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			httpresp.Error(
+				c,
+				http.StatusNotFound,
+				httpresp.ErrKeyAuthenticationImpersonatedNotFound.Error(),
+				nil,
+			)
+
+			return false
+		}
+
+		logger.WithContext(c).Errorw(
+			"get impersonated user fail",
+			"uid", targetUID,
+			"err", err,
+		)
+
+		httpresp.InternalServerError(c)
+
+		return false
+	}
+
+	c.Set(impersonatorKey, token.UID)
+	c.Request = c.Request.WithContext(logger.ContextWithFields(c.Request.Context(), "impersonator_id", token.UID))
+	setUserID(c, targetUID)
+	c.Set(organizationKey, target.CustomClaims[organizationClaim])
+	c.Header(ImpersonatedByHeader, token.UID)
+
+	return true
+}
+
+// AuditImpersonation writes the handled impersonated request to the audit collection,
+// request which didn't complete, e.g. it panicked, is recorded with status 500.
+func (a *AdminAuthenticator) AuditImpersonation(c *gin.Context, completed bool) {
+	statusCode := c.Writer.Status()
+	if !completed {
+		statusCode = http.StatusInternalServerError
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), auditTimeout)
+	defer cancel()
+
+	// failure is already logged by repo, the response has been written at this point
+	_ = a.auditRepo.CreateImpersonationLog(
+		ctx,
+		&auditentity.ImpersonationLog{
+			AdminID:    GetImpersonatorID(c),
+			UserID:     GetUserID(c),
+			Method:     c.Request.Method,
+			Path:       c.Request.URL.Path,
+			StatusCode: statusCode,
+			ClientIP:   c.ClientIP(),
+			UserAgent:  c.Request.UserAgent(),
+		},
+	)
+}
@@ -0,0 +1,52 @@
+package authen
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"firebase.google.com/go/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	authenticator := &AdminAuthenticator{}
+	tokens := map[string]*auth.Token{
+		"admin":  {UID: "admin", Claims: map[string]any{permissionsClaim: []any{PermissionLoggingWrite}}},
+		"member": {UID: "member", Claims: map[string]any{}},
+	}
+
+	engine := gin.New()
+	engine.PUT(
+		"/log-level", func(c *gin.Context) {
+			authenticator.authenticate(c, tokens[c.GetHeader("X-Test-User")])
+		},
+		RequirePermission(PermissionLoggingWrite),
+		func(c *gin.Context) { c.Status(http.StatusNoContent) },
+	)
+
+	t.Run(
+		"token with permission", func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPut, "/log-level", nil)
+			request.Header.Set("X-Test-User", "admin")
+			recorder := httptest.NewRecorder()
+			engine.ServeHTTP(recorder, request)
+
+			assert.Equal(t, http.StatusNoContent, recorder.Code)
+		},
+	)
+
+	t.Run(
+		"token without permission", func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPut, "/log-level", nil)
+			request.Header.Set("X-Test-User", "member")
+			recorder := httptest.NewRecorder()
+			engine.ServeHTTP(recorder, request)
+
+			assert.Equal(t, http.StatusForbidden, recorder.Code)
+		},
+	)
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/golang/be/pkg/common/httpresp"
+	"github.com/golang/be/pkg/common/logger"
 
 	"firebase.google.com/go/auth"
 	"github.com/gin-gonic/gin"
@@ -15,7 +16,15 @@ type AuthenticatorInterface interface {
 }
 
 const (
-	userKey = "user"
+	userKey         = "user"
+	organizationKey = "organization"
+	impersonatorKey = "impersonator"
+	tokenKey        = "token"
+
+	// permissionsClaim is the firebase custom claim listing permissions granted to the user.
+	permissionsClaim = "permissions"
+	// organizationClaim is the firebase custom claim holding organization the user belongs to.
+	organizationClaim = "org_id"
 )
 
 type AuthenticatorDecoder struct {
@@ -57,6 +66,12 @@ func (d *AuthenticatorDecoder) Decode(c *gin.Context) *auth.Token {
 	return decoded
 }
 
+// setUserID sets user of request, logs of request carry it.
+func setUserID(c *gin.Context, userID string) {
+	c.Set(userKey, userID)
+	c.Request = c.Request.WithContext(logger.ContextWithFields(c.Request.Context(), "user_id", userID))
+}
+
 func GetUserID(ctx *gin.Context) string {
 	userID, ok := ctx.Value(userKey).(string)
 	if !ok {
@@ -65,3 +80,39 @@ func GetUserID(ctx *gin.Context) string {
 
 	return userID
 }
+
+// GetOrganizationID returns organization of the current user, empty if user doesn't belong to any.
+func GetOrganizationID(ctx *gin.Context) string {
+	orgID, ok := ctx.Value(organizationKey).(string)
+	if !ok {
+		return ""
+	}
+
+	return orgID
+}
+
+// GetImpersonatorID returns UID of the admin impersonating the current user, empty if not impersonated.
+func GetImpersonatorID(ctx *gin.Context) string {
+	adminID, ok := ctx.Value(impersonatorKey).(string)
+	if !ok {
+		return ""
+	}
+
+	return adminID
+}
+
+// HasPermission checks whether permission is granted in custom claims of token.
+func HasPermission(token *auth.Token, permission string) bool {
+	permissions, ok := token.Claims[permissionsClaim].([]any)
+	if !ok {
+		return false
+	}
+
+	for _, item := range permissions {
+		if item == permission {
+			return true
+		}
+	}
+
+	return false
+}
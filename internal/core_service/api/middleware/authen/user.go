@@ -1,17 +1,23 @@
 package authen
 
 import (
+	"firebase.google.com/go/auth"
 	"github.com/gin-gonic/gin"
 )
 
 type UserAuthenticator struct {
 	decoder *AuthenticatorDecoder
+	admin   *AdminAuthenticator
 }
 
 func NewUserAuthenticator(
 	decoder *AuthenticatorDecoder,
+	admin *AdminAuthenticator,
 ) AuthenticatorInterface {
-	return &UserAuthenticator{decoder: decoder}
+	return &UserAuthenticator{
+		decoder: decoder,
+		admin:   admin,
+	}
 }
 
 func (a *UserAuthenticator) Authenticate(c *gin.Context) {
@@ -20,6 +26,28 @@ func (a *UserAuthenticator) Authenticate(c *gin.Context) {
 		return
 	}
 
-	c.Set(userKey, tokenData.UID)
+	a.authenticate(c, tokenData)
+}
+
+// authenticate sets user of decoded token, or user impersonated by it, to request.
+func (a *UserAuthenticator) authenticate(c *gin.Context, tokenData *auth.Token) {
+	targetUID := c.GetHeader(ImpersonateHeader)
+	if targetUID == "" {
+		setUserID(c, tokenData.UID)
+		c.Set(organizationKey, tokenData.Claims[organizationClaim])
+		c.Next()
+
+		return
+	}
+
+	if !a.admin.Impersonate(c, tokenData, targetUID) {
+		return
+	}
+
+	// deferred, so requests that panic are audited too
+	completed := false
+	defer func() { a.admin.AuditImpersonation(c, completed) }()
+
 	c.Next()
+	completed = true
 }
@@ -0,0 +1,127 @@
+package authen
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"firebase.google.com/go/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/be/internal/core_service/entity/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeUsers map[string]*auth.UserRecord
+
+func (u fakeUsers) GetUser(_ context.Context, uid string) (*auth.UserRecord, error) {
+	if user, ok := u[uid]; ok {
+		return user, nil
+	}
+
+	return nil, ErrUserNotFound
+}
+
+type memoryAuditRepo struct {
+	mu   sync.Mutex
+	logs []*audit.ImpersonationLog
+}
+
+func (r *memoryAuditRepo) CreateImpersonationLog(_ context.Context, log *audit.ImpersonationLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.logs = append(r.logs, log)
+
+	return nil
+}
+
+func TestUserAuthenticatorImpersonation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	auditRepo := &memoryAuditRepo{}
+	authenticator := &UserAuthenticator{
+		admin: &AdminAuthenticator{
+			users: fakeUsers{
+				"user": {
+					UserInfo:     &auth.UserInfo{UID: "user"},
+					CustomClaims: map[string]any{organizationClaim: "org"},
+				},
+			},
+			auditRepo: auditRepo,
+		},
+	}
+
+	admin := &auth.Token{UID: "admin", Claims: map[string]any{permissionsClaim: []any{PermissionImpersonateUser}}}
+	member := &auth.Token{UID: "member", Claims: map[string]any{}}
+
+	engine := gin.New()
+	engine.Use(gin.RecoveryWithWriter(io.Discard))
+	route := func(path string, handler gin.HandlerFunc) {
+		engine.GET(
+			path, func(c *gin.Context) {
+				token := admin
+				if c.GetHeader("X-Test-Member") != "" {
+					token = member
+				}
+
+				authenticator.authenticate(c, token)
+			}, handler,
+		)
+	}
+	route(
+		"/me", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"user": GetUserID(c), "org": GetOrganizationID(c)})
+		},
+	)
+	route("/panic", func(*gin.Context) { panic("handler fail") })
+
+	get := func(path, targetUID string, asMember bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(ImpersonateHeader, targetUID)
+		if asMember {
+			req.Header.Set("X-Test-Member", "true")
+		}
+
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	t.Run("acts as target user in its organization", func(t *testing.T) {
+		recorder := get("/me", "user", false)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"user":"user","org":"org"}`, recorder.Body.String())
+		assert.Equal(t, "admin", recorder.Header().Get(ImpersonatedByHeader))
+
+		assert.Len(t, auditRepo.logs, 1)
+		assert.Equal(t, "admin", auditRepo.logs[0].AdminID)
+		assert.Equal(t, "user", auditRepo.logs[0].UserID)
+		assert.Equal(t, http.StatusOK, auditRepo.logs[0].StatusCode)
+	})
+
+	t.Run("rejects user without permission", func(t *testing.T) {
+		recorder := get("/me", "user", true)
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		assert.Empty(t, recorder.Header().Get(ImpersonatedByHeader))
+		assert.Len(t, auditRepo.logs, 1)
+	})
+
+	t.Run("rejects unknown user", func(t *testing.T) {
+		recorder := get("/me", "unknown", false)
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		assert.Len(t, auditRepo.logs, 1)
+	})
+
+	t.Run("audits request that panics", func(t *testing.T) {
+		recorder := get("/panic", "user", false)
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+		assert.Len(t, auditRepo.logs, 2)
+		assert.Equal(t, "/panic", auditRepo.logs[1].Path)
+		assert.Equal(t, http.StatusInternalServerError, auditRepo.logs[1].StatusCode)
+	})
+}
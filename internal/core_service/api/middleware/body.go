@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	config "github.com/golang/be/config/core_service"
+	"github.com/golang/be/pkg/common/httpresp"
+	"github.com/golang/be/pkg/common/jsonlimit"
+)
+
+// unlimitedBodyKey keeps body of request before it is limited, so routes can replace limit of their group.
+const unlimitedBodyKey = "unlimited_body"
+
+// BodyLimiter limits size of request bodies and structure of JSON bound from them.
+type BodyLimiter struct {
+	cfg config.Request
+}
+
+func NewBodyLimiter(cfg *config.Config) *BodyLimiter {
+	return &BodyLimiter{cfg: cfg.Request}
+}
+
+// Limit returns middleware limiting bodies of route group, reading past limit fails with *http.MaxBytesError.
+//
+// It must run after middlewares reading body, so routes replacing limit keep what they have read.
+func (l *BodyLimiter) Limit(group string) gin.HandlerFunc {
+	maxSize := l.cfg.MaxBodySize[group]
+	limits := jsonlimit.Limits{MaxDepth: l.cfg.JSONMaxDepth, MaxArrayLength: l.cfg.JSONMaxArrayLength}
+
+	return func(c *gin.Context) {
+		httpresp.SetJSONLimits(c, limits)
+
+		if maxSize <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			return
+		}
+
+		c.Set(unlimitedBodyKey, c.Request.Body)
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize)
+	}
+}
+
+// LimitBody replaces limit of group by maxSize for route accepting larger bodies, e.g. uploads,
+// zero means body is limited by handler itself. It must be called before body is read.
+func LimitBody(c *gin.Context, maxSize int64) {
+	if body, ok := c.Get(unlimitedBodyKey); ok {
+		c.Request.Body = body.(io.ReadCloser)
+	}
+
+	if maxSize > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize)
+	}
+}
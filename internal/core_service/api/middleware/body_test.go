@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	config "github.com/golang/be/config/core_service"
+	"github.com/golang/be/pkg/common/httpresp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyLimiter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := NewBodyLimiter(&config.Config{Request: config.Request{
+		MaxBodySize:        map[string]int64{RouteGroupUser: 64},
+		JSONMaxDepth:       2,
+		JSONMaxArrayLength: 2,
+	}})
+
+	type request struct {
+		Name string   `json:"name" binding:"required"`
+		Tags []string `json:"tags"`
+	}
+
+	engine := gin.New()
+	engine.Use(limiter.Limit(RouteGroupUser))
+	engine.POST(
+		"/strict", func(c *gin.Context) {
+			var req request
+			if !httpresp.BindStrictJSON(c, &req) {
+				return
+			}
+
+			c.JSON(http.StatusOK, req)
+		},
+	)
+	engine.POST(
+		"/upload", func(c *gin.Context) {
+			LimitBody(c, 1024)
+
+			data, err := io.ReadAll(c.Request.Body)
+			assert.NoError(t, err)
+
+			c.String(http.StatusOK, "%d", len(data))
+		},
+	)
+
+	post := func(path, body string) (int, string) {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+
+		var res httpresp.Response
+		_ = json.Unmarshal(recorder.Body.Bytes(), &res)
+		if res.ErrorKey == nil {
+			return recorder.Code, ""
+		}
+
+		return recorder.Code, *res.ErrorKey
+	}
+
+	t.Run(
+		"bind valid body", func(t *testing.T) {
+			status, errorKey := post("/strict", `{"name":"chair","tags":["a","b"]}`)
+			assert.Equal(t, http.StatusOK, status)
+			assert.Empty(t, errorKey)
+		},
+	)
+
+	t.Run(
+		"reject body too large", func(t *testing.T) {
+			status, errorKey := post("/strict", `{"name":"`+strings.Repeat("a", 100)+`"}`)
+			assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+			assert.Equal(t, httpresp.ErrKeyHTTPValidatorsBodyTooLarge.Error(), errorKey)
+		},
+	)
+
+	t.Run(
+		"replace limit of route", func(t *testing.T) {
+			status, _ := post("/upload", strings.Repeat("a", 100))
+			assert.Equal(t, http.StatusOK, status)
+		},
+	)
+
+	t.Run(
+		"reject unknown field", func(t *testing.T) {
+			status, errorKey := post("/strict", `{"name":"chair","color":"red"}`)
+			assert.Equal(t, http.StatusBadRequest, status)
+			assert.Equal(t, httpresp.ErrKeyHTTPValidatorsUnknownField.Error(), errorKey)
+		},
+	)
+
+	t.Run(
+		"reject deep and long JSON", func(t *testing.T) {
+			status, errorKey := post("/strict", `{"name":"chair","tags":[["a"]]}`)
+			assert.Equal(t, http.StatusBadRequest, status)
+			assert.Equal(t, httpresp.ErrKeyHTTPValidatorsJSONTooDeep.Error(), errorKey)
+
+			status, errorKey = post("/strict", `{"name":"chair","tags":["a","b","c"]}`)
+			assert.Equal(t, http.StatusBadRequest, status)
+			assert.Equal(t, httpresp.ErrKeyHTTPValidatorsJSONArrayTooLong.Error(), errorKey)
+		},
+	)
+
+	t.Run(
+		"reject invalid body", func(t *testing.T) {
+			status, errorKey := post("/strict", `{"tags":[]}`)
+			assert.Equal(t, http.StatusBadRequest, status)
+			assert.Equal(t, httpresp.ErrKeyHTTPValidatorsDecodeFail.Error(), errorKey)
+		},
+	)
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CachePolicy specific Cache-Control of successful responses of route.
+//
+// Public specific whether shared caches, e.g. CDN, can keep response, responses of users must be private.
+// MaxAge specific how long response is fresh, zero means it must be revalidated by ETag on each use.
+// StaleWhileRevalidate specific how long a stale response can be used while it is revalidated in background.
+type CachePolicy struct {
+	Public               bool
+	MaxAge               time.Duration
+	StaleWhileRevalidate time.Duration
+}
+
+// Cache policies declared by routes.
+//
+// There is no public policy, product routes only serve products of organization of user, so responses are private.
+var (
+	// CachePrivateRevalidate is policy of data of user, browsers keep it but revalidate it before use.
+	CachePrivateRevalidate = CachePolicy{}
+)
+
+// header returns Cache-Control of policy.
+func (p CachePolicy) header() string {
+	directives := []string{"private"}
+	if p.Public {
+		directives[0] = "public"
+	}
+
+	if p.MaxAge > 0 {
+		directives = append(directives, "max-age="+strconv.Itoa(int(p.MaxAge.Seconds())))
+	} else {
+		directives = append(directives, "no-cache")
+	}
+
+	if p.StaleWhileRevalidate > 0 {
+		directives = append(directives, "stale-while-revalidate="+strconv.Itoa(int(p.StaleWhileRevalidate.Seconds())))
+	}
+
+	return strings.Join(directives, ", ")
+}
+
+// Cache returns middleware applying policy to successful GET responses of route.
+//
+// Response gets strong ETag hashed from its body and If-None-Match matching it is answered by 304 without body.
+// Responses vary by language, since messages are translated by Accept-Language and language_code headers.
+func Cache(policy CachePolicy) gin.HandlerFunc {
+	cacheControl := policy.header()
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			return
+		}
+
+		writer := &bufferWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		c.Writer = writer.ResponseWriter
+
+		if writer.Status() != http.StatusOK {
+			_, _ = c.Writer.Write(writer.body.Bytes())
+
+			return
+		}
+
+		sum := sha256.Sum256(writer.body.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+		header := c.Writer.Header()
+		header.Set("Cache-Control", cacheControl)
+		header.Add("Vary", "Accept-Language")
+		header.Add("Vary", "language_code")
+
+		if matched, ok := matchETag(c.GetHeader("If-None-Match"), etag); ok {
+			// client keeps representation it sent, e.g. compressed one
+			header.Set("ETag", matched)
+			header.Del("Content-Length")
+			c.Writer.WriteHeader(http.StatusNotModified)
+			c.Writer.WriteHeaderNow()
+
+			return
+		}
+
+		header.Set("ETag", etag)
+		_, _ = c.Writer.Write(writer.body.Bytes())
+	}
+}
+
+// matchETag returns tag of If-None-Match matching etag, tags of compressed representations match too.
+func matchETag(ifNoneMatch, etag string) (string, bool) {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return etag, true
+		}
+
+		// If-None-Match uses weak comparison
+		candidate := strings.TrimPrefix(tag, "W/")
+		for _, encoding := range []string{EncodingBrotli, EncodingGzip} {
+			candidate = strings.Replace(candidate, "-"+encoding+`"`, `"`, 1)
+		}
+
+		if candidate == etag {
+			return tag, true
+		}
+	}
+
+	return "", false
+}
+
+// bufferWriter keeps response body, so it can be sent once handler is done.
+type bufferWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bufferWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// Flush is ignored, body is sent once handler is done.
+func (w *bufferWriter) Flush() {}
@@ -0,0 +1,231 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	config "github.com/golang/be/config/core_service"
+)
+
+// Content codings of compressed responses.
+const (
+	EncodingBrotli = "br"
+	EncodingGzip   = "gzip"
+)
+
+// compressor compresses responses whose content type and size are worth it.
+type compressor struct {
+	cfg    config.Compression
+	gzips  sync.Pool
+	brotli sync.Pool
+}
+
+// Compress returns middleware compressing responses by gzip or brotli as client accepts, brotli is preferred.
+//
+// Responses smaller than MinSize, of other content types or already encoded are sent as is.
+// Strong ETag of compressed response gets encoding as suffix, so each representation has own ETag.
+func Compress(cfg *config.Config) gin.HandlerFunc {
+	comp := &compressor{cfg: cfg.Compression}
+	comp.gzips.New = func() any {
+		w, _ := gzip.NewWriterLevel(io.Discard, comp.cfg.GzipLevel)
+
+		return w
+	}
+	comp.brotli.New = func() any {
+		return brotli.NewWriterLevel(io.Discard, comp.cfg.BrotliLevel)
+	}
+
+	return comp.handle
+}
+
+func (comp *compressor) handle(c *gin.Context) {
+	if !comp.cfg.Enabled || c.Request.Method == http.MethodHead {
+		return
+	}
+
+	encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+	if encoding == "" {
+		return
+	}
+
+	writer := &compressWriter{ResponseWriter: c.Writer, comp: comp, encoding: encoding}
+	c.Writer = writer
+
+	defer func() {
+		writer.Close()
+		c.Writer = writer.ResponseWriter
+	}()
+
+	c.Next()
+}
+
+// negotiateEncoding returns encoding of highest quality in Accept-Encoding, it's empty if none is accepted.
+func negotiateEncoding(acceptEncoding string) string {
+	var (
+		best    string
+		bestQ   float64
+		qualify = map[string]float64{}
+	)
+
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+
+			q = parsed
+		}
+
+		qualify[strings.ToLower(strings.TrimSpace(name))] = q
+	}
+
+	for _, encoding := range []string{EncodingBrotli, EncodingGzip} {
+		q, ok := qualify[encoding]
+		if !ok {
+			q, ok = qualify["*"]
+		}
+
+		if ok && q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+
+	return best
+}
+
+// compressWriter buffers response until MinSize is reached, then it decides whether response is compressed.
+type compressWriter struct {
+	gin.ResponseWriter
+	comp     *compressor
+	encoding string
+	buf      bytes.Buffer
+	decided  bool
+	encoder  io.WriteCloser
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		return w.write(data)
+	}
+
+	w.buf.Write(data)
+	if w.buf.Len() < w.comp.cfg.MinSize {
+		return len(data), nil
+	}
+
+	w.decide(true)
+
+	if _, err := w.write(w.buf.Bytes()); err != nil {
+		return 0, err
+	}
+
+	w.buf.Reset()
+
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush sends buffered response, streamed responses are compressed regardless of size.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(w.buf.Len() > 0)
+		_, _ = w.write(w.buf.Bytes())
+		w.buf.Reset()
+	}
+
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+
+	w.ResponseWriter.Flush()
+}
+
+// Close writes rest of response and releases encoder.
+func (w *compressWriter) Close() {
+	if !w.decided {
+		w.decide(w.buf.Len() >= w.comp.cfg.MinSize)
+		_, _ = w.write(w.buf.Bytes())
+	}
+
+	if w.encoder == nil {
+		return
+	}
+
+	_ = w.encoder.Close()
+
+	switch encoder := w.encoder.(type) {
+	case *gzip.Writer:
+		w.comp.gzips.Put(encoder)
+	case *brotli.Writer:
+		w.comp.brotli.Put(encoder)
+	}
+}
+
+// decide starts compression if response is large enough and its content is compressible.
+func (w *compressWriter) decide(largeEnough bool) {
+	w.decided = true
+
+	header := w.Header()
+	if !largeEnough || !bodyAllowed(w.Status()) || header.Get("Content-Encoding") != "" ||
+		!w.compressible(header.Get("Content-Type")) {
+		return
+	}
+
+	header.Set("Content-Encoding", w.encoding)
+	header.Add("Vary", "Accept-Encoding")
+	header.Del("Content-Length")
+
+	if etag := header.Get("ETag"); strings.HasPrefix(etag, `"`) {
+		header.Set("ETag", strings.TrimSuffix(etag, `"`)+"-"+w.encoding+`"`)
+	}
+
+	switch w.encoding {
+	case EncodingGzip:
+		encoder := w.comp.gzips.Get().(*gzip.Writer)
+		encoder.Reset(w.ResponseWriter)
+		w.encoder = encoder
+	case EncodingBrotli:
+		encoder := w.comp.brotli.Get().(*brotli.Writer)
+		encoder.Reset(w.ResponseWriter)
+		w.encoder = encoder
+	}
+}
+
+func (w *compressWriter) write(data []byte) (int, error) {
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compressWriter) compressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(strings.ToLower(mediaType))
+
+	for _, prefix := range w.comp.cfg.ContentTypes {
+		if strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func bodyAllowed(status int) bool {
+	return status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	config "github.com/golang/be/config/core_service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressAndCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{Compression: config.Compression{
+		Enabled:      true,
+		MinSize:      64,
+		ContentTypes: []string{"application/json"},
+		GzipLevel:    gzip.DefaultCompression,
+		BrotliLevel:  brotli.DefaultCompression,
+	}}
+	largeBody := strings.Repeat("product ", 100)
+	publicPolicy := CachePolicy{Public: true, MaxAge: time.Minute, StaleWhileRevalidate: 5 * time.Minute}
+
+	engine := gin.New()
+	engine.Use(Compress(cfg))
+	engine.GET(
+		"/products", Cache(publicPolicy), func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"data": largeBody})
+		},
+	)
+	engine.GET(
+		"/small", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"data": "small"})
+		},
+	)
+	engine.GET(
+		"/image", func(c *gin.Context) {
+			c.Data(http.StatusOK, "image/png", []byte(largeBody))
+		},
+	)
+
+	get := func(path, acceptEncoding, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	t.Run(
+		"prefer brotli", func(t *testing.T) {
+			recorder := get("/products", "gzip, br", "")
+			assert.Equal(t, EncodingBrotli, recorder.Header().Get("Content-Encoding"))
+
+			body, err := io.ReadAll(brotli.NewReader(recorder.Body))
+			assert.NoError(t, err)
+			assert.Contains(t, string(body), largeBody)
+		},
+	)
+
+	t.Run(
+		"compress by gzip", func(t *testing.T) {
+			recorder := get("/products", "gzip, br;q=0", "")
+			assert.Equal(t, EncodingGzip, recorder.Header().Get("Content-Encoding"))
+
+			reader, err := gzip.NewReader(recorder.Body)
+			assert.NoError(t, err)
+			body, err := io.ReadAll(reader)
+			assert.NoError(t, err)
+			assert.Contains(t, string(body), largeBody)
+		},
+	)
+
+	t.Run(
+		"skip small and incompressible responses", func(t *testing.T) {
+			recorder := get("/small", "gzip", "")
+			assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+			assert.Equal(t, `{"data":"small"}`, recorder.Body.String())
+
+			recorder = get("/image", "gzip", "")
+			assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+			assert.Equal(t, largeBody, recorder.Body.String())
+		},
+	)
+
+	t.Run(
+		"answer matching ETag with not modified", func(t *testing.T) {
+			recorder := get("/products", "", "")
+			etag := recorder.Header().Get("ETag")
+			assert.Equal(t, "public, max-age=60, stale-while-revalidate=300", recorder.Header().Get("Cache-Control"))
+			assert.Contains(t, recorder.Header().Values("Vary"), "Accept-Language")
+
+			recorder = get("/products", "", etag)
+			assert.Equal(t, http.StatusNotModified, recorder.Code)
+			assert.Empty(t, recorder.Body.String())
+
+			compressed := get("/products", "gzip", "")
+			compressedETag := compressed.Header().Get("ETag")
+			assert.Equal(t, strings.TrimSuffix(etag, `"`)+`-gzip"`, compressedETag)
+
+			recorder = get("/products", "gzip", compressedETag)
+			assert.Equal(t, http.StatusNotModified, recorder.Code)
+			assert.Equal(t, compressedETag, recorder.Header().Get("ETag"))
+
+			recorder = get("/products", "", `"other"`)
+			assert.Equal(t, http.StatusOK, recorder.Code)
+		},
+	)
+}
@@ -1,23 +1,129 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/gin-gonic/gin"
+	config "github.com/golang/be/config/core_service"
 	cors "github.com/rs/cors/wrapper/gin"
 )
 
-// CorsConfigs sets up cors middleware.
-func CorsConfigs() gin.HandlerFunc {
+// ErrCORSWildcardCredentials is returned if a policy allows credentials from any origin.
+var ErrCORSWildcardCredentials = errors.New("cors policy can't allow credentials from any origin")
+
+// exposedHeaders specific headers set by middlewares, they are always exposed to browsers.
+var exposedHeaders = []string{
+	"Content-Length", HeaderRequestID,
+	HeaderRateLimitLimit, HeaderRateLimitRemaining, HeaderRateLimitReset, HeaderRateLimitPolicy, HeaderRetryAfter,
+	HeaderIdempotentReplayed,
+}
+
+// CORS applies CORS policies of route groups, groups without own policy use default policy.
+type CORS struct {
+	defaultPolicy gin.HandlerFunc
+	groupPolicies map[string]gin.HandlerFunc
+}
+
+func NewCORS(cfg *config.Config) (*CORS, error) {
+	defaultPolicy, err := newCORSPolicy(cfg.CORS.Default)
+	if err != nil {
+		return nil, fmt.Errorf("default: %w", err)
+	}
+
+	groupPolicies := make(map[string]gin.HandlerFunc, len(cfg.CORS.Groups))
+
+	for group, policy := range cfg.CORS.Groups {
+		groupPolicies[group], err = newCORSPolicy(mergeCORSPolicy(cfg.CORS.Default, policy))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", group, err)
+		}
+	}
+
+	return &CORS{defaultPolicy: defaultPolicy, groupPolicies: groupPolicies}, nil
+}
+
+// Handle returns middleware applying policy of group whose path prefix is the longest match of request path.
+//
+// It must be used by engine rather than groups, since preflight requests match no route.
+func (c *CORS) Handle(groupPrefixes map[string]string) gin.HandlerFunc {
+	prefixes := make([]string, 0, len(groupPrefixes))
+	for prefix := range groupPrefixes {
+		prefixes = append(prefixes, prefix)
+	}
+
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+
+	return func(ctx *gin.Context) {
+		path := ctx.Request.URL.Path
+
+		for _, prefix := range prefixes {
+			if path != prefix && !strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+				continue
+			}
+
+			if policy, ok := c.groupPolicies[groupPrefixes[prefix]]; ok {
+				policy(ctx)
+
+				return
+			}
+
+			break
+		}
+
+		c.defaultPolicy(ctx)
+	}
+}
+
+// mergeCORSPolicy fills unset fields of group policy by default policy.
+func mergeCORSPolicy(defaultPolicy, policy config.CORSPolicy) config.CORSPolicy {
+	if len(policy.AllowedOrigins) == 0 {
+		policy.AllowedOrigins = defaultPolicy.AllowedOrigins
+	}
+
+	if len(policy.AllowedMethods) == 0 {
+		policy.AllowedMethods = defaultPolicy.AllowedMethods
+	}
+
+	if len(policy.AllowedHeaders) == 0 {
+		policy.AllowedHeaders = defaultPolicy.AllowedHeaders
+	}
+
+	if len(policy.ExposedHeaders) == 0 {
+		policy.ExposedHeaders = defaultPolicy.ExposedHeaders
+	}
+
+	if policy.AllowCredentials == nil {
+		policy.AllowCredentials = defaultPolicy.AllowCredentials
+	}
+
+	if policy.MaxAge == 0 {
+		policy.MaxAge = defaultPolicy.MaxAge
+	}
+
+	return policy
+}
+
+func newCORSPolicy(policy config.CORSPolicy) (gin.HandlerFunc, error) {
+	allowCredentials := policy.AllowCredentials != nil && *policy.AllowCredentials
+
+	for _, origin := range policy.AllowedOrigins {
+		// browsers would send credentials to any site reflected as allowed origin
+		if origin == "*" && allowCredentials {
+			return nil, ErrCORSWildcardCredentials
+		}
+	}
+
 	return cors.New(
 		cors.Options{
-			AllowOriginFunc: func(origin string) bool {
-				return true
-			},
-			AllowedOrigins:   []string{"*"},
-			AllowedHeaders:   []string{"*"},
-			AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "HEAD", "OPTIONS", "DELETE"},
-			ExposedHeaders:   []string{"Content-Length"},
-			MaxAge:           86400,
-			AllowCredentials: true,
+			AllowedOrigins:   policy.AllowedOrigins,
+			AllowedMethods:   policy.AllowedMethods,
+			AllowedHeaders:   policy.AllowedHeaders,
+			ExposedHeaders:   append(append([]string{}, exposedHeaders...), policy.ExposedHeaders...),
+			MaxAge:           int(policy.MaxAge.Seconds()),
+			AllowCredentials: allowCredentials,
 		},
-	)
+	), nil
 }
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	config "github.com/golang/be/config/core_service"
+	"github.com/golang/be/pkg/common/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{CORS: config.CORS{
+		Default: config.CORSPolicy{
+			AllowedOrigins: []string{"https://*.example.com"},
+			AllowedMethods: []string{http.MethodGet, http.MethodPost},
+			AllowedHeaders: []string{"Content-Type"},
+		},
+		Groups: map[string]config.CORSPolicy{
+			RouteGroupAdmin: {
+				AllowedOrigins:   []string{"https://admin.example.com"},
+				AllowCredentials: utils.ToPtr(true),
+			},
+		},
+	}}
+
+	corsMiddleware, err := NewCORS(cfg)
+	assert.NoError(t, err)
+
+	engine := gin.New()
+	engine.Use(corsMiddleware.Handle(map[string]string{
+		"/api/v1":       RouteGroupPublic,
+		"/api/v1/admin": RouteGroupAdmin,
+	}))
+	engine.GET("/api/v1/products", func(c *gin.Context) {})
+	engine.GET("/api/v1/admin/products", func(c *gin.Context) {})
+
+	request := func(method, path, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Origin", origin)
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	t.Run(
+		"allow wildcard subdomain", func(t *testing.T) {
+			recorder := request(http.MethodGet, "/api/v1/products", "https://shop.example.com")
+			assert.Equal(t, "https://shop.example.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+			assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Credentials"))
+			assert.Contains(t, recorder.Header().Get("Access-Control-Expose-Headers"), "X-Request-Id")
+		},
+	)
+
+	t.Run(
+		"reject unknown origin", func(t *testing.T) {
+			recorder := request(http.MethodGet, "/api/v1/products", "https://example.org")
+			assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+		},
+	)
+
+	t.Run(
+		"apply group policy to preflight", func(t *testing.T) {
+			recorder := request(http.MethodOptions, "/api/v1/admin/products", "https://admin.example.com")
+			assert.Equal(t, "https://admin.example.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, "true", recorder.Header().Get("Access-Control-Allow-Credentials"))
+
+			recorder = request(http.MethodOptions, "/api/v1/admin/products", "https://shop.example.com")
+			assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+		},
+	)
+
+	t.Run(
+		"reject credentials from any origin", func(t *testing.T) {
+			cfg.CORS.Default.AllowedOrigins = []string{"*"}
+			cfg.CORS.Default.AllowCredentials = utils.ToPtr(true)
+
+			_, err := NewCORS(cfg)
+			assert.ErrorIs(t, err, ErrCORSWildcardCredentials)
+		},
+	)
+}
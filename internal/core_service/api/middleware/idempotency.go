@@ -0,0 +1,292 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	config "github.com/golang/be/config/core_service"
+	"github.com/golang/be/internal/core_service/api/middleware/authen"
+	"github.com/golang/be/internal/core_service/entity/idempotency"
+	idempotencyrepo "github.com/golang/be/internal/core_service/repo/idempotency"
+	"github.com/golang/be/pkg/common/httpresp"
+	"github.com/golang/be/pkg/common/logger"
+)
+
+// Idempotency headers, a replayed response has HeaderIdempotentReplayed set to true.
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// maxIdempotencyKeyLength specific max length of Idempotency-Key, UUIDs are recommended to clients.
+const maxIdempotencyKeyLength = 255
+
+var errIdempotencyBodyTooLarge = errors.New("request body too large to be fingerprinted")
+
+// Idempotency makes mutating requests carrying Idempotency-Key safe to retry.
+//
+// The first request of a key is processed and its response is stored, retries of same request get stored response.
+// A key reused for different request gets 422 and a retry arriving while first request is processed gets 409.
+type Idempotency struct {
+	cfg  config.Idempotency
+	repo idempotencyrepo.RepoInterface
+}
+
+func NewIdempotency(cfg *config.Config, repo idempotencyrepo.RepoInterface) *Idempotency {
+	return &Idempotency{cfg: cfg.Idempotency, repo: repo}
+}
+
+// Handle returns middleware of idempotent requests, it must run after authentication, so keys are scoped by user.
+func (i *Idempotency) Handle(c *gin.Context) {
+	key := c.GetHeader(HeaderIdempotencyKey)
+	if !i.cfg.Enabled || key == "" || !isMutating(c.Request.Method) {
+		return
+	}
+
+	if !validIdempotencyKey(key) {
+		httpresp.Error(c, http.StatusBadRequest, httpresp.ErrKeyIdempotencyInvalidKey.Error(), nil)
+
+		return
+	}
+
+	fingerprint, err := i.fingerprint(c)
+	if errors.Is(err, errIdempotencyBodyTooLarge) {
+		httpresp.Error(
+			c,
+			http.StatusRequestEntityTooLarge,
+			httpresp.ErrKeyIdempotencyBodyTooLarge.Error(),
+			map[string]any{"max_size": i.cfg.MaxSpoolSize},
+		)
+
+		return
+	}
+
+	if err != nil {
+		httpresp.Error(
+			c,
+			http.StatusBadRequest,
+			httpresp.ErrKeyHTTPValidatorsDecodeFail.Error(),
+			map[string]any{"msg_err": err.Error()},
+		)
+
+		return
+	}
+
+	// handlers may not close body, spooled body keeps its temporary file open until it is closed
+	body := c.Request.Body
+	defer body.Close()
+
+	// owner is random, so a request whose lock expired can't complete or release record of a retry
+	now := time.Now()
+	record := &idempotency.Record{
+		ID:          i.scope(c) + " " + c.Request.Method + " " + c.FullPath() + " " + key,
+		Fingerprint: fingerprint,
+		Owner:       newRequestID(),
+		Status:      idempotency.StatusProcessing,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(i.cfg.LockTimeout),
+	}
+
+	existing, err := i.repo.Acquire(c, record)
+	if err != nil {
+		// request is processed without protection rather than failed
+		c.Next()
+
+		return
+	}
+
+	if existing != nil {
+		i.reject(c, record, existing)
+
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer, limit: i.cfg.MaxResponseSize}
+	c.Writer = recorder
+
+	// client may be gone, outcome of request must be recorded anyway
+	ctx := logger.CopyFields(context.Background(), c)
+
+	// deferred, so key of a panicking request is freed instead of being held until lock timeout
+	completed := false
+	defer func() {
+		if !completed {
+			_ = i.repo.Release(ctx, record.ID, record.Owner)
+		}
+	}()
+
+	c.Next()
+	completed = true
+
+	status := recorder.Status()
+	if status >= http.StatusInternalServerError || recorder.overflow {
+		_ = i.repo.Release(ctx, record.ID, record.Owner)
+
+		return
+	}
+
+	_ = i.repo.Complete(
+		ctx,
+		record.ID,
+		record.Owner,
+		status,
+		recorder.Header().Get("Content-Type"),
+		recorder.body.Bytes(),
+		time.Now().Add(i.cfg.TTL),
+	)
+}
+
+// reject responds to request whose key is taken by existing record.
+func (i *Idempotency) reject(c *gin.Context, record, existing *idempotency.Record) {
+	switch {
+	case existing.Fingerprint != record.Fingerprint:
+		httpresp.Error(c, http.StatusUnprocessableEntity, httpresp.ErrKeyIdempotencyKeyMismatch.Error(), nil)
+	case existing.Status != idempotency.StatusCompleted:
+		httpresp.Error(c, http.StatusConflict, httpresp.ErrKeyIdempotencyInProgress.Error(), nil)
+	default:
+		c.Header(HeaderIdempotentReplayed, "true")
+		c.Data(existing.ResponseStatus, existing.ContentType, existing.Body)
+		c.Abort()
+	}
+}
+
+// scope returns client the key belongs to, keys of different clients never collide.
+func (i *Idempotency) scope(c *gin.Context) string {
+	if userID := authen.GetUserID(c); userID != "" {
+		return "user:" + userID
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+// fingerprint hashes method, URI and body of request, body is restored for handler.
+//
+// Bodies up to MaxBodySize are buffered in memory, larger ones are spooled to a temporary file while they are hashed,
+// errIdempotencyBodyTooLarge is returned if body is larger than MaxSpoolSize.
+func (i *Idempotency) fingerprint(c *gin.Context) (string, error) {
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+
+	body := c.Request.Body
+	if body == nil || body == http.NoBody {
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+
+	head, err := io.ReadAll(io.LimitReader(body, i.cfg.MaxBodySize+1))
+	if err != nil {
+		return "", err
+	}
+
+	hash.Write(head)
+
+	if int64(len(head)) <= i.cfg.MaxBodySize {
+		c.Request.Body = io.NopCloser(bytes.NewReader(head))
+
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+
+	spooled, err := spool(io.MultiReader(bytes.NewReader(head), io.TeeReader(body, hash)), i.cfg.MaxSpoolSize)
+	if err != nil {
+		return "", err
+	}
+
+	c.Request.Body = spooled
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// spool copies r to a temporary file and returns the file rewound, errIdempotencyBodyTooLarge is returned
+// if r is larger than maxSize.
+//
+// File is removed at once, its content stays readable until it is closed.
+func spool(r io.Reader, maxSize int64) (*os.File, error) {
+	file, err := os.CreateTemp("", "idempotency-*")
+	if err != nil {
+		return nil, err
+	}
+
+	_ = os.Remove(file.Name())
+
+	written, err := io.Copy(file, io.LimitReader(r, maxSize+1))
+	if err == nil && written > maxSize {
+		err = errIdempotencyBodyTooLarge
+	}
+
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+
+	if err != nil {
+		_ = file.Close()
+
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
+
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// responseRecorder keeps body written to response up to limit, overflow is set if body is larger.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.record(data)
+
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.record([]byte(s))
+
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *responseRecorder) record(data []byte) {
+	if w.overflow {
+		return
+	}
+
+	if w.body.Len()+len(data) > w.limit {
+		w.overflow = true
+		w.body.Reset()
+
+		return
+	}
+
+	w.body.Write(data)
+}
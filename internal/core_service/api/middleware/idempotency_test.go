@@ -0,0 +1,259 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	config "github.com/golang/be/config/core_service"
+	"github.com/golang/be/internal/core_service/entity/idempotency"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryIdempotencyRepo struct {
+	mu      sync.Mutex
+	records map[string]*idempotency.Record
+}
+
+func (r *memoryIdempotencyRepo) Acquire(_ context.Context, record *idempotency.Record) (*idempotency.Record, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.records[record.ID]; ok {
+		return existing, nil
+	}
+
+	r.records[record.ID] = record
+
+	return nil, nil
+}
+
+func (r *memoryIdempotencyRepo) Complete(
+	_ context.Context,
+	id, owner string,
+	status int,
+	contentType string,
+	body []byte,
+	expiresAt time.Time,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.records[id]
+	if !ok || record.Owner != owner || record.Status != idempotency.StatusProcessing {
+		return nil
+	}
+
+	record.Status = idempotency.StatusCompleted
+	record.ResponseStatus = status
+	record.ContentType = contentType
+	record.Body = body
+	record.ExpiresAt = expiresAt
+
+	return nil
+}
+
+func (r *memoryIdempotencyRepo) Release(_ context.Context, id, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if record, ok := r.records[id]; ok && record.Owner == owner && record.Status == idempotency.StatusProcessing {
+		delete(r.records, id)
+	}
+
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &memoryIdempotencyRepo{records: map[string]*idempotency.Record{}}
+	middleware := NewIdempotency(
+		&config.Config{Idempotency: config.Idempotency{
+			Enabled:         true,
+			TTL:             time.Hour,
+			LockTimeout:     time.Minute,
+			MaxBodySize:     1024,
+			MaxSpoolSize:    4096,
+			MaxResponseSize: 1024,
+		}},
+		repo,
+	)
+
+	created := 0
+	failing := true
+
+	engine := gin.New()
+	engine.Use(gin.RecoveryWithWriter(io.Discard), middleware.Handle)
+	engine.POST(
+		"/orders", func(c *gin.Context) {
+			created++
+			c.JSON(http.StatusCreated, gin.H{"id": created})
+		},
+	)
+	engine.POST(
+		"/flaky", func(c *gin.Context) {
+			if failing {
+				c.Status(http.StatusServiceUnavailable)
+
+				return
+			}
+
+			c.Status(http.StatusOK)
+		},
+	)
+
+	engine.POST(
+		"/uploads", func(c *gin.Context) {
+			body, err := io.ReadAll(c.Request.Body)
+			assert.NoError(t, err)
+
+			c.String(http.StatusCreated, strconv.Itoa(len(body)))
+		},
+	)
+
+	// lock of request expires while it is processed and a retry acquires key
+	engine.POST(
+		"/expired", func(c *gin.Context) {
+			repo.mu.Lock()
+			repo.records["ip:192.0.2.1 POST /expired key-7"] = &idempotency.Record{
+				Owner:  "retry",
+				Status: idempotency.StatusProcessing,
+			}
+			repo.mu.Unlock()
+
+			c.Status(http.StatusOK)
+		},
+	)
+
+	engine.POST(
+		"/panic", func(c *gin.Context) {
+			if failing {
+				panic("handler fail")
+			}
+
+			c.Status(http.StatusOK)
+		},
+	)
+
+	post := func(path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set(HeaderIdempotencyKey, key)
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	t.Run(
+		"replay stored response", func(t *testing.T) {
+			first := post("/orders", "key-1", `{"qty":1}`)
+			assert.Equal(t, http.StatusCreated, first.Code)
+
+			replay := post("/orders", "key-1", `{"qty":1}`)
+			assert.Equal(t, http.StatusCreated, replay.Code)
+			assert.Equal(t, first.Body.String(), replay.Body.String())
+			assert.Equal(t, "true", replay.Header().Get(HeaderIdempotentReplayed))
+			assert.Equal(t, 1, created)
+		},
+	)
+
+	t.Run(
+		"reject key reused for different request", func(t *testing.T) {
+			recorder := post("/orders", "key-1", `{"qty":2}`)
+			assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			assert.Equal(t, 1, created)
+		},
+	)
+
+	t.Run(
+		"reject request in progress", func(t *testing.T) {
+			repo.records["ip:192.0.2.1 POST /orders key-2"] = &idempotency.Record{
+				Fingerprint: "unknown",
+				Status:      idempotency.StatusProcessing,
+			}
+
+			recorder := post("/orders", "key-2", `{"qty":1}`)
+			assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+			repo.records["ip:192.0.2.1 POST /orders key-2"].Fingerprint = middleware.mustFingerprint(t, "/orders", `{"qty":1}`)
+
+			recorder = post("/orders", "key-2", `{"qty":1}`)
+			assert.Equal(t, http.StatusConflict, recorder.Code)
+		},
+	)
+
+	t.Run(
+		"release key of failed request", func(t *testing.T) {
+			assert.Equal(t, http.StatusServiceUnavailable, post("/flaky", "key-3", "").Code)
+
+			failing = false
+			assert.Equal(t, http.StatusOK, post("/flaky", "key-3", "").Code)
+		},
+	)
+
+	t.Run(
+		"release key of panicking request", func(t *testing.T) {
+			failing = true
+			assert.Equal(t, http.StatusInternalServerError, post("/panic", "key-4", "").Code)
+
+			failing = false
+			assert.Equal(t, http.StatusOK, post("/panic", "key-4", "").Code)
+		},
+	)
+
+	t.Run(
+		"fingerprint whole spooled body", func(t *testing.T) {
+			body := strings.Repeat("a", 2048)
+
+			recorder := post("/uploads", "key-5", body)
+			assert.Equal(t, http.StatusCreated, recorder.Code)
+			assert.Equal(t, "2048", recorder.Body.String())
+
+			// same size and content type, only the end differs
+			recorder = post("/uploads", "key-5", body[:2047]+"b")
+			assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+		},
+	)
+
+	t.Run(
+		"reject body too large to fingerprint", func(t *testing.T) {
+			recorder := post("/uploads", "key-6", strings.Repeat("a", 4097))
+			assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+		},
+	)
+
+	t.Run(
+		"keep record acquired by retry after lock expired", func(t *testing.T) {
+			assert.Equal(t, http.StatusOK, post("/expired", "key-7", "").Code)
+
+			record := repo.records["ip:192.0.2.1 POST /expired key-7"]
+			assert.Equal(t, "retry", record.Owner)
+			assert.Equal(t, idempotency.StatusProcessing, record.Status)
+		},
+	)
+
+	t.Run(
+		"reject invalid key", func(t *testing.T) {
+			recorder := post("/orders", "bad\nkey", `{"qty":1}`)
+			assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		},
+	)
+}
+
+func (i *Idempotency) mustFingerprint(t *testing.T, path, body string) string {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+
+	fingerprint, err := i.fingerprint(c)
+	assert.NoError(t, err)
+
+	return fingerprint
+}
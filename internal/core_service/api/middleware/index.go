@@ -5,8 +5,25 @@ import (
 	"go.uber.org/fx"
 )
 
+// Route groups rate limits, body limits and CORS policies are configured for.
+const (
+	RouteGroupPublic = "public"
+	RouteGroupUser   = "user"
+	RouteGroupAdmin  = "admin"
+)
+
 var Module = fx.Options(
+	fx.Provide(NewRateLimiter),
+	fx.Provide(NewIdempotency),
+	fx.Provide(NewCORS),
+	fx.Provide(NewBodyLimiter),
 	fx.Provide(authen.NewAuthenticatorDecoder),
+	fx.Provide(authen.NewAdminAuthenticator),
 	fx.Provide(fx.Annotate(authen.NewUserAuthenticator, fx.ResultTags(`name:"user"`))),
-	fx.Provide(fx.Annotate(authen.NewAdminAuthenticator, fx.ResultTags(`name:"admin"`))),
+	fx.Provide(
+		fx.Annotate(
+			func(admin *authen.AdminAuthenticator) authen.AuthenticatorInterface { return admin },
+			fx.ResultTags(`name:"admin"`),
+		),
+	),
 )
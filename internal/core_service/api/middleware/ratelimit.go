@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	config "github.com/golang/be/config/core_service"
+	"github.com/golang/be/internal/core_service/api/middleware/authen"
+	"github.com/golang/be/pkg/common/httpresp"
+	"github.com/golang/be/pkg/common/logger"
+	"github.com/golang/be/pkg/common/ratelimit"
+)
+
+// Rate limit headers of IETF draft RateLimit header fields for HTTP.
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRateLimitPolicy    = "RateLimit-Policy"
+	HeaderRetryAfter         = "Retry-After"
+)
+
+// RateLimiter limits requests of each client by token buckets kept in store.
+type RateLimiter struct {
+	cfg   config.RateLimit
+	store ratelimit.Store
+}
+
+func NewRateLimiter(cfg *config.Config, store ratelimit.Store) *RateLimiter {
+	return &RateLimiter{cfg: cfg.RateLimit, store: store}
+}
+
+// Limit returns middleware limiting requests of route group, routes with own limit are limited by it instead.
+//
+// It must run after authentication of group, so authenticated users are limited by their UID.
+func (l *RateLimiter) Limit(group string) gin.HandlerFunc {
+	groupLimit := toLimit(l.cfg.Groups[group])
+
+	return func(c *gin.Context) {
+		if !l.cfg.Enabled {
+			return
+		}
+
+		limit, scope := groupLimit, group
+
+		route := c.Request.Method + " " + c.FullPath()
+		if rule, ok := l.cfg.Routes[route]; ok {
+			limit, scope = toLimit(rule), route
+		}
+
+		if !limit.Enabled() {
+			return
+		}
+
+		result, err := l.store.Take(c, scope+"|"+l.clientKey(c), limit)
+		if err != nil {
+			// a broken store must not take api down
+			logger.WithContext(c).Errorw(
+				"take rate limit token fail",
+				"err", err,
+			)
+
+			return
+		}
+
+		c.Header(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+		c.Header(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+		c.Header(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(result.Reset)))
+		c.Header(HeaderRateLimitPolicy, strconv.Itoa(result.Limit)+";w="+strconv.Itoa(ceilSeconds(limit.Period)))
+
+		if !result.Allowed {
+			retryAfter := ceilSeconds(result.RetryAfter)
+			c.Header(HeaderRetryAfter, strconv.Itoa(retryAfter))
+
+			httpresp.Error(
+				c,
+				http.StatusTooManyRequests,
+				httpresp.ErrKeyRateLimitExceeded.Error(),
+				map[string]any{"retry_after": retryAfter},
+			)
+		}
+	}
+}
+
+// clientKey identifies client by authenticated user, API key or IP.
+func (l *RateLimiter) clientKey(c *gin.Context) string {
+	if userID := authen.GetUserID(c); userID != "" {
+		return "user:" + userID
+	}
+
+	if l.cfg.APIKeyHeader != "" {
+		if apiKey := c.GetHeader(l.cfg.APIKeyHeader); apiKey != "" {
+			// keys are not kept in memory of store
+			sum := sha256.Sum256([]byte(apiKey))
+
+			return "key:" + hex.EncodeToString(sum[:16])
+		}
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+func toLimit(rule config.RateLimitRule) ratelimit.Limit {
+	return ratelimit.Limit{Requests: rule.Requests, Period: rule.Period, Burst: rule.Burst}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
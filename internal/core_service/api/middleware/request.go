@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/be/internal/core_service/api/middleware/authen"
+	"github.com/golang/be/pkg/common/logger"
+	"github.com/golang/be/pkg/common/tracing"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const HeaderRequestID = "X-Request-ID"
+
+// validRequestID matches request IDs accepted from clients, others are replaced so they can't forge log lines.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID sets ID of request from X-Request-ID header or generates it, ID is returned in same header.
+//
+// ID and route are carried by request context, so logs of use cases and repos handling request include them.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(HeaderRequestID)
+		if !validRequestID.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+
+		c.Header(HeaderRequestID, requestID)
+
+		ctx := logger.ContextWithFields(c.Request.Context(), "request_id", requestID, "route", c.FullPath())
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
+// AccessLog logs each request with its status, latency and user, server errors are logged at error level.
+//
+// Successful requests of quietPaths such as probes are not logged.
+func AccessLog(quietPaths ...string) gin.HandlerFunc {
+	quiet := make(map[string]bool, len(quietPaths))
+	for _, path := range quietPaths {
+		quiet[path] = true
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status < http.StatusBadRequest && quiet[c.Request.URL.Path] {
+			return
+		}
+
+		level := zapcore.InfoLevel
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = zapcore.ErrorLevel
+		case status >= http.StatusBadRequest:
+			level = zapcore.WarnLevel
+		}
+
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.String("route", c.FullPath()),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("client_ip", c.ClientIP()),
+			zap.Int("size", c.Writer.Size()),
+			zap.String("request_id", c.Writer.Header().Get(HeaderRequestID)),
+		}
+
+		if userID := authen.GetUserID(c); userID != "" {
+			fields = append(fields, zap.String("user_id", userID))
+		}
+
+		if traceID := tracing.TraceID(c.Request.Context()); traceID != "" {
+			fields = append(fields, zap.String("trace_id", traceID))
+		}
+
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("errors", c.Errors.String()))
+		}
+
+		logger.Log(level, "request", fields...)
+	}
+}
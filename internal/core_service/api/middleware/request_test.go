@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/be/pkg/common/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var fields []any
+
+	engine := gin.New()
+	engine.ContextWithFallback = true
+	engine.Use(RequestID(), AccessLog())
+	engine.GET(
+		"/products/:productId", func(c *gin.Context) {
+			// handlers pass gin context to use cases
+			fields = logger.Fields(c)
+		},
+	)
+
+	t.Run(
+		"accept client request ID", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/products/1", nil)
+			req.Header.Set(HeaderRequestID, "client-id.1")
+			recorder := httptest.NewRecorder()
+			engine.ServeHTTP(recorder, req)
+
+			assert.Equal(t, "client-id.1", recorder.Header().Get(HeaderRequestID))
+			assert.Equal(t, []any{"request_id", "client-id.1", "route", "/products/:productId"}, fields)
+		},
+	)
+
+	t.Run(
+		"replace invalid request ID", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/products/1", nil)
+			req.Header.Set(HeaderRequestID, "forged\nline")
+			recorder := httptest.NewRecorder()
+			engine.ServeHTTP(recorder, req)
+
+			requestID := recorder.Header().Get(HeaderRequestID)
+			assert.Len(t, requestID, 32)
+			assert.Equal(t, requestID, fields[1])
+		},
+	)
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	config "github.com/golang/be/config/core_service"
+)
+
+// Timeout returns middleware setting deadline of request context, routes with own timeout get it instead of default.
+//
+// Handlers pass gin context to use cases, so Mongo queries and other operations honoring context fail once deadline
+// passes and handler responds with 504. Handler itself is not interrupted.
+func Timeout(cfg *config.Config) gin.HandlerFunc {
+	defaultTimeout := cfg.Timeout.Default
+	routes := cfg.Timeout.Routes
+
+	return func(c *gin.Context) {
+		timeout, ok := routes[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			timeout = defaultTimeout
+		}
+
+		if timeout <= 0 {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
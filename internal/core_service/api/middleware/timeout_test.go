@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	config "github.com/golang/be/config/core_service"
+	"github.com/golang/be/pkg/common/httpresp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{Timeout: config.Timeout{
+		Default: 10 * time.Millisecond,
+		Routes:  map[string]time.Duration{"POST /uploads/:uploadId": time.Hour},
+	}}
+
+	var deadline time.Time
+
+	engine := gin.New()
+	engine.ContextWithFallback = true
+	engine.Use(Timeout(cfg))
+	engine.GET(
+		"/products", func(c *gin.Context) {
+			// use cases get gin context
+			<-c.Done()
+			httpresp.InternalServerError(c)
+		},
+	)
+	engine.POST(
+		"/uploads/:uploadId", func(c *gin.Context) {
+			deadline, _ = c.Deadline()
+		},
+	)
+
+	t.Run(
+		"respond timeout once deadline passes", func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/products", nil))
+
+			assert.Equal(t, http.StatusGatewayTimeout, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), httpresp.ErrKeySystemTimeout.Error())
+		},
+	)
+
+	t.Run(
+		"apply timeout of route", func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/uploads/1", nil))
+
+			assert.WithinDuration(t, time.Now().Add(time.Hour), deadline, time.Minute)
+		},
+	)
+}
@@ -3,12 +3,16 @@ package api
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	config "github.com/golang/be/config/core_service"
 	"github.com/golang/be/internal/core_service/api/middleware"
 	"github.com/golang/be/internal/core_service/api/middleware/authen"
 	"github.com/golang/be/internal/core_service/docs"
+	"github.com/golang/be/pkg/common/health"
+	"github.com/golang/be/pkg/common/metrics"
+	"github.com/golang/be/pkg/core_service/objectstorage"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/fx"
@@ -16,10 +20,17 @@ import (
 
 type RouteParams struct {
 	fx.In
-	Engine    *gin.Engine
-	Cfg       *config.Config
-	UserAuth  authen.AuthenticatorInterface `name:"user"`
-	AdminAuth authen.AuthenticatorInterface `name:"admin"`
+	Engine      *gin.Engine
+	Cfg         *config.Config
+	UserAuth    authen.AuthenticatorInterface `name:"user"`
+	AdminAuth   authen.AuthenticatorInterface `name:"admin"`
+	Storage     objectstorage.Storage
+	Health      *health.Registry
+	Metrics     *metrics.Registry
+	Limiter     *middleware.RateLimiter
+	Idempotency *middleware.Idempotency
+	CORS        *middleware.CORS
+	BodyLimiter *middleware.BodyLimiter
 }
 
 type Router struct {
@@ -70,21 +81,62 @@ func NewRouter(params RouteParams, config *config.Config) Router {
 		},
 	)
 
-	// K8s probe
-	engine.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+	// K8s probes, healthz is kept for probes configured before livez existed
+	engine.GET("/livez", health.LivenessHandler)
+	engine.GET("/healthz", health.LivenessHandler)
+	engine.GET("/readyz", params.Health.ReadinessHandler)
+
+	// Prometheus metrics, they have own listener if port is configured
+	if params.Cfg.Metrics.Port == "" {
+		engine.GET(params.Cfg.Metrics.Path, gin.WrapH(params.Metrics.Handler()))
+	}
+
+	// Serve objects of local storage, GCS serves objects itself
+	if localStorage, ok := params.Storage.(*objectstorage.LocalStorage); ok {
+		engine.GET(strings.TrimSuffix(localStorage.RoutePath(), "/")+"/*key", localStorage.ServeObject)
+	}
+
+	const (
+		publicPath = "/api/v1"
+		adminPath  = publicPath + "/admin"
+		userPath   = publicPath + "/user"
+	)
 
 	// configs cors
-	engine.Use(middleware.CorsConfigs())
+	engine.Use(params.CORS.Handle(map[string]string{
+		publicPath: middleware.RouteGroupPublic,
+		adminPath:  middleware.RouteGroupAdmin,
+		userPath:   middleware.RouteGroupUser,
+	}))
+
+	// compress responses of api, objects of storage are already compressed
+	engine.Use(middleware.Compress(params.Cfg))
+
+	// bound time use cases and repositories can spend on request
+	engine.Use(middleware.Timeout(params.Cfg))
 
 	// public group
-	publicGroup := engine.Group("/api/v1")
+	publicGroup := engine.Group(publicPath).Use(
+		params.Limiter.Limit(middleware.RouteGroupPublic),
+		params.Idempotency.Handle,
+		params.BodyLimiter.Limit(middleware.RouteGroupPublic),
+	)
 
 	// admin group
-	adminGroup := publicGroup.Group("/admin").Use(params.AdminAuth.Authenticate)
+	adminGroup := engine.Group(adminPath).Use(
+		params.AdminAuth.Authenticate,
+		params.Limiter.Limit(middleware.RouteGroupAdmin),
+		params.Idempotency.Handle,
+		params.BodyLimiter.Limit(middleware.RouteGroupAdmin),
+	)
 
 	// user group
-	// userGroup := publicGroup.Group("/user").Use(params.UserAuth.Authenticate)
-	userGroup := publicGroup.Group("/user")
+	userGroup := engine.Group(userPath).Use(
+		params.UserAuth.Authenticate,
+		params.Limiter.Limit(middleware.RouteGroupUser),
+		params.Idempotency.Handle,
+		params.BodyLimiter.Limit(middleware.RouteGroupUser),
+	)
 
 	return Router{
 		PublicGroup: publicGroup,
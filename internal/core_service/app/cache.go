@@ -0,0 +1,12 @@
+package app
+
+import (
+	config "github.com/golang/be/config/core_service"
+	"github.com/golang/be/pkg/common/cache"
+)
+
+// NewCacheStore provides store of cached values, a shared store such as Redis can replace it
+// to keep caches of instances consistent.
+func NewCacheStore(cfg *config.Config) cache.Store {
+	return cache.NewLRUStore(cfg.Cache.MaxEntries, cfg.Cache.MaxBytes)
+}
@@ -9,21 +9,23 @@ import (
 	"github.com/golang/be/internal/core_service/api/handler"
 	"github.com/golang/be/internal/core_service/api/middleware"
 	"github.com/golang/be/internal/core_service/domain"
+	"github.com/golang/be/internal/core_service/domain/media"
+	"github.com/golang/be/internal/core_service/domain/product"
 	"github.com/golang/be/internal/core_service/repo"
+	"github.com/golang/be/pkg/common/health"
 	"github.com/golang/be/pkg/common/logger"
+	"github.com/golang/be/pkg/common/metrics"
 	"github.com/golang/be/pkg/common/mongo"
 	"github.com/golang/be/pkg/common/msgtranslate"
+	"github.com/golang/be/pkg/common/ratelimit"
+	"github.com/golang/be/pkg/common/tracing"
 	"github.com/golang/be/pkg/core_service/firebase"
-	"github.com/golang/be/pkg/core_service/firebase/storage"
+	"github.com/golang/be/pkg/core_service/objectstorage"
 	"go.uber.org/fx"
 )
 
 var InternalOptions = fx.Options(
-	// Common Config
-	fx.Provide(cmconfig.NewConfig),
-
-	// Config
-	fx.Provide(config.NewConfig),
+	CoreOptions,
 
 	// Server
 	fx.Provide(NewServer),
@@ -37,6 +39,28 @@ var InternalOptions = fx.Options(
 	// Middleware
 	middleware.Module,
 
+	// Jobs
+	fx.Invoke(media.ScheduleGC),
+	fx.Invoke(product.ScheduleReindexSearch),
+
+	// Health checks
+	fx.Invoke(RegisterHealthChecks),
+
+	// Metrics listener, if it is separated from api
+	fx.Invoke(RunMetricsServer),
+
+	// Run server last, so it stops before its dependencies
+	fx.Invoke(RunServer),
+)
+
+// CoreOptions provides config, use cases and repos, it is shared by server and command line jobs.
+var CoreOptions = fx.Options(
+	// Common Config
+	fx.Provide(cmconfig.NewConfig),
+
+	// Config
+	fx.Provide(config.NewConfig),
+
 	// Use Case
 	domain.Module,
 
@@ -53,12 +77,28 @@ var InternalOptions = fx.Options(
 var PackageOptions = fx.Options(
 	// Mongo
 	fx.Provide(mongo.New),
+	fx.Provide(mongo.NewReadPreferences),
 
 	// Firebase
 	fx.Provide(firebase.NewApps),
 
 	// Storage
-	fx.Provide(storage.NewBucketHandler),
+	fx.Provide(objectstorage.New),
+
+	// Health
+	fx.Provide(health.NewRegistry),
+
+	// Metrics
+	fx.Provide(metrics.NewRegistry),
+
+	// Tracing
+	fx.Provide(tracing.New),
+
+	// Rate limit, buckets are kept by each instance
+	fx.Provide(fx.Annotate(ratelimit.NewMemoryStore, fx.As(new(ratelimit.Store)))),
+
+	// Cache, values are kept by each instance
+	fx.Provide(NewCacheStore),
 
 	// Logger
 	fx.Provide(logger.Init),
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"firebase.google.com/go/auth"
+	config "github.com/golang/be/config/core_service"
+	"github.com/golang/be/pkg/common/health"
+	"github.com/golang/be/pkg/core_service/objectstorage"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.uber.org/fx"
+)
+
+// healthCheckKey is a key which never exists, looking it up proves storage is reachable and authorized.
+const healthCheckKey = "healthz/probe"
+
+type healthParams struct {
+	fx.In
+	Cfg     *config.Config
+	Health  *health.Registry
+	DB      *mongo.Database
+	Storage objectstorage.Storage
+	Auth    *auth.Client
+}
+
+// RegisterHealthChecks registers checks of dependencies to readiness probe.
+func RegisterHealthChecks(params healthParams) {
+	timeout := params.Cfg.HTTP.HealthCheckTimeout
+
+	params.Health.Register(
+		"mongo", timeout, func(ctx context.Context) error {
+			return params.DB.Client().Ping(ctx, readpref.Primary())
+		},
+	)
+
+	params.Health.Register(
+		"storage", timeout, func(ctx context.Context) error {
+			_, err := params.Storage.Stat(ctx, healthCheckKey)
+			if errors.Is(err, objectstorage.ErrObjectNotFound) {
+				return nil
+			}
+
+			return err
+		},
+	)
+
+	// Firebase Auth is rate limited per project, every pod probing it each few seconds adds up
+	params.Health.Register(
+		"firebase", timeout, health.Cached(
+			params.Cfg.HTTP.HealthCheckCacheTTL, func(ctx context.Context) error {
+				_, err := params.Auth.GetUser(ctx, healthCheckKey)
+				if auth.IsUserNotFound(err) {
+					return nil
+				}
+
+				return err
+			},
+		),
+	)
+}
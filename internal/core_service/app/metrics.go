@@ -0,0 +1,53 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	config "github.com/golang/be/config/core_service"
+	"github.com/golang/be/pkg/common/metrics"
+	"go.uber.org/fx"
+)
+
+// RunMetricsServer serves metrics by own listener if metrics port is configured,
+// otherwise they are served by api router.
+func RunMetricsServer(cfg *config.Config, metricsRegistry *metrics.Registry, lc fx.Lifecycle) {
+	if cfg.Metrics.Port == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Metrics.Path, metricsRegistry.Handler())
+
+	server := &http.Server{
+		Addr:              cfg.Metrics.Host + ":" + cfg.Metrics.Port,
+		Handler:           mux,
+		ReadHeaderTimeout: 2 * time.Second,
+	}
+
+	lc.Append(
+		fx.Hook{
+			OnStart: func(context.Context) error {
+				listener, err := net.Listen("tcp", server.Addr)
+				if err != nil {
+					return err
+				}
+
+				log.Println("Starting metrics server at " + server.Addr)
+
+				go func() {
+					if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+						log.Println("Metrics server stopped unexpectedly:", err)
+					}
+				}()
+
+				return nil
+			},
+			OnStop: server.Shutdown,
+		},
+	)
+}
@@ -3,51 +3,209 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"log"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	config "github.com/golang/be/config/core_service"
+	"github.com/golang/be/internal/core_service/api/middleware"
+	"github.com/golang/be/pkg/common/health"
+	"github.com/golang/be/pkg/common/metrics"
+	"github.com/golang/be/pkg/common/tlsreload"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 type params struct {
 	fx.In
-	Cfg *config.Config
-	Lc  fx.Lifecycle
+	Cfg            *config.Config
+	Lc             fx.Lifecycle
+	Metrics        *metrics.Registry
+	TracerProvider trace.TracerProvider
 }
 
-// NewServer starts the http server.
-func NewServer(params params) *gin.Engine {
+type serverResult struct {
+	fx.Out
+	Engine     *gin.Engine
+	HTTPServer *http.Server
+}
+
+// NewServer returns gin engine and http server serving it, RunServer starts the server.
+//
+// Server serves HTTP/2 over TLS if certificate is configured, otherwise it can accept h2c for internal traffic.
+func NewServer(params params) (serverResult, error) {
+	cfg := params.Cfg.HTTP
+
+	// probes and metrics are requested too often to be traced and logged
+	quietPaths := []string{"/livez", "/healthz", "/readyz", params.Cfg.Metrics.Path}
+	untracedPaths := make(map[string]bool, len(quietPaths))
+	for _, path := range quietPaths {
+		untracedPaths[path] = true
+	}
+
 	engine := gin.New()
-	engine.Use(gin.Logger(), gin.Recovery())
+	if err := engine.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return serverResult{}, err
+	}
+
+	// handlers pass gin context to use cases, it must expose values of request context such as span
+	engine.ContextWithFallback = true
+	engine.Use(
+		otelgin.Middleware(
+			params.Cfg.App.Name,
+			otelgin.WithTracerProvider(params.TracerProvider),
+			otelgin.WithFilter(func(r *http.Request) bool { return !untracedPaths[r.URL.Path] }),
+		),
+		middleware.RequestID(),
+		middleware.AccessLog(quietPaths...),
+		// metrics wrap recovery so panicking requests are counted as 500
+		params.Metrics.Middleware(),
+		gin.Recovery(),
+	)
 	httpServer := &http.Server{
-		Addr:              params.Cfg.HTTP.Host + ":" + params.Cfg.HTTP.Port,
+		Addr:              cfg.Host + ":" + cfg.Port,
 		Handler:           engine,
-		ReadHeaderTimeout: 2 * time.Second,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+
+	if httpServer.ReadHeaderTimeout == 0 {
+		httpServer.ReadHeaderTimeout = 2 * time.Second
+	}
+
+	if cfg.TLS.CertFile != "" {
+		tlsConfig, err := newTLSConfig(cfg.TLS, params.Lc)
+		if err != nil {
+			return serverResult{}, err
+		}
+
+		httpServer.TLSConfig = tlsConfig
+	} else if cfg.H2C {
+		httpServer.Handler = h2c.NewHandler(engine, &http2.Server{IdleTimeout: cfg.IdleTimeout})
+	}
+
+	return serverResult{Engine: engine, HTTPServer: httpServer}, nil
+}
+
+// newTLSConfig returns TLS config serving certificate reloaded from disk and verifying client certificates if configured.
+func newTLSConfig(cfg config.HTTPTLS, lc fx.Lifecycle) (*tls.Config, error) {
+	reloader, err := tlsreload.New(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	clientAuth, err := tlsreload.ClientAuth(cfg.ClientAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+		ClientAuth:     clientAuth,
+		NextProtos:     []string{http2.NextProtoTLS, "http/1.1"},
+	}
+
+	if cfg.ClientCAFile != "" {
+		tlsConfig.ClientCAs, err = tlsreload.LoadCertPool(cfg.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
 	}
 
+	if cfg.ReloadInterval > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		lc.Append(
+			fx.Hook{
+				OnStart: func(context.Context) error {
+					go reloader.Watch(ctx, cfg.ReloadInterval)
+
+					return nil
+				},
+				OnStop: func(context.Context) error {
+					cancel()
+
+					return nil
+				},
+			},
+		)
+	}
+
+	return tlsConfig, nil
+}
+
+type runParams struct {
+	fx.In
+	Cfg        *config.Config
+	Lc         fx.Lifecycle
+	Shutdowner fx.Shutdowner
+	HTTPServer *http.Server
+	Health     *health.Registry
+}
+
+// RunServer starts http server and shuts it down gracefully.
+//
+// It must be invoked after all routes are registered, so its stop hook runs before dependencies are closed.
+// On stop, readiness fails first, then in-flight requests are drained until shutdown timeout.
+func RunServer(params runParams) {
+	httpServer := params.HTTPServer
+	cfg := params.Cfg.HTTP
+
 	params.Lc.Append(
 		fx.Hook{
 			OnStart: func(context.Context) error {
-				log.Println("Starting HTTPS server at " + httpServer.Addr)
+				listener, err := net.Listen("tcp", httpServer.Addr)
+				if err != nil {
+					return err
+				}
+
+				serve := httpServer.Serve
+				if httpServer.TLSConfig != nil {
+					serve = func(listener net.Listener) error { return httpServer.ServeTLS(listener, "", "") }
+
+					log.Println("Starting HTTPS server at " + httpServer.Addr)
+				} else {
+					log.Println("Starting HTTP server at " + httpServer.Addr)
+				}
+
 				go func() {
-					err := httpServer.ListenAndServe()
-					if err != nil {
-						panic(err)
+					if err := serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+						log.Println("HTTP server stopped unexpectedly:", err)
+
+						_ = params.Shutdowner.Shutdown(fx.ExitCode(1))
 					}
 				}()
 
 				return nil
 			},
 			OnStop: func(ctx context.Context) error {
-				log.Println("Stopping HTTPS server.")
+				params.Health.SetShuttingDown()
 
-				return httpServer.Shutdown(ctx)
+				log.Println("Draining HTTP server.")
+
+				select {
+				case <-time.After(cfg.DrainDelay):
+				case <-ctx.Done():
+				}
+
+				shutdownCtx, cancel := context.WithTimeout(ctx, cfg.ShutdownTimeout)
+				defer cancel()
+
+				log.Println("Stopping HTTP server.")
+
+				return httpServer.Shutdown(shutdownCtx)
 			},
 		},
 	)
-
-	return engine
 }
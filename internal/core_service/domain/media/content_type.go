@@ -0,0 +1,80 @@
+package media
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	cmentity "github.com/golang/be/internal/common/entity"
+)
+
+// sniffLen is number of leading bytes used to detect content type, same as http.DetectContentType.
+const sniffLen = 512
+
+const (
+	ContentTypeJPEG     = "image/jpeg"
+	ContentTypePNG      = "image/png"
+	ContentTypeGIF      = "image/gif"
+	ContentTypeWebP     = "image/webp"
+	ContentTypeMP4      = "video/mp4"
+	ContentTypeWebM     = "video/webm"
+	ContentTypeGLTFBin  = "model/gltf-binary"
+	ContentTypeGLTFJSON = "model/gltf+json"
+)
+
+// allowedContentTypes specific whitelist of content types for each media type, with file extension used in key.
+var allowedContentTypes = map[string]map[string]string{
+	cmentity.MediaTypeImage: {
+		ContentTypeJPEG: ".jpg",
+		ContentTypePNG:  ".png",
+		ContentTypeGIF:  ".gif",
+		ContentTypeWebP: ".webp",
+	},
+	cmentity.MediaTypeVideo: {
+		ContentTypeMP4:  ".mp4",
+		ContentTypeWebM: ".webm",
+	},
+	cmentity.MediaTypeThreeDimension: {
+		ContentTypeGLTFBin:  ".glb",
+		ContentTypeGLTFJSON: ".gltf",
+	},
+}
+
+// DetectContentType returns content type of file from its leading bytes.
+//
+// Client supplied content type is never trusted, fileName is only used to tell glTF JSON from other JSON documents.
+func DetectContentType(head []byte, fileName string) string {
+	if bytes.HasPrefix(head, []byte("glTF")) {
+		return ContentTypeGLTFBin
+	}
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return ""
+	}
+
+	trimmed := bytes.TrimLeft(head, " \t\r\n")
+	if contentType == "text/plain" &&
+		bytes.HasPrefix(trimmed, []byte("{")) &&
+		strings.EqualFold(filepath.Ext(fileName), ".gltf") {
+		return ContentTypeGLTFJSON
+	}
+
+	return contentType
+}
+
+// IsValidMediaType checks whether mediaType is supported.
+func IsValidMediaType(mediaType string) bool {
+	_, ok := allowedContentTypes[mediaType]
+
+	return ok
+}
+
+// fileExtension returns extension of contentType if it is allowed for mediaType.
+func fileExtension(mediaType, contentType string) (string, bool) {
+	ext, ok := allowedContentTypes[mediaType][contentType]
+
+	return ext, ok
+}
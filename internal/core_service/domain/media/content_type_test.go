@@ -0,0 +1,46 @@
+package media
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectContentType(t *testing.T) {
+	t.Run(
+		"detect", func(t *testing.T) {
+			inputData := []struct {
+				head     []byte
+				fileName string
+				result   string
+			}{
+				{head: []byte("\x89PNG\x0D\x0A\x1A\x0A"), fileName: "a.jpg", result: ContentTypePNG},
+				{head: []byte("\xFF\xD8\xFF\xE0"), fileName: "a.jpg", result: ContentTypeJPEG},
+				{head: []byte("RIFF\x00\x00\x00\x00WEBPVP"), fileName: "a.webp", result: ContentTypeWebP},
+				{head: []byte("\x00\x00\x00\x14ftypisom\x00\x00\x02\x00mp41"), fileName: "a.mp4", result: ContentTypeMP4},
+				{head: []byte("\x1A\x45\xDF\xA3"), fileName: "a.webm", result: ContentTypeWebM},
+				{head: []byte("glTF\x02\x00\x00\x00"), fileName: "a.bin", result: ContentTypeGLTFBin},
+				{head: []byte(` {"asset":{"version":"2.0"}}`), fileName: "a.gltf", result: ContentTypeGLTFJSON},
+				{head: []byte(`{"asset":{"version":"2.0"}}`), fileName: "a.json", result: "text/plain"},
+			}
+
+			for _, item := range inputData {
+				assert.Equal(t, item.result, DetectContentType(item.head, item.fileName), item.fileName)
+			}
+		},
+	)
+
+	t.Run(
+		"whitelist", func(t *testing.T) {
+			ext, ok := fileExtension("image", ContentTypePNG)
+			assert.True(t, ok)
+			assert.Equal(t, ".png", ext)
+
+			_, ok = fileExtension("image", ContentTypeMP4)
+			assert.False(t, ok)
+
+			_, ok = fileExtension("image", "text/html")
+			assert.False(t, ok)
+		},
+	)
+}
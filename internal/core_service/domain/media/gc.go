@@ -0,0 +1,220 @@
+package media
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	config "github.com/golang/be/config/core_service"
+	"github.com/golang/be/pkg/common/logger"
+	"github.com/golang/be/pkg/common/tracing"
+	"go.uber.org/fx"
+)
+
+// variantKeyPattern matches keys of image variants, first group is key of original without extension.
+var variantKeyPattern = regexp.MustCompile(`^(.+)_\d+w\.[a-z]+$`)
+
+// GCReport define result of a garbage collection.
+//
+// Orphans specific objects no product or unfinished upload references, they are deleted unless DryRun.
+type GCReport struct {
+	DryRun      bool           `json:"dry_run"`
+	Scanned     int            `json:"scanned"`
+	Orphans     []OrphanObject `json:"orphans"`
+	OrphanBytes int64          `json:"orphan_bytes"`
+	Deleted     int            `json:"deleted"`
+	StartedAt   time.Time      `json:"started_at"`
+	FinishedAt  time.Time      `json:"finished_at"`
+}
+
+type OrphanObject struct {
+	Key       string    `json:"key"`
+	Size      int64     `json:"size"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (u *UseCase) CollectGarbage(ctx context.Context, dryRun bool) (*GCReport, error) {
+	ctx, span := tracing.Start(ctx, "media.CollectGarbage")
+	defer span.End()
+
+	report := &GCReport{DryRun: dryRun, Orphans: []OrphanObject{}, StartedAt: time.Now()}
+
+	// objects are listed before references, so an object attached meanwhile is still seen as referenced
+	var objects []OrphanObject
+
+	for _, prefix := range u.cfg.Media.GC.Prefixes {
+		infos, err := u.storage.List(ctx, prefix)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, info := range infos {
+			objects = append(objects, OrphanObject{Key: info.Key, Size: info.Size, UpdatedAt: info.UpdatedAt})
+		}
+	}
+
+	references, err := u.findReferences(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := report.StartedAt.Add(-u.cfg.Media.GC.GracePeriod)
+
+	for _, object := range objects {
+		report.Scanned++
+
+		if object.UpdatedAt.After(deadline) || references.has(object.Key, u.storage.URL(object.Key)) {
+			continue
+		}
+
+		report.Orphans = append(report.Orphans, object)
+		report.OrphanBytes += object.Size
+
+		if dryRun {
+			continue
+		}
+
+		if err = u.storage.Delete(ctx, object.Key); err != nil {
+			logger.WithContext(ctx).Errorw(
+				"delete orphaned object fail",
+				"key", object.Key,
+				"err", err,
+			)
+
+			continue
+		}
+
+		report.Deleted++
+	}
+
+	report.FinishedAt = time.Now()
+
+	return report, nil
+}
+
+type mediaReferences struct {
+	keys map[string]bool
+	urls map[string]bool
+	// bases specific referenced keys without extension, variants of them are referenced too
+	bases map[string]bool
+}
+
+func (r *mediaReferences) add(key, url string) {
+	if key != "" {
+		r.keys[key] = true
+		r.bases[strings.TrimSuffix(key, path.Ext(key))] = true
+	}
+
+	if url != "" {
+		r.urls[url] = true
+	}
+}
+
+func (r *mediaReferences) has(key, url string) bool {
+	if r.keys[key] || r.urls[url] {
+		return true
+	}
+
+	match := variantKeyPattern.FindStringSubmatch(key)
+
+	return match != nil && r.bases[match[1]]
+}
+
+// findReferences returns keys and URLs of media of products and parts of unfinished uploads.
+//
+// Products may only have URLs of media, e.g. ones created before media has key.
+func (u *UseCase) findReferences(ctx context.Context) (*mediaReferences, error) {
+	references := &mediaReferences{keys: map[string]bool{}, urls: map[string]bool{}, bases: map[string]bool{}}
+
+	allMedia, err := u.productRepo.FindAllMedia(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, media := range allMedia {
+		references.add(media.Key, media.URL)
+		references.add("", media.ThumbnailURL)
+
+		for _, variant := range media.Variants {
+			references.add(variant.Key, variant.URL)
+		}
+	}
+
+	partKeys, err := u.uploadSessionRepo.FindUploadingPartKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range partKeys {
+		references.add(key, "")
+	}
+
+	return references, nil
+}
+
+// ScheduleGC runs garbage collection of media periodically while server is running.
+func ScheduleGC(cfg *config.Config, useCase UseCaseInterface, lc fx.Lifecycle) {
+	if cfg.Media.GC.Interval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(
+		fx.Hook{
+			OnStart: func(_ context.Context) error {
+				go func() {
+					defer close(done)
+
+					ticker := time.NewTicker(cfg.Media.GC.Interval)
+					defer ticker.Stop()
+
+					for {
+						select {
+						case <-ctx.Done():
+							return
+						case <-ticker.C:
+							runGC(ctx, useCase, cfg.Media.GC.DryRun)
+						}
+					}
+				}()
+
+				return nil
+			},
+			OnStop: func(stopCtx context.Context) error {
+				cancel()
+
+				select {
+				case <-done:
+					return nil
+				case <-stopCtx.Done():
+					return stopCtx.Err()
+				}
+			},
+		},
+	)
+}
+
+func runGC(ctx context.Context, useCase UseCaseInterface, dryRun bool) {
+	report, err := useCase.CollectGarbage(ctx, dryRun)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"collect media garbage fail",
+			"err", err,
+		)
+
+		return
+	}
+
+	logger.WithContext(ctx).Infow(
+		"collect media garbage",
+		"dry_run", report.DryRun,
+		"scanned", report.Scanned,
+		"orphans", len(report.Orphans),
+		"orphan_bytes", report.OrphanBytes,
+		"deleted", report.Deleted,
+	)
+}
@@ -0,0 +1,87 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	cmentity "github.com/golang/be/internal/common/entity"
+	"github.com/golang/be/pkg/core_service/gltf"
+)
+
+// ModelLimitError tells which complexity limit a 3D model exceeds.
+type ModelLimitError struct {
+	Name string
+	Max  int
+}
+
+func (e *ModelLimitError) Error() string {
+	return fmt.Sprintf("3d model has more than %d %s", e.Max, e.Name)
+}
+
+// inspectModel validates stored glTF model and returns its statistics.
+func (u *UseCase) inspectModel(ctx context.Context, key, contentType string, size int64) (*cmentity.ModelInfo, error) {
+	reader, _, err := u.storage.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	defer reader.Close()
+
+	var stats *gltf.Stats
+
+	if contentType == ContentTypeGLTFBin {
+		stats, err = gltf.InspectGLB(reader, size)
+	} else {
+		stats, err = gltf.InspectJSON(reader)
+	}
+
+	switch {
+	case errors.Is(err, gltf.ErrInvalidAsset), errors.Is(err, gltf.ErrUnsupportedAsset):
+		return nil, ErrInvalidModel
+	case errors.Is(err, gltf.ErrExternalReference):
+		return nil, ErrModelExternalReference
+	case err != nil:
+		return nil, err
+	}
+
+	if err = u.checkModelLimits(stats); err != nil {
+		return nil, err
+	}
+
+	return &cmentity.ModelInfo{
+		Version:    stats.Version,
+		Generator:  stats.Generator,
+		Meshes:     stats.Meshes,
+		Materials:  stats.Materials,
+		Triangles:  stats.Triangles,
+		Vertices:   stats.Vertices,
+		Nodes:      stats.Nodes,
+		Textures:   stats.Textures,
+		Animations: stats.Animations,
+		BoundsMin:  stats.BoundsMin,
+		BoundsMax:  stats.BoundsMax,
+	}, nil
+}
+
+func (u *UseCase) checkModelLimits(stats *gltf.Stats) error {
+	limits := u.cfg.Media.ThreeDimension
+
+	for _, limit := range []struct {
+		name  string
+		value int
+		max   int
+	}{
+		{"triangles", stats.Triangles, limits.MaxTriangles},
+		{"vertices", stats.Vertices, limits.MaxVertices},
+		{"meshes", stats.Meshes, limits.MaxMeshes},
+		{"materials", stats.Materials, limits.MaxMaterials},
+		{"textures", stats.Textures, limits.MaxTextures},
+	} {
+		if limit.max > 0 && limit.value > limit.max {
+			return &ModelLimitError{Name: limit.name, Max: limit.max}
+		}
+	}
+
+	return nil
+}
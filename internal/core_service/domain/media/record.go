@@ -0,0 +1,75 @@
+package media
+
+import (
+	"context"
+	"time"
+
+	cmentity "github.com/golang/be/internal/common/entity"
+	mediaentity "github.com/golang/be/internal/core_service/entity/media"
+	"github.com/golang/be/pkg/common/tracing"
+)
+
+// createRecord keeps uploaded image media, so it is found by URL with its variants when it is attached.
+//
+// Record expires with grace period of garbage collection, media not attached by then is removed.
+func (u *UseCase) createRecord(ctx context.Context, media *cmentity.Media) error {
+	if media.Type != cmentity.MediaTypeImage {
+		return nil
+	}
+
+	now := time.Now()
+
+	return u.mediaRepo.Create(
+		ctx,
+		&mediaentity.Record{
+			ID:        media.Key,
+			Media:     *media,
+			CreatedAt: now,
+			ExpiresAt: now.Add(u.cfg.Media.GC.GracePeriod),
+		},
+	)
+}
+
+func (u *UseCase) AttachImage(
+	ctx context.Context,
+	url string,
+	attach func(ctx context.Context, media *cmentity.Media) error,
+) (*cmentity.Media, error) {
+	ctx, span := tracing.Start(ctx, "media.AttachImage")
+	defer span.End()
+
+	record, err := u.mediaRepo.FindOneByURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if record == nil {
+		return nil, ErrMediaNotFound
+	}
+
+	media := record.Media
+	if err = attach(ctx, &media); err != nil {
+		return nil, err
+	}
+
+	if len(media.Variants) > 0 {
+		return &media, nil
+	}
+
+	// variants stored after record was found are missed by update of products if it ran before attach
+	record, err = u.mediaRepo.FindOneByURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if record == nil || len(record.Media.Variants) == 0 {
+		return &media, nil
+	}
+
+	media = record.Media
+	if _, err = u.productRepo.UpdateMediaVariants(ctx, cmentity.MediaTypeImage, &media); err != nil {
+		return nil, err
+	}
+
+	return &media, nil
+}
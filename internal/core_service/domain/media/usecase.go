@@ -0,0 +1,510 @@
+package media
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	config "github.com/golang/be/config/core_service"
+	cmentity "github.com/golang/be/internal/common/entity"
+	"github.com/golang/be/internal/core_service/entity/upload"
+	mediarepo "github.com/golang/be/internal/core_service/repo/media"
+	productrepo "github.com/golang/be/internal/core_service/repo/product"
+	uploadrepo "github.com/golang/be/internal/core_service/repo/upload"
+	"github.com/golang/be/pkg/common/logger"
+	"github.com/golang/be/pkg/common/metrics"
+	"github.com/golang/be/pkg/common/mongo"
+	"github.com/golang/be/pkg/common/tracing"
+	"github.com/golang/be/pkg/common/worker"
+	"github.com/golang/be/pkg/core_service/imaging"
+	"github.com/golang/be/pkg/core_service/objectstorage"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+)
+
+const (
+	mediaKeyPrefix = "media/"
+	partKeyPrefix  = "uploads/"
+)
+
+var (
+	ErrInvalidMediaType       = errors.New("invalid media type")
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+	ErrFileTooLarge           = errors.New("file too large")
+	ErrUploadSessionNotFound  = errors.New("upload session not found")
+	ErrUploadSessionCompleted = errors.New("upload session completed")
+	ErrUploadSessionFailed    = errors.New("upload session failed")
+	ErrUploadOffsetMismatch   = errors.New("upload offset mismatch")
+	ErrUploadChunkEmpty       = errors.New("upload chunk empty")
+	ErrUploadChunkTooLarge    = errors.New("upload chunk too large")
+	ErrMediaNotFound          = errors.New("media not found")
+	ErrInvalidContainer       = errors.New("file doesn't match its container")
+	ErrInvalidModel           = errors.New("invalid 3d model")
+	ErrModelExternalReference = errors.New("3d model references external file")
+)
+
+// FileInfo specific a file to be stored as media.
+//
+// FileName specific name of file on client, it only helps to detect content type.
+// Private specific whether file can only be downloaded by signed URL.
+type FileInfo struct {
+	MediaType string
+	FileName  string
+	Private   bool
+}
+
+type UseCaseInterface interface {
+	// Upload stores file and returns its media.
+	Upload(ctx context.Context, info FileInfo, file io.Reader) (*cmentity.Media, error)
+	// CreateUploadSession starts a resumable upload of a file with size bytes.
+	CreateUploadSession(ctx context.Context, userID string, info FileInfo, size int64) (*upload.Session, error)
+	// GetUploadSession returns session of user, ErrUploadSessionNotFound is returned if it belongs to another user.
+	GetUploadSession(ctx context.Context, userID, id string) (*upload.Session, error)
+	// AppendChunk stores chunk at offset of session of user, the file is stored as media when its last chunk arrives.
+	//
+	// Session whose file is rejected when it is stored fails, its parts are deleted.
+	AppendChunk(ctx context.Context, userID, id string, offset int64, chunk io.Reader) (*upload.Session, error)
+	// MaxSize returns max size in bytes of a file of mediaType.
+	MaxSize(mediaType string) int64
+	// SignURL returns a short-lived URL granting read access to media and its expiry.
+	SignURL(ctx context.Context, media *cmentity.Media) (string, time.Time, error)
+	// UploadPoster stores poster as image media and sets it as thumbnail of video.
+	UploadPoster(ctx context.Context, video *cmentity.Media, fileName string, poster io.Reader) (*cmentity.Media, error)
+	// AttachImage finds image media uploaded at url and passes it to attach, e.g. to set it to product.
+	// Variants generated after media is found are set to products referencing it, ErrMediaNotFound is returned
+	// if no media was uploaded at url or it expired.
+	AttachImage(
+		ctx context.Context,
+		url string,
+		attach func(ctx context.Context, media *cmentity.Media) error,
+	) (*cmentity.Media, error)
+	// CollectGarbage removes objects which are older than grace period and no product references.
+	CollectGarbage(ctx context.Context, dryRun bool) (*GCReport, error)
+}
+
+type UseCase struct {
+	cfg               *config.Config
+	storage           objectstorage.Storage
+	uploadSessionRepo uploadrepo.RepoInterface
+	productRepo       productrepo.RepoInterface
+	mediaRepo         mediarepo.RepoInterface
+	imagePool         *worker.Pool
+	uploads           *prometheus.CounterVec
+	uploadedBytes     *prometheus.CounterVec
+}
+
+func (u *UseCase) Upload(ctx context.Context, info FileInfo, file io.Reader) (*cmentity.Media, error) {
+	ctx, span := tracing.Start(ctx, "media.Upload")
+	defer span.End()
+
+	if !IsValidMediaType(info.MediaType) {
+		return nil, ErrInvalidMediaType
+	}
+
+	media, err := u.store(ctx, info, file)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = u.createRecord(ctx, media); err != nil {
+		u.deleteObjects(ctx, media.Key)
+
+		return nil, err
+	}
+
+	u.waitVariants(ctx, media, u.enqueueVariants(ctx, media))
+
+	return media, nil
+}
+
+func (u *UseCase) CreateUploadSession(
+	ctx context.Context,
+	userID string,
+	info FileInfo,
+	size int64,
+) (*upload.Session, error) {
+	ctx, span := tracing.Start(ctx, "media.CreateUploadSession")
+	defer span.End()
+
+	if !IsValidMediaType(info.MediaType) {
+		return nil, ErrInvalidMediaType
+	}
+
+	if size > u.MaxSize(info.MediaType) {
+		return nil, ErrFileTooLarge
+	}
+
+	session := &upload.Session{
+		Entity: cmentity.Entity{
+			ID:     mongo.NewID(),
+			Status: upload.StatusUploading,
+		},
+		UserID:    userID,
+		MediaType: info.MediaType,
+		FileName:  info.FileName,
+		Size:      size,
+		Private:   info.Private,
+		Parts:     []upload.Part{},
+		ExpiresAt: cmentity.UnixTimestamp(time.Now().Add(u.cfg.Media.UploadSessionTTL)),
+	}
+
+	if err := u.uploadSessionRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (u *UseCase) GetUploadSession(ctx context.Context, userID, id string) (*upload.Session, error) {
+	ctx, span := tracing.Start(ctx, "media.GetUploadSession")
+	defer span.End()
+
+	session, err := u.uploadSessionRepo.FindOneByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// sessions of other users are hidden, their media can be private
+	if session == nil || session.UserID != userID || time.Now().After(session.ExpiresAt.Time()) {
+		return nil, ErrUploadSessionNotFound
+	}
+
+	return session, nil
+}
+
+func (u *UseCase) AppendChunk(
+	ctx context.Context,
+	userID, id string,
+	offset int64,
+	chunk io.Reader,
+) (*upload.Session, error) {
+	ctx, span := tracing.Start(ctx, "media.AppendChunk")
+	defer span.End()
+
+	session, err := u.GetUploadSession(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch session.Status {
+	case upload.StatusCompleted:
+		return nil, ErrUploadSessionCompleted
+	case upload.StatusFailed:
+		return nil, ErrUploadSessionFailed
+	}
+
+	if offset != session.UploadedSize {
+		return nil, ErrUploadOffsetMismatch
+	}
+
+	// all chunks arrived but completing failed before, retry it
+	if session.UploadedSize == session.Size {
+		return u.completeUploadSession(ctx, session)
+	}
+
+	// a chunk must not go beyond declared size of file
+	limit := session.Size - offset
+	if limit > u.cfg.Media.MaxChunkSize {
+		limit = u.cfg.Media.MaxChunkSize
+	}
+
+	// key of part is unique so concurrent chunks at same offset never overwrite each other
+	partKey := fmt.Sprintf("%s%s/%020d-%s", partKeyPrefix, session.ID, offset, mongo.NewID())
+
+	info, err := u.storage.Put(ctx, partKey, newLimitedReader(chunk, limit), objectstorage.PutOptions{})
+	if err != nil {
+		if errors.Is(err, ErrFileTooLarge) {
+			return nil, ErrUploadChunkTooLarge
+		}
+
+		return nil, err
+	}
+
+	if info.Size == 0 {
+		u.deleteObjects(ctx, partKey)
+
+		return nil, ErrUploadChunkEmpty
+	}
+
+	part := upload.Part{Key: partKey, Offset: offset, Size: info.Size}
+
+	appended, err := u.uploadSessionRepo.AppendPart(ctx, id, part)
+	if err != nil || !appended {
+		u.deleteObjects(ctx, partKey)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, ErrUploadOffsetMismatch
+	}
+
+	session.Parts = append(session.Parts, part)
+	session.UploadedSize += info.Size
+
+	if session.UploadedSize < session.Size {
+		return session, nil
+	}
+
+	return u.completeUploadSession(ctx, session)
+}
+
+func (u *UseCase) MaxSize(mediaType string) int64 {
+	switch mediaType {
+	case cmentity.MediaTypeImage:
+		return u.cfg.Media.MaxImageSize
+	case cmentity.MediaTypeVideo:
+		return u.cfg.Media.MaxVideoSize
+	case cmentity.MediaTypeThreeDimension:
+		return u.cfg.Media.MaxThreeDimensionSize
+	default:
+		return 0
+	}
+}
+
+// completeUploadSession joins parts of session into media and removes the parts.
+//
+// Session fails if its file is rejected, other errors such as of storage keep it, so completing can be retried.
+func (u *UseCase) completeUploadSession(ctx context.Context, session *upload.Session) (*upload.Session, error) {
+	readers := make([]io.Reader, 0, len(session.Parts))
+	keys := make([]string, 0, len(session.Parts))
+
+	for _, part := range session.Parts {
+		reader, _, err := u.storage.Get(ctx, part.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		defer reader.Close()
+
+		readers = append(readers, reader)
+		keys = append(keys, part.Key)
+	}
+
+	media, err := u.store(
+		ctx,
+		FileInfo{MediaType: session.MediaType, FileName: session.FileName, Private: session.Private},
+		io.MultiReader(readers...),
+	)
+	if err != nil {
+		if isRejected(err) {
+			u.failUploadSession(ctx, session, keys)
+		}
+
+		return nil, err
+	}
+
+	if err = u.createRecord(ctx, media); err != nil {
+		u.deleteObjects(ctx, media.Key)
+
+		return nil, err
+	}
+
+	if err = u.uploadSessionRepo.Complete(ctx, session.ID.String(), media); err != nil {
+		return nil, err
+	}
+
+	u.deleteObjects(ctx, keys...)
+	u.waitVariants(ctx, media, u.enqueueVariants(ctx, media))
+
+	session.Status = upload.StatusCompleted
+	session.Media = media
+
+	return session, nil
+}
+
+// failUploadSession marks session failed and deletes its parts, parts left by failure are collected as orphans.
+func (u *UseCase) failUploadSession(ctx context.Context, session *upload.Session, keys []string) {
+	if err := u.uploadSessionRepo.Fail(ctx, session.ID.String()); err != nil {
+		return
+	}
+
+	u.deleteObjects(ctx, keys...)
+
+	session.Status = upload.StatusFailed
+	session.Parts = []upload.Part{}
+}
+
+// isRejected reports whether err rejects uploaded file, so uploading it again fails the same way.
+func isRejected(err error) bool {
+	var limitErr *ModelLimitError
+
+	return errors.Is(err, ErrUnsupportedContentType) ||
+		errors.Is(err, ErrFileTooLarge) ||
+		errors.Is(err, ErrInvalidContainer) ||
+		errors.Is(err, ErrInvalidModel) ||
+		errors.Is(err, ErrModelExternalReference) ||
+		errors.As(err, &limitErr)
+}
+
+func (u *UseCase) SignURL(_ context.Context, media *cmentity.Media) (string, time.Time, error) {
+	if media == nil || media.Key == "" {
+		return "", time.Time{}, ErrMediaNotFound
+	}
+
+	expiresAt := time.Now().Add(u.cfg.Media.SignedURLTTL)
+
+	signedURL, err := u.storage.SignedURL(media.Key, expiresAt)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return signedURL, expiresAt, nil
+}
+
+// store validates content type and size of file, then writes it to object storage.
+func (u *UseCase) store(ctx context.Context, info FileInfo, file io.Reader) (*cmentity.Media, error) {
+	reader := bufio.NewReaderSize(file, sniffLen)
+
+	head, err := reader.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	contentType := DetectContentType(head, info.FileName)
+
+	ext, ok := fileExtension(info.MediaType, contentType)
+	if !ok {
+		return nil, ErrUnsupportedContentType
+	}
+
+	if info.MediaType == cmentity.MediaTypeVideo && !matchesDeclaredType(info.MediaType, info.FileName, contentType) {
+		return nil, ErrInvalidContainer
+	}
+
+	key := mediaKeyPrefix + info.MediaType + "/" + mongo.NewID().String() + ext
+
+	// metadata such as location where photo was taken must not be published
+	var content io.Reader = reader
+	if contentType == ContentTypeJPEG {
+		if content, err = imaging.StripJPEGMetadata(reader); err != nil {
+			return nil, ErrUnsupportedContentType
+		}
+	}
+
+	object, err := u.storage.Put(
+		ctx,
+		key,
+		newLimitedReader(content, u.MaxSize(info.MediaType)),
+		objectstorage.PutOptions{ContentType: contentType, Public: !info.Private},
+	)
+	if err != nil {
+		return nil, convertPutError(err)
+	}
+
+	media := &cmentity.Media{
+		URL:         u.storage.URL(key),
+		Type:        info.MediaType,
+		ContentType: contentType,
+		Size:        object.Size,
+		Key:         key,
+		Private:     info.Private,
+	}
+
+	switch info.MediaType {
+	case cmentity.MediaTypeVideo:
+		media.VideoInfo, err = u.probeVideo(ctx, key, contentType, object.Size)
+	case cmentity.MediaTypeThreeDimension:
+		media.ModelInfo, err = u.inspectModel(ctx, key, contentType, object.Size)
+	}
+
+	if err != nil {
+		u.deleteObjects(ctx, key)
+
+		return nil, err
+	}
+
+	u.uploads.WithLabelValues(info.MediaType).Inc()
+	u.uploadedBytes.WithLabelValues(info.MediaType).Add(float64(object.Size))
+
+	return media, nil
+}
+
+func (u *UseCase) deleteObjects(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := u.storage.Delete(ctx, key); err != nil {
+			logger.WithContext(ctx).Errorw(
+				"delete object fail",
+				"key", key,
+				"err", err,
+			)
+		}
+	}
+}
+
+func convertPutError(err error) error {
+	if errors.Is(err, ErrFileTooLarge) {
+		return ErrFileTooLarge
+	}
+
+	return err
+}
+
+// limitedReader reads from r until n bytes, reading more returns ErrFileTooLarge.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func newLimitedReader(r io.Reader, n int64) io.Reader {
+	return &limitedReader{r: r, n: n}
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, ErrFileTooLarge
+	}
+
+	// read one extra byte to detect exceeding
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+
+	if l.n < 0 {
+		return n + int(l.n), ErrFileTooLarge
+	}
+
+	return n, err
+}
+
+func NewUseCase(
+	cfg *config.Config,
+	storage objectstorage.Storage,
+	uploadSessionRepo uploadrepo.RepoInterface,
+	productRepo productrepo.RepoInterface,
+	mediaRepo mediarepo.RepoInterface,
+	metricsRegistry *metrics.Registry,
+	lc fx.Lifecycle,
+) UseCaseInterface {
+	imagePool := worker.NewPool("image", cfg.Media.Image.Workers, cfg.Media.Image.QueueSize)
+
+	lc.Append(
+		fx.Hook{
+			OnStart: func(_ context.Context) error {
+				imagePool.Start()
+
+				return nil
+			},
+			OnStop: imagePool.Stop,
+		},
+	)
+
+	return &UseCase{
+		cfg:               cfg,
+		storage:           storage,
+		uploadSessionRepo: uploadSessionRepo,
+		productRepo:       productRepo,
+		mediaRepo:         mediaRepo,
+		imagePool:         imagePool,
+		uploads:           metricsRegistry.Counter("media_uploads_total", "Number of stored media.", "type"),
+		uploadedBytes: metricsRegistry.Counter(
+			"media_uploaded_bytes_total", "Size of stored media in bytes.", "type",
+		),
+	}
+}
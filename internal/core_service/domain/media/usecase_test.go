@@ -0,0 +1,667 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	config "github.com/golang/be/config/core_service"
+	cmentity "github.com/golang/be/internal/common/entity"
+	mediaentity "github.com/golang/be/internal/core_service/entity/media"
+	"github.com/golang/be/internal/core_service/entity/upload"
+	productrepo "github.com/golang/be/internal/core_service/repo/product"
+	"github.com/golang/be/pkg/common/metrics"
+	"github.com/golang/be/pkg/core_service/imaging"
+	"github.com/golang/be/pkg/core_service/objectstorage"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx/fxtest"
+	"golang.org/x/image/webp"
+)
+
+type fakeUploadSessionRepo struct {
+	mu       sync.Mutex
+	sessions map[string]upload.Session
+}
+
+func (r *fakeUploadSessionRepo) Create(_ context.Context, session *upload.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sessions[session.ID.String()] = *session
+
+	return nil
+}
+
+func (r *fakeUploadSessionRepo) FindOneByID(_ context.Context, id string) (*upload.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+
+	session.Parts = append([]upload.Part{}, session.Parts...)
+
+	return &session, nil
+}
+
+func (r *fakeUploadSessionRepo) AppendPart(_ context.Context, id string, part upload.Part) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session := r.sessions[id]
+	if session.Status != upload.StatusUploading || session.UploadedSize != part.Offset {
+		return false, nil
+	}
+
+	session.UploadedSize += part.Size
+	session.Parts = append(session.Parts, part)
+	r.sessions[id] = session
+
+	return true, nil
+}
+
+func (r *fakeUploadSessionRepo) FindUploadingPartKeys(_ context.Context) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var keys []string
+
+	for _, session := range r.sessions {
+		if session.Status != upload.StatusUploading {
+			continue
+		}
+
+		for _, part := range session.Parts {
+			keys = append(keys, part.Key)
+		}
+	}
+
+	return keys, nil
+}
+
+func (r *fakeUploadSessionRepo) Complete(_ context.Context, id string, media *cmentity.Media) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session := r.sessions[id]
+	session.Status = upload.StatusCompleted
+	session.Media = media
+	r.sessions[id] = session
+
+	return nil
+}
+
+func (r *fakeUploadSessionRepo) Fail(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session := r.sessions[id]
+	session.Status = upload.StatusFailed
+	session.Parts = []upload.Part{}
+	r.sessions[id] = session
+
+	return nil
+}
+
+func (r *fakeUploadSessionRepo) UpdateMediaVariants(_ context.Context, media *cmentity.Media) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, session := range r.sessions {
+		if session.Media != nil && session.Media.Key == media.Key {
+			session.Media.ThumbnailURL = media.ThumbnailURL
+			session.Media.Variants = media.Variants
+			r.sessions[id] = session
+		}
+	}
+
+	return nil
+}
+
+type fakeMediaRepo struct {
+	mu      sync.Mutex
+	records map[string]mediaentity.Record
+}
+
+func (r *fakeMediaRepo) Create(_ context.Context, record *mediaentity.Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records[record.ID] = *record
+
+	return nil
+}
+
+func (r *fakeMediaRepo) FindOneByURL(_ context.Context, url string) (*mediaentity.Record, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, record := range r.records {
+		if record.Media.URL == url {
+			return &record, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *fakeMediaRepo) UpdateVariants(_ context.Context, media *cmentity.Media) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record := r.records[media.Key]
+	record.Media.ThumbnailURL = media.ThumbnailURL
+	record.Media.Variants = media.Variants
+	r.records[media.Key] = record
+
+	return nil
+}
+
+type fakeProductRepo struct {
+	productrepo.RepoInterface
+	updated chan cmentity.Media
+	media   []cmentity.Media
+}
+
+func (r *fakeProductRepo) FindAllMedia(_ context.Context) ([]cmentity.Media, error) {
+	return r.media, nil
+}
+
+func (r *fakeProductRepo) UpdateMediaVariants(_ context.Context, _ string, media *cmentity.Media) ([]string, error) {
+	r.updated <- *media
+
+	return nil, nil
+}
+
+func newTestUseCase(t *testing.T, opts ...func(cfg *config.Config)) (*UseCase, objectstorage.Storage) {
+	t.Helper()
+
+	storage, err := objectstorage.NewLocalStorage(t.TempDir(), "http://localhost/files", "secret")
+	assert.NoError(t, err)
+
+	cfg := &config.Config{
+		Media: config.Media{
+			MaxImageSize:     64,
+			MaxChunkSize:     16,
+			UploadSessionTTL: time.Hour,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	lc := fxtest.NewLifecycle(t)
+	useCase := NewUseCase(
+		cfg,
+		storage,
+		&fakeUploadSessionRepo{sessions: map[string]upload.Session{}},
+		&fakeProductRepo{updated: make(chan cmentity.Media, 1)},
+		&fakeMediaRepo{records: map[string]mediaentity.Record{}},
+		metrics.NewRegistry(),
+		lc,
+	)
+
+	lc.RequireStart()
+	t.Cleanup(lc.RequireStop)
+
+	return useCase.(*UseCase), storage
+}
+
+func TestUpload(t *testing.T) {
+	ctx := context.Background()
+	png := append([]byte("\x89PNG\x0D\x0A\x1A\x0A"), bytes.Repeat([]byte{1}, 32)...)
+	imageInfo := FileInfo{MediaType: cmentity.MediaTypeImage, FileName: "a.png"}
+
+	t.Run(
+		"store media", func(t *testing.T) {
+			useCase, storage := newTestUseCase(t)
+
+			media, err := useCase.Upload(ctx, FileInfo{MediaType: cmentity.MediaTypeImage, FileName: "a.jpg"}, bytes.NewReader(png))
+			assert.NoError(t, err)
+			assert.Equal(t, ContentTypePNG, media.ContentType)
+			assert.Equal(t, int64(len(png)), media.Size)
+			assert.Equal(t, storage.URL(media.Key), media.URL)
+		},
+	)
+
+	t.Run(
+		"reject content type", func(t *testing.T) {
+			useCase, _ := newTestUseCase(t)
+
+			_, err := useCase.Upload(ctx, imageInfo, bytes.NewReader([]byte("<html></html>")))
+			assert.ErrorIs(t, err, ErrUnsupportedContentType)
+		},
+	)
+
+	t.Run(
+		"reject large file", func(t *testing.T) {
+			useCase, storage := newTestUseCase(t)
+
+			large := append(png, bytes.Repeat([]byte{1}, 64)...)
+			_, err := useCase.Upload(ctx, imageInfo, bytes.NewReader(large))
+			assert.ErrorIs(t, err, ErrFileTooLarge)
+
+			objects, err := storage.List(ctx, "")
+			assert.NoError(t, err)
+			assert.Empty(t, objects)
+		},
+	)
+
+	t.Run(
+		"resumable upload", func(t *testing.T) {
+			useCase, storage := newTestUseCase(t)
+
+			session, err := useCase.CreateUploadSession(ctx, "uid", imageInfo, int64(len(png)))
+			assert.NoError(t, err)
+
+			_, err = useCase.AppendChunk(ctx, "uid", session.ID.String(), 8, bytes.NewReader(png[8:]))
+			assert.ErrorIs(t, err, ErrUploadOffsetMismatch)
+
+			_, err = useCase.AppendChunk(ctx, "uid", session.ID.String(), 0, bytes.NewReader(png))
+			assert.ErrorIs(t, err, ErrUploadChunkTooLarge)
+
+			for offset := int64(0); offset < int64(len(png)); offset = session.UploadedSize {
+				end := offset + useCase.cfg.Media.MaxChunkSize
+				if end > int64(len(png)) {
+					end = int64(len(png))
+				}
+
+				session, err = useCase.AppendChunk(ctx, "uid", session.ID.String(), offset, bytes.NewReader(png[offset:end]))
+				if !assert.NoError(t, err) {
+					return
+				}
+			}
+
+			assert.Equal(t, upload.StatusCompleted, session.Status)
+			assert.Equal(t, int64(len(png)), session.Media.Size)
+
+			objects, err := storage.List(ctx, "")
+			assert.NoError(t, err)
+			assert.Len(t, objects, 1)
+			assert.Equal(t, session.Media.Key, objects[0].Key)
+
+			_, err = useCase.AppendChunk(ctx, "uid", session.ID.String(), session.Size, bytes.NewReader(nil))
+			assert.ErrorIs(t, err, ErrUploadSessionCompleted)
+		},
+	)
+
+	t.Run(
+		"hide session of other user", func(t *testing.T) {
+			useCase, _ := newTestUseCase(t)
+
+			session, err := useCase.CreateUploadSession(ctx, "uid", imageInfo, int64(len(png)))
+			assert.NoError(t, err)
+
+			_, err = useCase.GetUploadSession(ctx, "other", session.ID.String())
+			assert.ErrorIs(t, err, ErrUploadSessionNotFound)
+
+			_, err = useCase.AppendChunk(ctx, "other", session.ID.String(), 0, bytes.NewReader(png[:8]))
+			assert.ErrorIs(t, err, ErrUploadSessionNotFound)
+
+			session, err = useCase.GetUploadSession(ctx, "uid", session.ID.String())
+			assert.NoError(t, err)
+			assert.Equal(t, int64(0), session.UploadedSize)
+		},
+	)
+
+	t.Run(
+		"fail session of rejected file", func(t *testing.T) {
+			useCase, storage := newTestUseCase(t)
+
+			html := []byte("<html></html>")
+			session, err := useCase.CreateUploadSession(ctx, "uid", imageInfo, int64(len(html)))
+			assert.NoError(t, err)
+
+			_, err = useCase.AppendChunk(ctx, "uid", session.ID.String(), 0, bytes.NewReader(html))
+			assert.ErrorIs(t, err, ErrUnsupportedContentType)
+
+			session, err = useCase.GetUploadSession(ctx, "uid", session.ID.String())
+			assert.NoError(t, err)
+			assert.Equal(t, upload.StatusFailed, session.Status)
+
+			objects, err := storage.List(ctx, "")
+			assert.NoError(t, err)
+			assert.Empty(t, objects)
+
+			_, err = useCase.AppendChunk(ctx, "uid", session.ID.String(), session.UploadedSize, bytes.NewReader(nil))
+			assert.ErrorIs(t, err, ErrUploadSessionFailed)
+		},
+	)
+
+	t.Run(
+		"reject session larger than limit", func(t *testing.T) {
+			useCase, _ := newTestUseCase(t)
+
+			_, err := useCase.CreateUploadSession(ctx, "uid", imageInfo, 65)
+			assert.ErrorIs(t, err, ErrFileTooLarge)
+		},
+	)
+}
+
+func TestImageVariants(t *testing.T) {
+	ctx := context.Background()
+
+	img := image.NewNRGBA(image.Rect(0, 0, 300, 200))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i)
+	}
+
+	var encoded bytes.Buffer
+	assert.NoError(t, png.Encode(&encoded, img))
+
+	useCase, storage := newTestUseCase(
+		t, func(cfg *config.Config) {
+			cfg.Media.MaxImageSize = int64(encoded.Len())
+			cfg.Media.Image = config.ImageMedia{
+				VariantWidths:  []int{480, 100},
+				VariantFormats: []string{imaging.FormatWebP, imaging.FormatJPEG},
+				JPEGQuality:    80,
+				MaxPixels:      300 * 200,
+				VariantWait:    5 * time.Second,
+				Workers:        1,
+				QueueSize:      1,
+			}
+		},
+	)
+
+	media, err := useCase.Upload(ctx, FileInfo{MediaType: cmentity.MediaTypeImage}, bytes.NewReader(encoded.Bytes()))
+	assert.NoError(t, err)
+
+	// upload waits for variants, they are kept by record of media to be attached later
+	assert.Len(t, media.Variants, 4)
+
+	record, err := useCase.mediaRepo.FindOneByURL(ctx, media.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, media.Variants, record.Media.Variants)
+	assert.Equal(t, media.ThumbnailURL, record.Media.ThumbnailURL)
+
+	var updated cmentity.Media
+
+	select {
+	case updated = <-useCase.productRepo.(*fakeProductRepo).updated:
+	case <-time.After(5 * time.Second):
+		assert.FailNow(t, "variants are not generated")
+	}
+
+	base := strings.TrimSuffix(media.Key, ".png")
+
+	assert.Equal(t, media.Key, updated.Key)
+	assert.Len(t, updated.Variants, 4)
+	assert.Equal(t, storage.URL(base+"_100w.webp"), updated.ThumbnailURL)
+
+	// widths larger than original are capped, images are never upscaled
+	assert.Equal(t, 100, updated.Variants[0].Width)
+	assert.Equal(t, 66, updated.Variants[0].Height)
+	assert.Equal(t, 300, updated.Variants[1].Width)
+	assert.Equal(t, base+"_300w.jpg", updated.Variants[3].Key)
+
+	reader, info, err := storage.Get(ctx, updated.Variants[0].Key)
+	assert.NoError(t, err)
+
+	defer reader.Close()
+
+	decoded, err := webp.Decode(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 100, 66), decoded.Bounds())
+	assert.Equal(t, "image/webp", info.ContentType)
+}
+
+func TestAttachImage(t *testing.T) {
+	ctx := context.Background()
+	png := append([]byte("\x89PNG\x0D\x0A\x1A\x0A"), bytes.Repeat([]byte{1}, 32)...)
+	variants := []cmentity.MediaVariant{{URL: "http://localhost/files/a_100w.jpg", Width: 100}}
+
+	useCase, _ := newTestUseCase(
+		t, func(cfg *config.Config) {
+			cfg.Media.GC.GracePeriod = time.Hour
+		},
+	)
+
+	uploaded, err := useCase.Upload(ctx, FileInfo{MediaType: cmentity.MediaTypeImage}, bytes.NewReader(png))
+	assert.NoError(t, err)
+
+	t.Run(
+		"attach uploaded media", func(t *testing.T) {
+			var attached *cmentity.Media
+
+			media, err := useCase.AttachImage(
+				ctx, uploaded.URL, func(_ context.Context, media *cmentity.Media) error {
+					attached = media
+
+					return nil
+				},
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, uploaded.Key, attached.Key)
+			assert.Equal(t, uploaded.Key, media.Key)
+		},
+	)
+
+	t.Run(
+		"set variants generated while attaching", func(t *testing.T) {
+			media, err := useCase.AttachImage(
+				ctx, uploaded.URL, func(ctx context.Context, media *cmentity.Media) error {
+					// variants are stored right after media is found, products are updated before it is attached
+					return useCase.mediaRepo.UpdateVariants(
+						ctx,
+						&cmentity.Media{Key: media.Key, ThumbnailURL: variants[0].URL, Variants: variants},
+					)
+				},
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, variants, media.Variants)
+
+			select {
+			case updated := <-useCase.productRepo.(*fakeProductRepo).updated:
+				assert.Equal(t, uploaded.Key, updated.Key)
+				assert.Equal(t, variants, updated.Variants)
+			default:
+				assert.Fail(t, "products are not updated")
+			}
+		},
+	)
+
+	t.Run(
+		"unknown media", func(t *testing.T) {
+			_, err := useCase.AttachImage(
+				ctx, "http://localhost/files/unknown.png", func(_ context.Context, _ *cmentity.Media) error {
+					assert.Fail(t, "unknown media is attached")
+
+					return nil
+				},
+			)
+			assert.ErrorIs(t, err, ErrMediaNotFound)
+		},
+	)
+}
+
+func mp4Box(boxType string, payload ...[]byte) []byte {
+	content := bytes.Join(payload, nil)
+
+	return append(append(binary.BigEndian.AppendUint32(nil, uint32(8+len(content))), boxType...), content...)
+}
+
+func TestUploadVideo(t *testing.T) {
+	ctx := context.Background()
+
+	// one second long 640x360 h264 video, sample entry has width and height after 24 bytes
+	mvhd := binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(make([]byte, 12), 1000), 1000)
+	hdlr := append(append(make([]byte, 8), "vide"...), make([]byte, 12)...)
+	sampleEntry := append(make([]byte, 24), 0x02, 0x80, 0x01, 0x68)
+	stsd := append(binary.BigEndian.AppendUint32(make([]byte, 4), 1), mp4Box("avc1", sampleEntry)...)
+	trak := mp4Box("trak", mp4Box("mdia", mp4Box("hdlr", hdlr), mp4Box("minf", mp4Box("stbl", mp4Box("stsd", stsd)))))
+	ftyp := mp4Box("ftyp", []byte("isom\x00\x00\x02\x00mp41"))
+	mdat := mp4Box("mdat", bytes.Repeat([]byte{1}, 64))
+	video := bytes.Join([][]byte{ftyp, mp4Box("moov", mp4Box("mvhd", mvhd), trak), mdat}, nil)
+
+	useCase, storage := newTestUseCase(
+		t, func(cfg *config.Config) {
+			cfg.Media.MaxVideoSize = 1024
+		},
+	)
+
+	t.Run(
+		"store metadata", func(t *testing.T) {
+			media, err := useCase.Upload(ctx, FileInfo{MediaType: cmentity.MediaTypeVideo, FileName: "a.mp4"}, bytes.NewReader(video))
+			assert.NoError(t, err)
+			assert.Equal(
+				t, &cmentity.VideoInfo{
+					Container: "mp4",
+					Duration:  1,
+					Width:     640,
+					Height:    360,
+					Codec:     "h264",
+					Bitrate:   int64(len(video) * 8),
+				}, media.VideoInfo,
+			)
+		},
+	)
+
+	t.Run(
+		"reject mismatched container", func(t *testing.T) {
+			_, err := useCase.Upload(ctx, FileInfo{MediaType: cmentity.MediaTypeVideo, FileName: "a.webm"}, bytes.NewReader(video))
+			assert.ErrorIs(t, err, ErrInvalidContainer)
+		},
+	)
+
+	t.Run(
+		"reject video without moov box", func(t *testing.T) {
+			objects, err := storage.List(ctx, "")
+			assert.NoError(t, err)
+
+			_, err = useCase.Upload(ctx, FileInfo{MediaType: cmentity.MediaTypeVideo}, bytes.NewReader(append(ftyp, mdat...)))
+			assert.ErrorIs(t, err, ErrInvalidContainer)
+
+			// invalid video is removed from storage
+			after, err := storage.List(ctx, "")
+			assert.NoError(t, err)
+			assert.Len(t, after, len(objects))
+		},
+	)
+}
+
+func TestUploadModel(t *testing.T) {
+	ctx := context.Background()
+
+	model := `{
+		"asset": {"version": "2.0"},
+		"scenes": [{"nodes": [0]}],
+		"nodes": [{"mesh": 0}],
+		"meshes": [{"primitives": [{"attributes": {"POSITION": 0}}]}],
+		"accessors": [{"count": 6, "min": [0, 0, 0], "max": [1, 2, 3]}]
+	}`
+	info := FileInfo{MediaType: cmentity.MediaTypeThreeDimension, FileName: "a.gltf"}
+
+	useCase, _ := newTestUseCase(
+		t, func(cfg *config.Config) {
+			cfg.Media.MaxThreeDimensionSize = 1024
+			cfg.Media.ThreeDimension.MaxTriangles = 2
+		},
+	)
+
+	t.Run(
+		"store statistics", func(t *testing.T) {
+			media, err := useCase.Upload(ctx, info, strings.NewReader(model))
+			assert.NoError(t, err)
+			assert.Equal(t, ContentTypeGLTFJSON, media.ContentType)
+			assert.Equal(t, 2, media.ModelInfo.Triangles)
+			assert.Equal(t, [3]float64{1, 2, 3}, media.ModelInfo.BoundsMax)
+		},
+	)
+
+	t.Run(
+		"reject complex model", func(t *testing.T) {
+			_, err := useCase.Upload(ctx, info, strings.NewReader(strings.Replace(model, `"count": 6`, `"count": 9`, 1)))
+
+			var limitErr *ModelLimitError
+			assert.ErrorAs(t, err, &limitErr)
+			assert.Equal(t, "triangles", limitErr.Name)
+		},
+	)
+
+	t.Run(
+		"reject invalid model", func(t *testing.T) {
+			_, err := useCase.Upload(ctx, info, strings.NewReader(`{"asset": {"version": "2.0"}, "meshes": 1}`))
+			assert.ErrorIs(t, err, ErrInvalidModel)
+		},
+	)
+}
+
+func TestCollectGarbage(t *testing.T) {
+	ctx := context.Background()
+
+	useCase, storage := newTestUseCase(
+		t, func(cfg *config.Config) {
+			cfg.Media.GC = config.MediaGC{Prefixes: []string{mediaKeyPrefix, partKeyPrefix}}
+		},
+	)
+
+	for _, key := range []string{
+		"media/image/a.png", "media/image/a_160w.webp", "media/image/b.png", "media/video/c.mp4", "uploads/d/0", "other/e",
+	} {
+		_, err := storage.Put(ctx, key, strings.NewReader("data"), objectstorage.PutOptions{})
+		assert.NoError(t, err)
+	}
+
+	// a.png and its variant are referenced by key, c.mp4 only by URL
+	productRepo := useCase.productRepo.(*fakeProductRepo)
+	productRepo.media = []cmentity.Media{
+		{Key: "media/image/a.png"},
+		{URL: storage.URL("media/video/c.mp4")},
+	}
+
+	t.Run(
+		"dry run", func(t *testing.T) {
+			report, err := useCase.CollectGarbage(ctx, true)
+			assert.NoError(t, err)
+			assert.Equal(t, 5, report.Scanned)
+			assert.Equal(t, []string{"media/image/b.png", "uploads/d/0"}, orphanKeys(report))
+			assert.Equal(t, 0, report.Deleted)
+		},
+	)
+
+	t.Run(
+		"delete orphans", func(t *testing.T) {
+			report, err := useCase.CollectGarbage(ctx, false)
+			assert.NoError(t, err)
+			assert.Equal(t, 2, report.Deleted)
+
+			_, err = storage.Stat(ctx, "media/image/b.png")
+			assert.ErrorIs(t, err, objectstorage.ErrObjectNotFound)
+
+			_, err = storage.Stat(ctx, "media/image/a_160w.webp")
+			assert.NoError(t, err)
+		},
+	)
+
+	t.Run(
+		"keep objects in grace period", func(t *testing.T) {
+			useCase.cfg.Media.GC.GracePeriod = time.Hour
+
+			_, err := storage.Put(ctx, "media/image/f.png", strings.NewReader("data"), objectstorage.PutOptions{})
+			assert.NoError(t, err)
+
+			report, err := useCase.CollectGarbage(ctx, false)
+			assert.NoError(t, err)
+			assert.Empty(t, report.Orphans)
+		},
+	)
+}
+
+func orphanKeys(report *GCReport) []string {
+	keys := make([]string, 0, len(report.Orphans))
+	for _, orphan := range report.Orphans {
+		keys = append(keys, orphan.Key)
+	}
+
+	return keys
+}
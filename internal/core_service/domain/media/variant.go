@@ -0,0 +1,238 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	// register decoders of allowed image content types
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+
+	cmentity "github.com/golang/be/internal/common/entity"
+	"github.com/golang/be/pkg/common/logger"
+	"github.com/golang/be/pkg/core_service/imaging"
+	"github.com/golang/be/pkg/core_service/objectstorage"
+)
+
+var ErrImageTooLarge = errors.New("image too large to be processed")
+
+// enqueueVariants generates variants of image media in background, its record, upload sessions and products using it
+// are updated when done. Returned channel receives media with variants if they are generated, then it is closed,
+// it is nil if no variant is generated.
+//
+// ctx is context of upload, only its log fields are kept by job so logs of job can be related to upload.
+func (u *UseCase) enqueueVariants(ctx context.Context, media *cmentity.Media) <-chan *cmentity.Media {
+	if media.Type != cmentity.MediaTypeImage || len(u.cfg.Media.Image.VariantWidths) == 0 {
+		return nil
+	}
+
+	original := *media
+	done := make(chan *cmentity.Media, 1)
+
+	submitted := u.imagePool.Submit(
+		func(jobCtx context.Context) {
+			defer close(done)
+
+			jobCtx = logger.CopyFields(jobCtx, ctx)
+
+			if err := u.processImage(jobCtx, &original); err != nil {
+				logger.WithContext(jobCtx).Errorw(
+					"generate image variants fail",
+					"key", original.Key,
+					"err", err,
+				)
+			}
+
+			if len(original.Variants) > 0 {
+				done <- &original
+			}
+		},
+	)
+	if !submitted {
+		logger.WithContext(ctx).Errorw(
+			"skip generating image variants",
+			"key", original.Key,
+		)
+
+		return nil
+	}
+
+	return done
+}
+
+// waitVariants sets variants of media once done receives them, it gives up after variant wait of config.
+// Variants generated later are still kept by record and upload session of media.
+func (u *UseCase) waitVariants(ctx context.Context, media *cmentity.Media, done <-chan *cmentity.Media) {
+	if done == nil || u.cfg.Media.Image.VariantWait <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(u.cfg.Media.Image.VariantWait)
+	defer timer.Stop()
+
+	select {
+	case processed, ok := <-done:
+		if ok {
+			media.Variants = processed.Variants
+			media.ThumbnailURL = processed.ThumbnailURL
+		}
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// processImage generates variants of media and sets them to its record, upload sessions and products referencing it.
+//
+// Record is updated before products, so media attached meanwhile gets variants either from record or by update
+// of products, see AttachImage.
+func (u *UseCase) processImage(ctx context.Context, media *cmentity.Media) error {
+	variants, err := u.generateVariants(ctx, media)
+	if err != nil {
+		return err
+	}
+
+	media.Variants = variants
+	media.ThumbnailURL = variants[0].URL
+
+	recordErr := u.mediaRepo.UpdateVariants(ctx, media)
+	sessionErr := u.uploadSessionRepo.UpdateMediaVariants(ctx, media)
+	_, productErr := u.productRepo.UpdateMediaVariants(ctx, cmentity.MediaTypeImage, media)
+
+	return errors.Join(recordErr, sessionErr, productErr)
+}
+
+// generateVariants decodes image media and stores a resized copy for each configured width and format.
+//
+// Variants are sorted by format then width, so the first one is the smallest of the preferred format.
+// Re-encoding drops all metadata of the original, EXIF orientation is applied to pixels beforehand.
+func (u *UseCase) generateVariants(ctx context.Context, media *cmentity.Media) ([]cmentity.MediaVariant, error) {
+	reader, _, err := u.storage.Get(ctx, media.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	defer reader.Close()
+
+	data, err := io.ReadAll(newLimitedReader(reader, u.cfg.Media.MaxImageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	imgConfig, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(imgConfig.Width)*int64(imgConfig.Height) > u.cfg.Media.Image.MaxPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	orientation := imaging.OrientationNormal
+	if media.ContentType == ContentTypeJPEG {
+		orientation = imaging.JPEGOrientation(data)
+	}
+
+	oriented := imaging.Orient(img, orientation)
+	base := strings.TrimSuffix(media.Key, path.Ext(media.Key))
+
+	var variants []cmentity.MediaVariant
+
+	for _, format := range u.cfg.Media.Image.VariantFormats {
+		for _, width := range variantWidths(u.cfg.Media.Image.VariantWidths, oriented.Bounds().Dx()) {
+			resized := oriented
+			if width != oriented.Bounds().Dx() {
+				resized = imaging.Resize(oriented, width)
+			}
+
+			variant, err := u.storeVariant(ctx, base, format, resized, media.Private)
+			if err != nil {
+				return nil, err
+			}
+
+			variants = append(variants, *variant)
+		}
+	}
+
+	if len(variants) == 0 {
+		return nil, imaging.ErrUnsupportedFormat
+	}
+
+	return variants, nil
+}
+
+func (u *UseCase) storeVariant(
+	ctx context.Context,
+	base, format string,
+	img image.Image,
+	private bool,
+) (*cmentity.MediaVariant, error) {
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, img, format, u.cfg.Media.Image.JPEGQuality); err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	key := fmt.Sprintf("%s_%dw%s", base, bounds.Dx(), imaging.Extension(format))
+
+	_, err := u.storage.Put(
+		ctx,
+		key,
+		&buf,
+		objectstorage.PutOptions{ContentType: imaging.ContentType(format), Public: !private},
+	)
+	// variant was stored by a previous attempt, its content is the same
+	if err != nil && !errors.Is(err, objectstorage.ErrObjectExists) {
+		return nil, err
+	}
+
+	return &cmentity.MediaVariant{
+		URL:         u.storage.URL(key),
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+		ContentType: imaging.ContentType(format),
+		Key:         key,
+	}, nil
+}
+
+// variantWidths returns sorted widths not larger than original width, images are never upscaled.
+//
+// Widths larger than original are replaced by original width.
+func variantWidths(widths []int, originalWidth int) []int {
+	seen := map[int]bool{}
+	res := make([]int, 0, len(widths))
+
+	for _, width := range widths {
+		if width <= 0 {
+			continue
+		}
+
+		if width > originalWidth {
+			width = originalWidth
+		}
+
+		if !seen[width] {
+			seen[width] = true
+			res = append(res, width)
+		}
+	}
+
+	sort.Ints(res)
+
+	return res
+}
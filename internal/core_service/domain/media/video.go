@@ -0,0 +1,86 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	cmentity "github.com/golang/be/internal/common/entity"
+	"github.com/golang/be/pkg/common/tracing"
+	"github.com/golang/be/pkg/core_service/videoprobe"
+)
+
+// videoContainers maps content types of video to their containers.
+var videoContainers = map[string]string{
+	ContentTypeMP4:  videoprobe.ContainerMP4,
+	ContentTypeWebM: videoprobe.ContainerWebM,
+}
+
+// matchesDeclaredType checks whether extension of fileName, if it is known for mediaType, belongs to contentType.
+func matchesDeclaredType(mediaType, fileName, contentType string) bool {
+	ext := strings.ToLower(filepath.Ext(fileName))
+
+	for allowedType, allowedExt := range allowedContentTypes[mediaType] {
+		if ext == allowedExt {
+			return allowedType == contentType
+		}
+	}
+
+	return true
+}
+
+// probeVideo reads metadata of stored video, ErrInvalidContainer is returned if it is not a playable container.
+func (u *UseCase) probeVideo(ctx context.Context, key, contentType string, size int64) (*cmentity.VideoInfo, error) {
+	reader, _, err := u.storage.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	defer reader.Close()
+
+	info, err := videoprobe.Probe(reader, videoContainers[contentType], size)
+	if err != nil {
+		if errors.Is(err, videoprobe.ErrInvalidContainer) || errors.Is(err, videoprobe.ErrNoVideoTrack) ||
+			errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrInvalidContainer
+		}
+
+		return nil, err
+	}
+
+	return &cmentity.VideoInfo{
+		Container:  info.Container,
+		Duration:   info.Duration.Seconds(),
+		Width:      info.Width,
+		Height:     info.Height,
+		Codec:      info.VideoCodec,
+		AudioCodec: info.AudioCodec,
+		Bitrate:    info.Bitrate,
+	}, nil
+}
+
+func (u *UseCase) UploadPoster(
+	ctx context.Context,
+	video *cmentity.Media,
+	fileName string,
+	poster io.Reader,
+) (*cmentity.Media, error) {
+	ctx, span := tracing.Start(ctx, "media.UploadPoster")
+	defer span.End()
+
+	if video == nil || video.Type != cmentity.MediaTypeVideo || video.Key == "" {
+		return nil, ErrMediaNotFound
+	}
+
+	// poster is only referenced by URL, it gets no variants since they could never be attached to product
+	posterMedia, err := u.store(ctx, FileInfo{MediaType: cmentity.MediaTypeImage, FileName: fileName, Private: video.Private}, poster)
+	if err != nil {
+		return nil, err
+	}
+
+	video.ThumbnailURL = posterMedia.URL
+
+	return posterMedia, nil
+}
@@ -0,0 +1,82 @@
+package product
+
+import (
+	"context"
+	"time"
+
+	config "github.com/golang/be/config/core_service"
+	"github.com/golang/be/pkg/common/logger"
+	"go.uber.org/fx"
+)
+
+// ScheduleReindexSearch reindexes search fields of products at start and periodically while server is running.
+//
+// Name, tags and origin of products are written by other services, reindex keeps search of them up to date.
+func ScheduleReindexSearch(cfg *config.Config, useCase UseCaseInterface, lc fx.Lifecycle) {
+	if cfg.Search.ReindexInterval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(
+		fx.Hook{
+			OnStart: func(_ context.Context) error {
+				go func() {
+					defer close(done)
+
+					ticker := time.NewTicker(cfg.Search.ReindexInterval)
+					defer ticker.Stop()
+
+					for {
+						runReindexSearch(ctx, useCase)
+
+						select {
+						case <-ctx.Done():
+							return
+						case <-ticker.C:
+						}
+					}
+				}()
+
+				return nil
+			},
+			OnStop: func(stopCtx context.Context) error {
+				cancel()
+
+				select {
+				case <-done:
+					return nil
+				case <-stopCtx.Done():
+					return stopCtx.Err()
+				}
+			},
+		},
+	)
+}
+
+func runReindexSearch(ctx context.Context, useCase UseCaseInterface) {
+	reindexed, err := useCase.ReindexSearch(ctx)
+	if err != nil {
+		// stopping server cancels reindex, it continues on next start
+		if ctx.Err() != nil {
+			return
+		}
+
+		logger.WithContext(ctx).Errorw(
+			"reindex product search fail",
+			"reindexed", reindexed,
+			"err", err,
+		)
+
+		return
+	}
+
+	if reindexed > 0 {
+		logger.WithContext(ctx).Infow(
+			"reindex product search",
+			"reindexed", reindexed,
+		)
+	}
+}
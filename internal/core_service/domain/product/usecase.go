@@ -2,21 +2,202 @@ package product
 
 import (
 	"context"
+	"errors"
 
+	cmentity "github.com/golang/be/internal/common/entity"
 	"github.com/golang/be/internal/core_service/entity/product"
 	productrepo "github.com/golang/be/internal/core_service/repo/product"
+	"github.com/golang/be/pkg/common/facet"
+	"github.com/golang/be/pkg/common/listquery"
+	"github.com/golang/be/pkg/common/pagination"
+	"github.com/golang/be/pkg/common/textsearch"
+	"github.com/golang/be/pkg/common/tracing"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrNoPermission    = errors.New("no permission to access product")
+	ErrMediaNotFound   = errors.New("product has no such media")
+	ErrProductNotFound = errors.New("product not found")
 )
 
 type UseCaseInterface interface {
-	GetProduct(ctx context.Context, productID *string) (*product.Product, error)
+	// GetProduct returns product if it belongs to organization orgID, nil if there is no such product.
+	GetProduct(ctx context.Context, productID *string, orgID string) (*product.Product, error)
+	// GetProductMedia returns media of product if product belongs to organization orgID.
+	GetProductMedia(ctx context.Context, productID *string, mediaType, orgID string) (*cmentity.Media, error)
+	// SetMedia replaces media of mediaType if product belongs to organization orgID.
+	SetMedia(ctx context.Context, productID *string, mediaType, orgID string, media *cmentity.Media) error
+	// SetMediaThumbnail sets thumbnail of media if product belongs to organization orgID, e.g. poster of video.
+	SetMediaThumbnail(ctx context.Context, productID *string, mediaType, orgID, thumbnailURL string) error
+	// ListProducts returns page of products of organization orgID matching query and facets of all of them,
+	// total of paging is set.
+	ListProducts(
+		ctx context.Context,
+		orgID string,
+		query *listquery.Query,
+		paging *pagination.Pagination,
+	) ([]product.Product, facet.Facets, error)
+	// SearchProducts returns page of products of organization orgID matching query with highlighted fields,
+	// total of paging is set.
+	SearchProducts(
+		ctx context.Context,
+		orgID string,
+		query textsearch.Query,
+		paging *pagination.Pagination,
+	) ([]product.SearchHit, error)
+	// ReindexSearch sets search fields of products written without them or changed since, e.g. by other services
+	// or imports, it returns number of products reindexed.
+	ReindexSearch(ctx context.Context) (int, error)
 }
 
 type UseCase struct {
 	productRepo productrepo.RepoInterface
 }
 
-func (u *UseCase) GetProduct(ctx context.Context, productID *string) (*product.Product, error) {
-	return u.productRepo.FindOneByID(ctx, productID)
+func (u *UseCase) GetProduct(ctx context.Context, productID *string, orgID string) (*product.Product, error) {
+	ctx, span := tracing.Start(ctx, "product.GetProduct")
+	defer span.End()
+
+	curProduct, err := u.productRepo.FindOneByID(ctx, productID)
+	if err != nil || curProduct == nil {
+		return nil, err
+	}
+
+	if orgID == "" || curProduct.OrganizationID.Hex() != orgID {
+		return nil, ErrNoPermission
+	}
+
+	return curProduct, nil
+}
+
+func (u *UseCase) GetProductMedia(
+	ctx context.Context,
+	productID *string,
+	mediaType, orgID string,
+) (*cmentity.Media, error) {
+	ctx, span := tracing.Start(ctx, "product.GetProductMedia")
+	defer span.End()
+
+	curProduct, err := u.productRepo.FindOneByID(ctx, productID)
+	if err != nil || curProduct == nil {
+		return nil, err
+	}
+
+	if orgID == "" || curProduct.OrganizationID.Hex() != orgID {
+		return nil, ErrNoPermission
+	}
+
+	return curProduct.MediaOf(mediaType), nil
+}
+
+func (u *UseCase) SetMedia(
+	ctx context.Context,
+	productID *string,
+	mediaType, orgID string,
+	media *cmentity.Media,
+) error {
+	ctx, span := tracing.Start(ctx, "product.SetMedia")
+	defer span.End()
+
+	curProduct, err := u.productRepo.FindOneByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	if curProduct == nil {
+		return ErrProductNotFound
+	}
+
+	if orgID == "" || curProduct.OrganizationID.Hex() != orgID {
+		return ErrNoPermission
+	}
+
+	return u.productRepo.UpdateMedia(ctx, productID, mediaType, media)
+}
+
+func (u *UseCase) SetMediaThumbnail(
+	ctx context.Context,
+	productID *string,
+	mediaType, orgID, thumbnailURL string,
+) error {
+	ctx, span := tracing.Start(ctx, "product.SetMediaThumbnail")
+	defer span.End()
+
+	media, err := u.GetProductMedia(ctx, productID, mediaType, orgID)
+	if err != nil {
+		return err
+	}
+
+	if media == nil || media.URL == "" {
+		return ErrMediaNotFound
+	}
+
+	return u.productRepo.UpdateMediaThumbnail(ctx, productID, mediaType, thumbnailURL)
+}
+
+func (u *UseCase) ListProducts(
+	ctx context.Context,
+	orgID string,
+	query *listquery.Query,
+	paging *pagination.Pagination,
+) ([]product.Product, facet.Facets, error) {
+	ctx, span := tracing.Start(ctx, "product.ListProducts")
+	defer span.End()
+
+	orgObjectID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return nil, nil, ErrNoPermission
+	}
+
+	result, err := u.productRepo.List(ctx, orgObjectID, query, paging)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paging.Total = int(result.Total)
+
+	return result.Products, result.Facets, nil
+}
+
+func (u *UseCase) SearchProducts(
+	ctx context.Context,
+	orgID string,
+	query textsearch.Query,
+	paging *pagination.Pagination,
+) ([]product.SearchHit, error) {
+	ctx, span := tracing.Start(ctx, "product.SearchProducts")
+	defer span.End()
+
+	orgObjectID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return nil, ErrNoPermission
+	}
+
+	if query.IsEmpty() {
+		return nil, nil
+	}
+
+	products, total, err := u.productRepo.Search(ctx, orgObjectID, query, paging.Page, paging.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	paging.Total = int(total)
+
+	hits := make([]product.SearchHit, len(products))
+	for i := range products {
+		hits[i] = products[i].Highlight(query)
+	}
+
+	return hits, nil
+}
+
+func (u *UseCase) ReindexSearch(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "product.ReindexSearch")
+	defer span.End()
+
+	return u.productRepo.ReindexSearch(ctx)
 }
 
 func NewUseCase(
@@ -0,0 +1,17 @@
+package audit
+
+import (
+	cmentity "github.com/golang/be/internal/common/entity"
+)
+
+// ImpersonationLog records a request an admin made on behalf of another user.
+type ImpersonationLog struct {
+	cmentity.Entity `bson:"inline"`
+	AdminID         string `bson:"admin_id" json:"admin_id"`
+	UserID          string `bson:"user_id" json:"user_id"`
+	Method          string `bson:"method" json:"method"`
+	Path            string `bson:"path" json:"path"`
+	StatusCode      int    `bson:"status_code" json:"status_code"`
+	ClientIP        string `bson:"client_ip" json:"client_ip"`
+	UserAgent       string `bson:"user_agent" json:"user_agent"`
+}
@@ -0,0 +1,31 @@
+package idempotency
+
+import (
+	"time"
+)
+
+// Statuses of idempotent request.
+const (
+	StatusProcessing = "processing"
+	StatusCompleted  = "completed"
+)
+
+// Record define an idempotent request and its response, so retries of request get same response.
+//
+// ID specific idempotency key scoped by client, method and route.
+// Fingerprint specific hash of request, a key can't be reused for different request.
+// Owner specific random token of request acquiring record, only it can complete or release record, so a request
+// whose lock expired can't overwrite record of a retry acquiring key after it.
+// ResponseStatus, ContentType and Body specific stored response, only available when request is completed.
+// ExpiresAt specific when record is removed, a processing record expires when its lock does.
+type Record struct {
+	ID             string    `bson:"_id"`
+	Fingerprint    string    `bson:"fingerprint"`
+	Owner          string    `bson:"owner"`
+	Status         string    `bson:"status"`
+	ResponseStatus int       `bson:"response_status,omitempty"`
+	ContentType    string    `bson:"content_type,omitempty"`
+	Body           []byte    `bson:"body,omitempty"`
+	CreatedAt      time.Time `bson:"created_at"`
+	ExpiresAt      time.Time `bson:"expires_at"`
+}
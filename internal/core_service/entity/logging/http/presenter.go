@@ -0,0 +1,13 @@
+package http
+
+// include response & request struct
+
+// SetLevelReq specific level to be set.
+//
+// Package specific package path whose level is set, level of logger is set if empty.
+// TTL specific duration such as 15m after which level reverts, it is kept until changed if empty.
+type SetLevelReq struct {
+	Level   string `json:"level" binding:"required" example:"debug"`
+	Package string `json:"package" example:"github.com/golang/be/internal/core_service/repo/product"`
+	TTL     string `json:"ttl" example:"15m"`
+}
@@ -0,0 +1,19 @@
+package media
+
+import (
+	"time"
+
+	cmentity "github.com/golang/be/internal/common/entity"
+)
+
+// Record define media stored by upload, it keeps variants generated in background, so they are copied to product
+// when media is attached to it later.
+//
+// ID specific object key of media.
+// ExpiresAt specific when record is removed, media not attached by then is collected as garbage.
+type Record struct {
+	ID        string         `bson:"_id"`
+	Media     cmentity.Media `bson:"media"`
+	CreatedAt time.Time      `bson:"created_at"`
+	ExpiresAt time.Time      `bson:"expires_at"`
+}
@@ -0,0 +1,55 @@
+package product
+
+import (
+	"github.com/golang/be/pkg/common/facet"
+	"github.com/golang/be/pkg/common/listquery"
+)
+
+// Fields products are faceted by.
+const (
+	FacetType   = "type"
+	FacetOrigin = "origin"
+	FacetTags   = "tags"
+	FacetRating = "rating"
+)
+
+// QuerySchema specific fields products can be filtered, sorted and selected by when they are listed.
+//
+// Organization isn't among them, listed products are always of organization of user.
+var QuerySchema = listquery.NewSchema(
+	"-created_at",
+	listquery.Field{Name: "type", Operators: listquery.Equality, Sortable: true, Selectable: true},
+	listquery.Field{Name: "origin", Operators: listquery.Equality, Sortable: true, Selectable: true},
+	listquery.Field{Name: "tags", Operators: listquery.Array, Selectable: true},
+	listquery.Field{Name: "product_name", Operators: listquery.Equality, Sortable: true, Selectable: true},
+	listquery.Field{
+		Name:       "rating_score",
+		Type:       listquery.Number,
+		Operators:  listquery.Comparison,
+		Sortable:   true,
+		Selectable: true,
+	},
+	listquery.Field{
+		Name:       "total_item",
+		Type:       listquery.Int,
+		Operators:  listquery.Comparison,
+		Sortable:   true,
+		Selectable: true,
+	},
+	listquery.Field{Name: "created_at", Type: listquery.Time, Operators: listquery.Comparison, Sortable: true},
+	listquery.Field{Name: "status", Operators: listquery.Equality, Selectable: true},
+	listquery.Field{Name: "url_link", Selectable: true},
+	listquery.Field{Name: "image", Selectable: true},
+	listquery.Field{Name: "video", Selectable: true},
+	listquery.Field{Name: "three_dimension", Selectable: true},
+	listquery.Field{Name: "attribute", Selectable: true},
+)
+
+// ListResult specific page of products matching filter, number of all matching products and their facets.
+//
+// Rating is faceted by buckets of one point, e.g. 4-5, the last bucket includes max score.
+type ListResult struct {
+	Products []Product
+	Total    int64
+	Facets   facet.Facets
+}
@@ -0,0 +1,44 @@
+package product
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/golang/be/pkg/common/listquery"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestQuerySchema(t *testing.T) {
+	t.Run("filters catalog", func(t *testing.T) {
+		values, _ := url.ParseQuery(
+			"filter[type][in]=tea,coffee&filter[tags][all]=organic,gift&filter[rating_score][gte]=3.5" +
+				"&sort=-rating_score&fields=product_name,image",
+		)
+
+		query, err := QuerySchema.Parse(values)
+		assert.NoError(t, err)
+		assert.Equal(
+			t,
+			bson.D{
+				{Key: "rating_score", Value: bson.D{{Key: "$gte", Value: 3.5}}},
+				{Key: "tags", Value: bson.D{{Key: "$all", Value: bson.A{"organic", "gift"}}}},
+				{Key: "type", Value: bson.D{{Key: "$in", Value: bson.A{"tea", "coffee"}}}},
+			},
+			query.Filter,
+		)
+		assert.Equal(t, bson.D{{Key: "rating_score", Value: -1}, {Key: "_id", Value: -1}}, query.Sort)
+		assert.Equal(t, bson.D{{Key: "product_name", Value: 1}, {Key: "image", Value: 1}}, query.Projection)
+	})
+
+	t.Run("hides search and organization fields", func(t *testing.T) {
+		for _, query := range []string{
+			"filter[search.tokens]=a", "fields=search", "sort=tags", "filter[org_id]=64b7f0c2a1e4c3d2b1a09f8e",
+		} {
+			values, _ := url.ParseQuery(query)
+
+			_, err := QuerySchema.Parse(values)
+			assert.ErrorIs(t, err, listquery.ErrUnknownField, query)
+		}
+	})
+}
@@ -1,5 +1,10 @@
 package http
 
+import (
+	cmentity "github.com/golang/be/internal/common/entity"
+	"github.com/golang/be/internal/core_service/entity/product"
+)
+
 // include response & request struct
 
 type CreateProductReq struct {
@@ -7,3 +12,20 @@ type CreateProductReq struct {
 
 type CreateProductResp struct {
 }
+
+// AttachImageReq specific URL of image media uploaded by POST /user/media/image or an upload session.
+type AttachImageReq struct {
+	URL string `json:"url" binding:"required"`
+}
+
+type SignedURLResp struct {
+	URL       string                 `json:"url"`
+	ExpiresAt cmentity.UnixTimestamp `json:"expires_at" swaggertype:"integer"`
+}
+
+// SearchProductResp specific product found by search, highlights keep its fields matching query as HTML
+// with matched words wrapped in em tags.
+type SearchProductResp struct {
+	*product.Product
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
@@ -21,4 +21,19 @@ type Product struct {
 	Tags            []string           `bson:"tags" json:"tags"`
 	AuthorID        primitive.ObjectID `bson:"author_id" json:"author_id"`
 	Attribute       any                `bson:"attribute" json:"attribute"`
+	Search          Search             `bson:"search" json:"-"`
+}
+
+// MediaOf returns media of product by media type, nil if media type is unknown.
+func (p *Product) MediaOf(mediaType string) *cmentity.Media {
+	switch mediaType {
+	case cmentity.MediaTypeImage:
+		return &p.Image
+	case cmentity.MediaTypeVideo:
+		return &p.Video
+	case cmentity.MediaTypeThreeDimension:
+		return &p.ThreeDimension
+	default:
+		return nil
+	}
 }
@@ -0,0 +1,68 @@
+package product
+
+import (
+	"strings"
+
+	"github.com/golang/be/pkg/common/textsearch"
+)
+
+// SearchVersion specific version of normalization of search fields, products of older version are reindexed.
+const SearchVersion = 2
+
+// Search specific normalized fields of product matched by search.
+//
+// Name, Tags and Origin are ranked by text index, Tokens are matched by prefix while user is typing.
+// Source specific fields search was indexed from, products whose fields differ from it are reindexed.
+type Search struct {
+	Name    string       `bson:"name"`
+	Tags    string       `bson:"tags"`
+	Origin  string       `bson:"origin"`
+	Tokens  []string     `bson:"tokens"`
+	Source  SearchSource `bson:"source"`
+	Version int          `bson:"version"`
+}
+
+// SearchSource specific name, tags and origin of product as they were when search fields were set.
+type SearchSource struct {
+	Name   string   `bson:"name"`
+	Tags   []string `bson:"tags"`
+	Origin string   `bson:"origin"`
+}
+
+// SearchHit specific product found by search and its fields with matched words highlighted.
+type SearchHit struct {
+	Product    *Product
+	Highlights map[string]string
+}
+
+// IndexSearch sets search fields from name, tags and origin.
+//
+// This service doesn't write those fields, products written by other services are reindexed periodically,
+// see ScheduleReindexSearch of product domain.
+func (p *Product) IndexSearch() {
+	p.Search = Search{
+		Name:    textsearch.Normalize(p.ProductName),
+		Tags:    textsearch.Normalize(strings.Join(p.Tags, " ")),
+		Origin:  textsearch.Normalize(p.Origin),
+		Tokens:  textsearch.Tokens(append([]string{p.ProductName, p.Origin}, p.Tags...)...),
+		Source:  SearchSource{Name: p.ProductName, Tags: p.Tags, Origin: p.Origin},
+		Version: SearchVersion,
+	}
+}
+
+// Highlight returns search hit of product with highlighted fields matching query.
+func (p *Product) Highlight(query textsearch.Query) SearchHit {
+	hit := SearchHit{Product: p, Highlights: map[string]string{}}
+
+	for field, text := range map[string]string{
+		"product_name": p.ProductName,
+		"tags":         strings.Join(p.Tags, ", "),
+		"origin":       p.Origin,
+	} {
+		if highlighted, ok := textsearch.Highlight(text, query); ok {
+			hit.Highlights[field] = highlighted
+		}
+	}
+
+	return hit
+}
@@ -0,0 +1,10 @@
+package http
+
+// include response & request struct
+
+type CreateSessionReq struct {
+	MediaType string `json:"media_type" binding:"required"`
+	FileName  string `json:"file_name" binding:"required"`
+	Size      int64  `json:"size" binding:"required,gt=0"`
+	Private   bool   `json:"private"`
+}
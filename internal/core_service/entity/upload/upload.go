@@ -0,0 +1,37 @@
+package upload
+
+import (
+	cmentity "github.com/golang/be/internal/common/entity"
+)
+
+// Statuses of resumable upload session.
+const (
+	StatusUploading = "uploading"
+	StatusCompleted = "completed"
+	// StatusFailed specific uploaded file was rejected, e.g. its content type isn't supported, its parts are deleted.
+	StatusFailed = "failed"
+)
+
+// Session define a resumable upload, client sends file in chunks which are kept as parts until the last one arrives.
+//
+// UploadedSize specific offset the next chunk must start at.
+// Media specific the uploaded file, only available when session is completed.
+type Session struct {
+	cmentity.Entity `bson:"inline"`
+	UserID          string                 `bson:"user_id" json:"user_id"`
+	MediaType       string                 `bson:"media_type" json:"media_type"`
+	FileName        string                 `bson:"file_name" json:"file_name"`
+	Size            int64                  `bson:"size" json:"size"`
+	Private         bool                   `bson:"private" json:"private"`
+	UploadedSize    int64                  `bson:"uploaded_size" json:"uploaded_size"`
+	Parts           []Part                 `bson:"parts" json:"-"`
+	Media           *cmentity.Media        `bson:"media,omitempty" json:"media,omitempty"`
+	ExpiresAt       cmentity.UnixTimestamp `bson:"expires_at" json:"expires_at"`
+}
+
+// Part define a chunk stored in object storage.
+type Part struct {
+	Key    string `bson:"key"`
+	Offset int64  `bson:"offset"`
+	Size   int64  `bson:"size"`
+}
@@ -0,0 +1,43 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/golang/be/internal/core_service/entity/audit"
+	"github.com/golang/be/pkg/common/logger"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type RepoInterface interface {
+	CreateImpersonationLog(ctx context.Context, log *audit.ImpersonationLog) error
+}
+
+type MongoRepo struct {
+	db       *mongo.Database
+	collName string
+}
+
+func (r *MongoRepo) CreateImpersonationLog(ctx context.Context, log *audit.ImpersonationLog) error {
+	_, err := r.db.Collection(r.collName).InsertOne(ctx, log)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"insert impersonation log fail",
+			"admin_id", log.AdminID,
+			"user_id", log.UserID,
+			"err", err,
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+func NewMongoRepo(
+	db *mongo.Database,
+) RepoInterface {
+	return &MongoRepo{
+		db:       db,
+		collName: "audit_logs",
+	}
+}
@@ -0,0 +1,161 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/be/internal/core_service/entity/idempotency"
+	"github.com/golang/be/pkg/common/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/fx"
+)
+
+type RepoInterface interface {
+	// Acquire inserts record if its key is free, it returns existing record of key otherwise.
+	Acquire(ctx context.Context, record *idempotency.Record) (*idempotency.Record, error)
+	// Complete stores response of record acquired by owner, so it is replayed until expiresAt.
+	Complete(
+		ctx context.Context,
+		id, owner string,
+		status int,
+		contentType string,
+		body []byte,
+		expiresAt time.Time,
+	) error
+	// Release removes processing record acquired by owner, so request can be retried.
+	Release(ctx context.Context, id, owner string) error
+}
+
+type MongoRepo struct {
+	db       *mongo.Database
+	collName string
+}
+
+func (r *MongoRepo) Acquire(ctx context.Context, record *idempotency.Record) (*idempotency.Record, error) {
+	coll := r.db.Collection(r.collName)
+
+	// expired records are kept until TTL monitor removes them, so they are replaced here
+	_, err := coll.DeleteOne(ctx, bson.M{"_id": record.ID, "expires_at": bson.M{"$lte": time.Now()}})
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"delete expired idempotency record fail",
+			"id", record.ID,
+			"err", err,
+		)
+
+		return nil, err
+	}
+
+	_, err = coll.InsertOne(ctx, record)
+	if err == nil {
+		return nil, nil
+	}
+
+	if !mongo.IsDuplicateKeyError(err) {
+		logger.WithContext(ctx).Errorw(
+			"insert idempotency record fail",
+			"id", record.ID,
+			"err", err,
+		)
+
+		return nil, err
+	}
+
+	var existing idempotency.Record
+	err = coll.FindOne(ctx, bson.M{"_id": record.ID}).Decode(&existing)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			// released in between, client can retry
+			return &idempotency.Record{ID: record.ID, Fingerprint: record.Fingerprint, Status: idempotency.StatusProcessing}, nil
+		}
+
+		logger.WithContext(ctx).Errorw(
+			"find idempotency record fail",
+			"id", record.ID,
+			"err", err,
+		)
+
+		return nil, err
+	}
+
+	return &existing, nil
+}
+
+func (r *MongoRepo) Complete(
+	ctx context.Context,
+	id, owner string,
+	status int,
+	contentType string,
+	body []byte,
+	expiresAt time.Time,
+) error {
+	_, err := r.db.Collection(r.collName).UpdateOne(
+		ctx,
+		bson.M{"_id": id, "owner": owner, "status": idempotency.StatusProcessing},
+		bson.M{"$set": bson.M{
+			"status":          idempotency.StatusCompleted,
+			"response_status": status,
+			"content_type":    contentType,
+			"body":            body,
+			"expires_at":      expiresAt,
+		}},
+	)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"complete idempotency record fail",
+			"id", id,
+			"err", err,
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *MongoRepo) Release(ctx context.Context, id, owner string) error {
+	_, err := r.db.Collection(r.collName).DeleteOne(
+		ctx,
+		bson.M{"_id": id, "owner": owner, "status": idempotency.StatusProcessing},
+	)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"release idempotency record fail",
+			"id", id,
+			"err", err,
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+// ensureIndexes creates TTL index which removes records once they expire.
+func (r *MongoRepo) ensureIndexes(ctx context.Context) error {
+	_, err := r.db.Collection(r.collName).Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	)
+
+	return err
+}
+
+func NewMongoRepo(
+	db *mongo.Database,
+	lc fx.Lifecycle,
+) RepoInterface {
+	repo := &MongoRepo{
+		db:       db,
+		collName: "idempotency_keys",
+	}
+
+	lc.Append(fx.Hook{OnStart: repo.ensureIndexes})
+
+	return repo
+}
@@ -1,10 +1,19 @@
 package repo
 
 import (
+	"github.com/golang/be/internal/core_service/repo/audit"
+	"github.com/golang/be/internal/core_service/repo/idempotency"
+	"github.com/golang/be/internal/core_service/repo/media"
 	"github.com/golang/be/internal/core_service/repo/product"
+	"github.com/golang/be/internal/core_service/repo/upload"
 	"go.uber.org/fx"
 )
 
 var Module = fx.Options(
 	fx.Provide(product.NewMongoRepo),
+	fx.Decorate(product.NewCachedRepo),
+	fx.Provide(audit.NewMongoRepo),
+	fx.Provide(upload.NewMongoRepo),
+	fx.Provide(idempotency.NewMongoRepo),
+	fx.Provide(media.NewMongoRepo),
 )
@@ -0,0 +1,119 @@
+package media
+
+import (
+	"context"
+	"time"
+
+	cmentity "github.com/golang/be/internal/common/entity"
+	"github.com/golang/be/internal/core_service/entity/media"
+	"github.com/golang/be/pkg/common/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/fx"
+)
+
+type RepoInterface interface {
+	Create(ctx context.Context, record *media.Record) error
+	// FindOneByURL returns record of media at url, nil if there is none or it expired.
+	FindOneByURL(ctx context.Context, url string) (*media.Record, error)
+	// UpdateVariants sets thumbnail and variants of record of media by its object key.
+	UpdateVariants(ctx context.Context, media *cmentity.Media) error
+}
+
+type MongoRepo struct {
+	db       *mongo.Database
+	collName string
+}
+
+func (r *MongoRepo) Create(ctx context.Context, record *media.Record) error {
+	_, err := r.db.Collection(r.collName).InsertOne(ctx, record)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"insert media record fail",
+			"key", record.ID,
+			"err", err,
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *MongoRepo) FindOneByURL(ctx context.Context, url string) (*media.Record, error) {
+	var res media.Record
+
+	// expired records are kept until TTL monitor removes them
+	err := r.db.Collection(r.collName).FindOne(
+		ctx,
+		bson.M{"media.url": url, "expires_at": bson.M{"$gt": time.Now()}},
+	).Decode(&res)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+
+		logger.WithContext(ctx).Errorw(
+			"find media record by url fail",
+			"url", url,
+			"err", err,
+		)
+
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (r *MongoRepo) UpdateVariants(ctx context.Context, media *cmentity.Media) error {
+	_, err := r.db.Collection(r.collName).UpdateOne(
+		ctx,
+		bson.M{"_id": media.Key},
+		bson.M{"$set": bson.M{
+			"media.thumbnail_url": media.ThumbnailURL,
+			"media.variants":      media.Variants,
+		}},
+	)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"update media record variants fail",
+			"key", media.Key,
+			"err", err,
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+// ensureIndexes creates index to find records by URL and TTL index which removes records once they expire.
+func (r *MongoRepo) ensureIndexes(ctx context.Context) error {
+	_, err := r.db.Collection(r.collName).Indexes().CreateMany(
+		ctx,
+		[]mongo.IndexModel{
+			{Keys: bson.D{{Key: "media.url", Value: 1}}},
+			{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0),
+			},
+		},
+	)
+
+	return err
+}
+
+func NewMongoRepo(
+	db *mongo.Database,
+	lc fx.Lifecycle,
+) RepoInterface {
+	repo := &MongoRepo{
+		db:       db,
+		collName: "media",
+	}
+
+	lc.Append(fx.Hook{OnStart: repo.ensureIndexes})
+
+	return repo
+}
@@ -0,0 +1,89 @@
+package product
+
+import (
+	"context"
+
+	config "github.com/golang/be/config/core_service"
+	cmentity "github.com/golang/be/internal/common/entity"
+	"github.com/golang/be/internal/core_service/entity/product"
+	"github.com/golang/be/pkg/common/cache"
+	"github.com/golang/be/pkg/common/metrics"
+)
+
+// CachedRepo caches products found by ID, products updated through it are invalidated.
+type CachedRepo struct {
+	RepoInterface
+	products *cache.Aside[product.Product]
+}
+
+func (r *CachedRepo) FindOneByID(ctx context.Context, id *string) (*product.Product, error) {
+	return r.products.Get(
+		ctx, *id, func(ctx context.Context) (*product.Product, error) {
+			return r.RepoInterface.FindOneByID(ctx, id)
+		},
+	)
+}
+
+func (r *CachedRepo) UpdateMedia(ctx context.Context, id *string, mediaType string, media *cmentity.Media) error {
+	err := r.RepoInterface.UpdateMedia(ctx, id, mediaType, media)
+
+	// product may be changed even if update returns error, e.g. on timeout
+	if invalidateErr := r.products.Invalidate(ctx, *id); err == nil {
+		err = invalidateErr
+	}
+
+	return err
+}
+
+func (r *CachedRepo) UpdateMediaThumbnail(ctx context.Context, id *string, mediaType, thumbnailURL string) error {
+	err := r.RepoInterface.UpdateMediaThumbnail(ctx, id, mediaType, thumbnailURL)
+
+	// product may be changed even if update returns error, e.g. on timeout
+	if invalidateErr := r.products.Invalidate(ctx, *id); err == nil {
+		err = invalidateErr
+	}
+
+	return err
+}
+
+func (r *CachedRepo) UpdateMediaVariants(
+	ctx context.Context,
+	mediaType string,
+	media *cmentity.Media,
+) ([]string, error) {
+	ids, err := r.RepoInterface.UpdateMediaVariants(ctx, mediaType, media)
+	if len(ids) == 0 {
+		return ids, err
+	}
+
+	if invalidateErr := r.products.Invalidate(ctx, ids...); err == nil {
+		err = invalidateErr
+	}
+
+	return ids, err
+}
+
+// NewCachedRepo decorates repo, it is decorated only if caching of products is enabled.
+func NewCachedRepo(
+	repo RepoInterface,
+	cfg *config.Config,
+	store cache.Store,
+	metricsRegistry *metrics.Registry,
+) RepoInterface {
+	if cfg.Cache.Product.TTL <= 0 {
+		return repo
+	}
+
+	return &CachedRepo{
+		RepoInterface: repo,
+		products: cache.NewAside[product.Product](
+			store,
+			cache.Options{
+				Name:        "product",
+				TTL:         cfg.Cache.Product.TTL,
+				NegativeTTL: cfg.Cache.Product.NegativeTTL,
+			},
+			metricsRegistry,
+		),
+	}
+}
@@ -0,0 +1,70 @@
+package product
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	config "github.com/golang/be/config/core_service"
+	cmentity "github.com/golang/be/internal/common/entity"
+	"github.com/golang/be/internal/core_service/entity/product"
+	"github.com/golang/be/pkg/common/cache"
+	"github.com/golang/be/pkg/common/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRepo returns product of name, it references media of key "image.png".
+type fakeRepo struct {
+	RepoInterface
+	name  string
+	loads int
+}
+
+func (r *fakeRepo) FindOneByID(_ context.Context, _ *string) (*product.Product, error) {
+	r.loads++
+
+	return &product.Product{ProductName: r.name}, nil
+}
+
+func (r *fakeRepo) UpdateMediaVariants(_ context.Context, _ string, media *cmentity.Media) ([]string, error) {
+	if media.Key != "image.png" {
+		return nil, nil
+	}
+
+	r.name = "updated"
+
+	return []string{"id"}, nil
+}
+
+func TestCachedRepo(t *testing.T) {
+	ctx := context.Background()
+	id := "id"
+
+	cfg := &config.Config{}
+	cfg.Cache.Product.TTL = time.Minute
+
+	inner := &fakeRepo{name: "original"}
+	repo := NewCachedRepo(inner, cfg, cache.NewLRUStore(0, 0), metrics.NewRegistry())
+
+	t.Run("invalidate products of updated media", func(t *testing.T) {
+		found, err := repo.FindOneByID(ctx, &id)
+		assert.NoError(t, err)
+		assert.Equal(t, "original", found.ProductName)
+
+		_, err = repo.UpdateMediaVariants(ctx, cmentity.MediaTypeImage, &cmentity.Media{Key: "other.png"})
+		assert.NoError(t, err)
+
+		_, err = repo.FindOneByID(ctx, &id)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, inner.loads)
+
+		ids, err := repo.UpdateMediaVariants(ctx, cmentity.MediaTypeImage, &cmentity.Media{Key: "image.png"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"id"}, ids)
+
+		found, err = repo.FindOneByID(ctx, &id)
+		assert.NoError(t, err)
+		assert.Equal(t, "updated", found.ProductName)
+		assert.Equal(t, 2, inner.loads)
+	})
+}
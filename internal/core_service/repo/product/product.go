@@ -2,27 +2,76 @@ package product
 
 import (
 	"context"
+	"regexp"
+	"time"
 
+	cmentity "github.com/golang/be/internal/common/entity"
 	"github.com/golang/be/internal/core_service/entity/product"
+	"github.com/golang/be/pkg/common/facet"
+	"github.com/golang/be/pkg/common/listquery"
 	"github.com/golang/be/pkg/common/logger"
+	cmmongo "github.com/golang/be/pkg/common/mongo"
+	"github.com/golang/be/pkg/common/pagination"
+	"github.com/golang/be/pkg/common/textsearch"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.uber.org/fx"
+)
+
+const (
+	// reindexBatchSize specific number of products updated by a write of reindex.
+	reindexBatchSize = 500
+	// maxTagFacets specific number of the most common tags counted in facets.
+	maxTagFacets = 20
+	// maxRatingBucket specific lower bound of the last rating bucket, it includes max score.
+	maxRatingBucket = 4
 )
 
 type RepoInterface interface {
 	FindOneByID(ctx context.Context, id *string) (*product.Product, error)
+	// FindAllMedia returns image, video and 3D media of all products.
+	FindAllMedia(ctx context.Context) ([]cmentity.Media, error)
+	// UpdateMedia replaces media of mediaType in product.
+	UpdateMedia(ctx context.Context, id *string, mediaType string, media *cmentity.Media) error
+	// UpdateMediaThumbnail sets thumbnail URL of media of mediaType in product.
+	UpdateMediaThumbnail(ctx context.Context, id *string, mediaType, thumbnailURL string) error
+	// UpdateMediaVariants sets thumbnail and variants of media of mediaType in all products referencing its object key,
+	// it returns IDs of the products.
+	UpdateMediaVariants(ctx context.Context, mediaType string, media *cmentity.Media) ([]string, error)
+	// List returns page of products of organization orgID matching filter of query, total number of them and facets
+	// counted over all of them.
+	List(
+		ctx context.Context,
+		orgID primitive.ObjectID,
+		query *listquery.Query,
+		paging *pagination.Pagination,
+	) (*product.ListResult, error)
+	// Search returns page of products of organization orgID matching query ranked by relevance and total number
+	// of matching products.
+	Search(
+		ctx context.Context,
+		orgID primitive.ObjectID,
+		query textsearch.Query,
+		page, limit int64,
+	) ([]product.Product, int64, error)
+	// ReindexSearch sets search fields of products written without them, by older normalization
+	// or whose name, tags or origin changed since, it returns number of products reindexed.
+	ReindexSearch(ctx context.Context) (int, error)
 }
 
 type MongoRepo struct {
 	db       *mongo.Database
 	collName string
+	prefs    *cmmongo.ReadPreferences
 }
 
 func (r *MongoRepo) FindOneByID(ctx context.Context, id *string) (*product.Product, error) {
 	objectID, err := primitive.ObjectIDFromHex(*id)
 	if err != nil {
-		logger.Errorw(
+		logger.WithContext(ctx).Errorw(
 			"decode string to objectID err",
 			"id", *id,
 			"err", err,
@@ -38,7 +87,7 @@ func (r *MongoRepo) FindOneByID(ctx context.Context, id *string) (*product.Produ
 			return nil, nil
 		}
 
-		logger.Errorw(
+		logger.WithContext(ctx).Errorw(
 			"find one by id fail",
 			"id", *id,
 			"err", err,
@@ -50,11 +99,512 @@ func (r *MongoRepo) FindOneByID(ctx context.Context, id *string) (*product.Produ
 	return &res, nil
 }
 
+// FindAllMedia reads from primary whatever read preference of client is, garbage collection must see media attached
+// right before it.
+func (r *MongoRepo) FindAllMedia(ctx context.Context) ([]cmentity.Media, error) {
+	coll := r.db.Collection(r.collName, options.Collection().SetReadPreference(readpref.Primary()))
+
+	cursor, err := coll.Find(
+		ctx,
+		bson.M{},
+		options.Find().SetProjection(
+			bson.M{
+				cmentity.MediaTypeImage:          1,
+				cmentity.MediaTypeVideo:          1,
+				cmentity.MediaTypeThreeDimension: 1,
+			},
+		),
+	)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"find product media fail",
+			"err", err,
+		)
+
+		return nil, err
+	}
+
+	defer cursor.Close(ctx)
+
+	var res []cmentity.Media
+
+	for cursor.Next(ctx) {
+		var curProduct product.Product
+		if err = cursor.Decode(&curProduct); err != nil {
+			logger.WithContext(ctx).Errorw(
+				"decode product media fail",
+				"err", err,
+			)
+
+			return nil, err
+		}
+
+		res = append(res, curProduct.Image, curProduct.Video, curProduct.ThreeDimension)
+	}
+
+	if err = cursor.Err(); err != nil {
+		logger.WithContext(ctx).Errorw(
+			"iterate product media fail",
+			"err", err,
+		)
+
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *MongoRepo) UpdateMedia(ctx context.Context, id *string, mediaType string, media *cmentity.Media) error {
+	objectID, err := primitive.ObjectIDFromHex(*id)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"decode string to objectID err",
+			"id", *id,
+			"err", err,
+		)
+
+		return err
+	}
+
+	_, err = r.db.Collection(r.collName).UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.M{"$set": bson.M{mediaType: media, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"update media fail",
+			"id", *id,
+			"media_type", mediaType,
+			"err", err,
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *MongoRepo) UpdateMediaThumbnail(ctx context.Context, id *string, mediaType, thumbnailURL string) error {
+	objectID, err := primitive.ObjectIDFromHex(*id)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"decode string to objectID err",
+			"id", *id,
+			"err", err,
+		)
+
+		return err
+	}
+
+	_, err = r.db.Collection(r.collName).UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.M{"$set": bson.M{mediaType + ".thumbnail_url": thumbnailURL, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"update media thumbnail fail",
+			"id", *id,
+			"media_type", mediaType,
+			"err", err,
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *MongoRepo) UpdateMediaVariants(
+	ctx context.Context,
+	mediaType string,
+	media *cmentity.Media,
+) ([]string, error) {
+	filter := bson.M{mediaType + ".key": media.Key}
+
+	// IDs are found before update, so the products are known even if update fails halfway
+	objectIDs, err := r.db.Collection(r.collName).Distinct(ctx, "_id", filter)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"find products of media fail",
+			"media_type", mediaType,
+			"key", media.Key,
+			"err", err,
+		)
+
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(objectIDs))
+	for _, objectID := range objectIDs {
+		if id, ok := objectID.(primitive.ObjectID); ok {
+			ids = append(ids, id.Hex())
+		}
+	}
+
+	_, err = r.db.Collection(r.collName).UpdateMany(
+		ctx,
+		filter,
+		bson.M{
+			"$set": bson.M{
+				mediaType + ".thumbnail_url": media.ThumbnailURL,
+				mediaType + ".variants":      media.Variants,
+				"updated_at":                 time.Now(),
+			},
+		},
+	)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"update media variants fail",
+			"media_type", mediaType,
+			"key", media.Key,
+			"err", err,
+		)
+
+		return ids, err
+	}
+
+	return ids, nil
+}
+
+// List reads by list read preference, listed pages tolerate slightly stale products.
+func (r *MongoRepo) List(
+	ctx context.Context,
+	orgID primitive.ObjectID,
+	query *listquery.Query,
+	paging *pagination.Pagination,
+) (*product.ListResult, error) {
+	cursor, err := r.db.Collection(r.collName, r.prefs.List()).Aggregate(ctx, listPipeline(orgID, query, paging))
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"list products fail",
+			"err", err,
+		)
+
+		return nil, err
+	}
+
+	var res []struct {
+		Products []product.Product `bson:"products"`
+		Total    []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+		Type   []facet.Count `bson:"type"`
+		Origin []facet.Count `bson:"origin"`
+		Tags   []facet.Count `bson:"tags"`
+		Rating []facet.Count `bson:"rating"`
+	}
+	if err = cursor.All(ctx, &res); err != nil {
+		logger.WithContext(ctx).Errorw(
+			"decode listed products fail",
+			"err", err,
+		)
+
+		return nil, err
+	}
+
+	result := &product.ListResult{Facets: facet.Facets{}}
+	if len(res) == 0 {
+		return result, nil
+	}
+
+	result.Products = res[0].Products
+	if len(res[0].Total) > 0 {
+		result.Total = res[0].Total[0].Count
+	}
+
+	result.Facets[product.FacetType] = res[0].Type
+	result.Facets[product.FacetOrigin] = res[0].Origin
+	result.Facets[product.FacetTags] = res[0].Tags
+	result.Facets[product.FacetRating] = res[0].Rating
+
+	return result, nil
+}
+
+// listPipeline returns aggregation of List, facets are counted in the same $facet as the page,
+// so they count products of organization orgID matching filter of query regardless of paging.
+func listPipeline(orgID primitive.ObjectID, query *listquery.Query, paging *pagination.Pagination) mongo.Pipeline {
+	page := bson.A{
+		bson.M{"$sort": query.Sort},
+		bson.M{"$skip": (paging.Page - 1) * paging.Limit},
+		bson.M{"$limit": paging.Limit},
+	}
+	if len(query.Projection) > 0 {
+		page = append(page, bson.M{"$project": query.Projection})
+	}
+
+	// ratingBucket is lower bound of bucket of one point, max score falls into the last bucket
+	ratingBucket := bson.M{"$toInt": bson.M{"$min": bson.A{bson.M{"$floor": "$rating_score"}, maxRatingBucket}}}
+
+	return mongo.Pipeline{
+		{{Key: "$match", Value: append(bson.D{{Key: "org_id", Value: orgID}}, query.Filter...)}},
+		{{Key: "$facet", Value: bson.M{
+			"products":          page,
+			"total":             bson.A{bson.M{"$count": "count"}},
+			product.FacetType:   countValues("type"),
+			product.FacetOrigin: countValues("origin"),
+			product.FacetTags:   append(countValues("tags", bson.M{"$unwind": "$tags"}), bson.M{"$limit": maxTagFacets}),
+			product.FacetRating: bson.A{
+				bson.M{"$match": bson.M{"rating_score": bson.M{"$type": "number"}}},
+				bson.M{"$group": bson.M{
+					"_id": bson.M{"$let": bson.M{
+						"vars": bson.M{"bucket": ratingBucket},
+						"in": bson.M{"$concat": bson.A{
+							bson.M{"$toString": "$$bucket"}, "-", bson.M{"$toString": bson.M{"$add": bson.A{"$$bucket", 1}}},
+						}},
+					}},
+					"count": bson.M{"$sum": 1},
+				}},
+				bson.M{"$sort": bson.D{{Key: "_id", Value: 1}}},
+			},
+		}}},
+	}
+}
+
+// Search reads by list read preference as List does.
+func (r *MongoRepo) Search(
+	ctx context.Context,
+	orgID primitive.ObjectID,
+	query textsearch.Query,
+	page, limit int64,
+) ([]product.Product, int64, error) {
+	var (
+		filter bson.M
+		opts   = options.Find().SetSkip((page - 1) * limit).SetLimit(limit)
+	)
+
+	if query.Prefix {
+		// words being typed aren't complete, so they can't be matched by text index
+		tokens := make(bson.A, len(query.Terms))
+		for i, term := range query.Terms {
+			tokens[i] = term
+		}
+
+		last := len(tokens) - 1
+		tokens[last] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(query.Terms[last])}
+
+		filter = bson.M{"org_id": orgID, "search.tokens": bson.M{"$all": tokens}}
+		opts.SetSort(bson.D{{Key: "rating_score", Value: -1}, {Key: "_id", Value: 1}})
+	} else {
+		filter = bson.M{"org_id": orgID, "$text": bson.M{"$search": query.Text()}}
+		opts.SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}}).SetSort(
+			bson.D{
+				{Key: "score", Value: bson.M{"$meta": "textScore"}},
+				{Key: "rating_score", Value: -1},
+				{Key: "_id", Value: 1},
+			},
+		)
+	}
+
+	coll := r.db.Collection(r.collName, r.prefs.List())
+
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"count searched products fail",
+			"query", query.Text(),
+			"err", err,
+		)
+
+		return nil, 0, err
+	}
+
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"search products fail",
+			"query", query.Text(),
+			"err", err,
+		)
+
+		return nil, 0, err
+	}
+
+	var res []product.Product
+	if err = cursor.All(ctx, &res); err != nil {
+		logger.WithContext(ctx).Errorw(
+			"decode searched products fail",
+			"query", query.Text(),
+			"err", err,
+		)
+
+		return nil, 0, err
+	}
+
+	return res, total, nil
+}
+
+func (r *MongoRepo) ReindexSearch(ctx context.Context) (int, error) {
+	coll := r.db.Collection(r.collName)
+
+	cursor, err := coll.Find(
+		ctx,
+		bson.M{
+			"$or": bson.A{
+				bson.M{"search.version": bson.M{"$ne": product.SearchVersion}},
+				bson.M{
+					"$expr": bson.M{
+						"$or": bson.A{
+							changed("$search.source.name", "$product_name", ""),
+							changed("$search.source.tags", "$tags", bson.A{}),
+							changed("$search.source.origin", "$origin", ""),
+						},
+					},
+				},
+			},
+		},
+		options.Find().SetProjection(bson.M{"product_name": 1, "tags": 1, "origin": 1}),
+	)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"find products to reindex fail",
+			"err", err,
+		)
+
+		return 0, err
+	}
+
+	defer cursor.Close(ctx)
+
+	var (
+		reindexed int
+		models    []mongo.WriteModel
+	)
+
+	flush := func() error {
+		if len(models) == 0 {
+			return nil
+		}
+
+		_, err := coll.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			logger.WithContext(ctx).Errorw(
+				"reindex products fail",
+				"err", err,
+			)
+
+			return err
+		}
+
+		reindexed += len(models)
+		models = models[:0]
+
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var curProduct product.Product
+		if err = cursor.Decode(&curProduct); err != nil {
+			logger.WithContext(ctx).Errorw(
+				"decode product to reindex fail",
+				"err", err,
+			)
+
+			return reindexed, err
+		}
+
+		curProduct.IndexSearch()
+		models = append(
+			models,
+			mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": curProduct.ID}).
+				SetUpdate(bson.M{"$set": bson.M{"search": curProduct.Search}}),
+		)
+
+		if len(models) == reindexBatchSize {
+			if err = flush(); err != nil {
+				return reindexed, err
+			}
+		}
+	}
+
+	if err = cursor.Err(); err != nil {
+		logger.WithContext(ctx).Errorw(
+			"iterate products to reindex fail",
+			"err", err,
+		)
+
+		return reindexed, err
+	}
+
+	return reindexed, flush()
+}
+
+// countValues returns facet pipeline counting products by value of field from the most common value,
+// products without value are not counted. before specific stages run before counting, e.g. to unwind array field.
+func countValues(field string, before ...bson.M) bson.A {
+	pipeline := bson.A{}
+	for _, stage := range before {
+		pipeline = append(pipeline, stage)
+	}
+
+	return append(
+		pipeline,
+		bson.M{"$match": bson.M{field: bson.M{"$nin": bson.A{nil, ""}}}},
+		bson.M{"$group": bson.M{"_id": "$" + field, "count": bson.M{"$sum": 1}}},
+		bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+	)
+}
+
+// ensureIndexes creates indexes of filters and search, name is weighted over tags and tags over origin in search.
+//
+// Search fields are normalized, so text index doesn't stem them by language.
+func (r *MongoRepo) ensureIndexes(ctx context.Context) error {
+	_, err := r.db.Collection(r.collName).Indexes().CreateMany(
+		ctx,
+		[]mongo.IndexModel{
+			{
+				Keys: bson.D{
+					{Key: "search.name", Value: "text"},
+					{Key: "search.tags", Value: "text"},
+					{Key: "search.origin", Value: "text"},
+				},
+				Options: options.Index().
+					SetName("search_text").
+					SetDefaultLanguage("none").
+					SetWeights(bson.M{"search.name": 10, "search.tags": 5, "search.origin": 2}),
+			},
+			{
+				Keys:    bson.D{{Key: "search.tokens", Value: 1}},
+				Options: options.Index().SetName("search_tokens"),
+			},
+			{Keys: bson.D{{Key: "org_id", Value: 1}}},
+			{Keys: bson.D{{Key: "type", Value: 1}}},
+			{Keys: bson.D{{Key: "origin", Value: 1}}},
+			{Keys: bson.D{{Key: "tags", Value: 1}}},
+		},
+	)
+
+	return err
+}
+
+// changed returns aggregation expression checking whether fields differ, missing and null fields equal empty.
+func changed(field, other string, empty any) bson.M {
+	return bson.M{
+		"$ne": bson.A{
+			bson.M{"$ifNull": bson.A{field, empty}},
+			bson.M{"$ifNull": bson.A{other, empty}},
+		},
+	}
+}
+
 func NewMongoRepo(
 	db *mongo.Database,
+	prefs *cmmongo.ReadPreferences,
+	lc fx.Lifecycle,
 ) RepoInterface {
-	return &MongoRepo{
+	repo := &MongoRepo{
 		db:       db,
 		collName: "products",
+		prefs:    prefs,
 	}
+
+	lc.Append(fx.Hook{OnStart: repo.ensureIndexes})
+
+	return repo
 }
@@ -0,0 +1,78 @@
+package product
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/golang/be/internal/core_service/entity/product"
+	"github.com/golang/be/pkg/common/pagination"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestListPipeline(t *testing.T) {
+	values, _ := url.ParseQuery("filter[type]=tea&filter[rating_score][gte]=4&sort=product_name&fields=product_name")
+
+	query, err := product.QuerySchema.Parse(values)
+	assert.NoError(t, err)
+
+	orgID := primitive.NewObjectID()
+	pipeline := listPipeline(orgID, query, &pagination.Pagination{Page: 3, Limit: 20})
+
+	t.Run("facets count products of organization matching filter", func(t *testing.T) {
+		assert.Len(t, pipeline, 2)
+		assert.Equal(
+			t,
+			bson.E{
+				Key: "$match",
+				Value: bson.D{
+					{Key: "org_id", Value: orgID},
+					{Key: "rating_score", Value: bson.D{{Key: "$gte", Value: 4.0}}},
+					{Key: "type", Value: bson.D{{Key: "$eq", Value: "tea"}}},
+				},
+			},
+			pipeline[0][0],
+		)
+
+		// facets are sub pipelines of the only stage after $match, so they see the same products as the page
+		assert.Equal(t, "$facet", pipeline[1][0].Key)
+
+		facets := pipeline[1][0].Value.(bson.M)
+		for _, name := range []string{product.FacetType, product.FacetOrigin, product.FacetTags, product.FacetRating} {
+			assert.Contains(t, facets, name)
+		}
+	})
+
+	t.Run("page is sorted with tiebreak and projected", func(t *testing.T) {
+		page := pipeline[1][0].Value.(bson.M)["products"]
+
+		assert.Equal(
+			t,
+			bson.A{
+				bson.M{"$sort": bson.D{{Key: "product_name", Value: 1}, {Key: "_id", Value: 1}}},
+				bson.M{"$skip": int64(40)},
+				bson.M{"$limit": int64(20)},
+				bson.M{"$project": bson.D{{Key: "product_name", Value: 1}}},
+			},
+			page,
+		)
+	})
+
+	t.Run("page isn't projected without fields", func(t *testing.T) {
+		query, err := product.QuerySchema.Parse(url.Values{})
+		assert.NoError(t, err)
+
+		page := listPipeline(orgID, query, &pagination.Pagination{Page: 1, Limit: 20})[1][0].Value.(bson.M)["products"]
+
+		assert.Equal(
+			t,
+			bson.A{
+				bson.M{"$sort": bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+				bson.M{"$skip": int64(0)},
+				bson.M{"$limit": int64(20)},
+			},
+			page,
+		)
+	})
+}
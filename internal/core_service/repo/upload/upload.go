@@ -0,0 +1,249 @@
+package upload
+
+import (
+	"context"
+	"time"
+
+	cmentity "github.com/golang/be/internal/common/entity"
+	"github.com/golang/be/internal/core_service/entity/upload"
+	"github.com/golang/be/pkg/common/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/fx"
+)
+
+type RepoInterface interface {
+	Create(ctx context.Context, session *upload.Session) error
+	FindOneByID(ctx context.Context, id string) (*upload.Session, error)
+	// AppendPart adds part to session if session is still at offset of part, it returns false otherwise.
+	AppendPart(ctx context.Context, id string, part upload.Part) (bool, error)
+	Complete(ctx context.Context, id string, media *cmentity.Media) error
+	// Fail marks session failed and removes its parts, so it can't be continued.
+	Fail(ctx context.Context, id string) error
+	// UpdateMediaVariants sets thumbnail and variants of media of sessions completed with its object key.
+	UpdateMediaVariants(ctx context.Context, media *cmentity.Media) error
+	// FindUploadingPartKeys returns object keys of parts of sessions which are not completed.
+	FindUploadingPartKeys(ctx context.Context) ([]string, error)
+}
+
+type MongoRepo struct {
+	db       *mongo.Database
+	collName string
+}
+
+func (r *MongoRepo) Create(ctx context.Context, session *upload.Session) error {
+	_, err := r.db.Collection(r.collName).InsertOne(ctx, session)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"insert upload session fail",
+			"id", session.ID,
+			"err", err,
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *MongoRepo) FindOneByID(ctx context.Context, id string) (*upload.Session, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		// invalid id can't match any session
+		return nil, nil
+	}
+
+	var res upload.Session
+	err = r.db.Collection(r.collName).FindOne(ctx, bson.M{"_id": objectID}).Decode(&res)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+
+		logger.WithContext(ctx).Errorw(
+			"find upload session by id fail",
+			"id", id,
+			"err", err,
+		)
+
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (r *MongoRepo) AppendPart(ctx context.Context, id string, part upload.Part) (bool, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := r.db.Collection(r.collName).UpdateOne(
+		ctx,
+		bson.M{
+			"_id":           objectID,
+			"status":        upload.StatusUploading,
+			"uploaded_size": part.Offset,
+		},
+		bson.M{
+			"$set":  bson.M{"uploaded_size": part.Offset + part.Size, "updated_at": time.Now()},
+			"$push": bson.M{"parts": part},
+		},
+	)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"append part to upload session fail",
+			"id", id,
+			"offset", part.Offset,
+			"err", err,
+		)
+
+		return false, err
+	}
+
+	return res.ModifiedCount == 1, nil
+}
+
+func (r *MongoRepo) Complete(ctx context.Context, id string, media *cmentity.Media) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Collection(r.collName).UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.M{"$set": bson.M{
+			"status":     upload.StatusCompleted,
+			"media":      media,
+			"updated_at": time.Now(),
+		}},
+	)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"complete upload session fail",
+			"id", id,
+			"err", err,
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *MongoRepo) Fail(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Collection(r.collName).UpdateOne(
+		ctx,
+		bson.M{"_id": objectID, "status": upload.StatusUploading},
+		bson.M{"$set": bson.M{
+			"status":     upload.StatusFailed,
+			"parts":      []upload.Part{},
+			"updated_at": time.Now(),
+		}},
+	)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"fail upload session fail",
+			"id", id,
+			"err", err,
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *MongoRepo) UpdateMediaVariants(ctx context.Context, media *cmentity.Media) error {
+	_, err := r.db.Collection(r.collName).UpdateMany(
+		ctx,
+		bson.M{"media.key": media.Key},
+		bson.M{"$set": bson.M{
+			"media.thumbnail_url": media.ThumbnailURL,
+			"media.variants":      media.Variants,
+			"updated_at":          time.Now(),
+		}},
+	)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"update upload session media variants fail",
+			"key", media.Key,
+			"err", err,
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *MongoRepo) FindUploadingPartKeys(ctx context.Context) ([]string, error) {
+	cursor, err := r.db.Collection(r.collName).Find(
+		ctx,
+		bson.M{"status": upload.StatusUploading},
+		options.Find().SetProjection(bson.M{"parts.key": 1}),
+	)
+	if err != nil {
+		logger.WithContext(ctx).Errorw(
+			"find uploading sessions fail",
+			"err", err,
+		)
+
+		return nil, err
+	}
+
+	var sessions []upload.Session
+	if err = cursor.All(ctx, &sessions); err != nil {
+		logger.WithContext(ctx).Errorw(
+			"decode uploading sessions fail",
+			"err", err,
+		)
+
+		return nil, err
+	}
+
+	var keys []string
+
+	for _, session := range sessions {
+		for _, part := range session.Parts {
+			keys = append(keys, part.Key)
+		}
+	}
+
+	return keys, nil
+}
+
+// ensureIndexes creates TTL index which removes sessions once they expire.
+func (r *MongoRepo) ensureIndexes(ctx context.Context) error {
+	_, err := r.db.Collection(r.collName).Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	)
+
+	return err
+}
+
+func NewMongoRepo(
+	db *mongo.Database,
+	lc fx.Lifecycle,
+) RepoInterface {
+	repo := &MongoRepo{
+		db:       db,
+		collName: "upload_sessions",
+	}
+
+	lc.Append(fx.Hook{OnStart: repo.ensureIndexes})
+
+	return repo
+}
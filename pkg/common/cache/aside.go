@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"context"
+	"hash/maphash"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/be/pkg/common/logger"
+	"github.com/golang/be/pkg/common/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/mgocompat"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
+)
+
+// Results of cache lookups in metrics.
+const (
+	resultHit         = "hit"
+	resultNegativeHit = "negative_hit"
+	resultMiss        = "miss"
+)
+
+// generationSlots specific number of invalidation counters, keys share counters by hash so memory is bounded.
+const generationSlots = 1024
+
+// Options specific how values of a kind are cached.
+//
+// Name specific prefix of keys of values, it must be unique in store.
+// TTL specific how long loaded value is kept, zero disables caching.
+// NegativeTTL specific how long not found is kept, zero disables negative caching.
+type Options struct {
+	Name        string
+	TTL         time.Duration
+	NegativeTTL time.Duration
+}
+
+// Aside loads values of type V through store, values are encoded by BSON like in MongoDB.
+//
+// Each caller gets own copy of value, so it can be changed without affecting cache.
+// Invalidation bumps generation of key, a load which started before it returns its value but doesn't store it.
+type Aside[V any] struct {
+	store       Store
+	opts        Options
+	group       singleflight.Group
+	seed        maphash.Seed
+	generations [generationSlots]atomic.Uint64
+	requests    *prometheus.CounterVec
+}
+
+func NewAside[V any](store Store, opts Options, metricsRegistry *metrics.Registry) *Aside[V] {
+	return &Aside[V]{
+		store: store,
+		opts:  opts,
+		seed:  maphash.MakeSeed(),
+		requests: metricsRegistry.Counter(
+			"cache_requests_total",
+			"Number of cache lookups by result: hit, negative_hit or miss.",
+			"cache", "result",
+		),
+	}
+}
+
+// Get returns value of key, value is loaded by load and stored if it isn't cached.
+//
+// Concurrent misses of a key share one load. Nil value returned by load is cached as not found.
+func (a *Aside[V]) Get(ctx context.Context, key string, load func(ctx context.Context) (*V, error)) (*V, error) {
+	if a.opts.TTL <= 0 {
+		return load(ctx)
+	}
+
+	key = a.key(key)
+
+	data, ok, err := a.store.Get(ctx, key)
+	if err != nil {
+		// a broken store must not take reads down
+		logger.WithContext(ctx).Warnw(
+			"get cached value fail",
+			"key", key,
+			"err", err,
+		)
+	}
+
+	if ok {
+		value, err := a.decode(data)
+		if err == nil {
+			if value == nil {
+				a.requests.WithLabelValues(a.opts.Name, resultNegativeHit).Inc()
+			} else {
+				a.requests.WithLabelValues(a.opts.Name, resultHit).Inc()
+			}
+
+			return value, nil
+		}
+	}
+
+	a.requests.WithLabelValues(a.opts.Name, resultMiss).Inc()
+
+	shared := a.group.DoChan(
+		key, func() (any, error) {
+			// load is shared by callers, it must not be cancelled when the first of them is gone
+			loadCtx := trace.ContextWithSpan(logger.CopyFields(context.Background(), ctx), trace.SpanFromContext(ctx))
+			generation := a.generation(key).Load()
+
+			value, err := load(loadCtx)
+			if err != nil {
+				return nil, err
+			}
+
+			return a.set(loadCtx, key, value, generation)
+		},
+	)
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-shared:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+
+		return a.decode(res.Val.([]byte))
+	}
+}
+
+// Invalidate removes values of keys, so they are loaded again on next get.
+func (a *Aside[V]) Invalidate(ctx context.Context, keys ...string) error {
+	fullKeys := make([]string, len(keys))
+	for i, key := range keys {
+		fullKeys[i] = a.key(key)
+		a.generation(fullKeys[i]).Add(1)
+		// callers arriving after invalidation must not get result of load started before it
+		a.group.Forget(fullKeys[i])
+	}
+
+	return a.store.Delete(ctx, fullKeys...)
+}
+
+// set stores encoded value and returns it, empty data means not found.
+//
+// Value isn't kept if key is invalidated after generation, which is read before value was loaded.
+func (a *Aside[V]) set(ctx context.Context, key string, value *V, generation uint64) ([]byte, error) {
+	var (
+		data []byte
+		ttl  = a.opts.NegativeTTL
+	)
+
+	if value != nil {
+		encoded, err := bson.MarshalWithRegistry(mgocompat.Registry, value)
+		if err != nil {
+			return nil, err
+		}
+
+		data, ttl = encoded, a.opts.TTL
+	}
+
+	if ttl <= 0 || a.generation(key).Load() != generation {
+		return data, nil
+	}
+
+	if err := a.store.Set(ctx, key, data, ttl); err != nil {
+		logger.WithContext(ctx).Warnw(
+			"set cached value fail",
+			"key", key,
+			"err", err,
+		)
+	}
+
+	// invalidation may delete key before value is set
+	if a.generation(key).Load() != generation {
+		_ = a.store.Delete(ctx, key)
+	}
+
+	return data, nil
+}
+
+func (a *Aside[V]) decode(data []byte) (*V, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var value V
+	if err := bson.UnmarshalWithRegistry(mgocompat.Registry, data, &value); err != nil {
+		return nil, err
+	}
+
+	return &value, nil
+}
+
+// generation returns invalidation counter of key.
+func (a *Aside[V]) generation(key string) *atomic.Uint64 {
+	return &a.generations[maphash.String(a.seed, key)%generationSlots]
+}
+
+func (a *Aside[V]) key(key string) string {
+	return a.opts.Name + ":" + key
+}
@@ -0,0 +1,17 @@
+// Package cache implements cache-aside loading of values over pluggable stores.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Store keeps encoded values by key, it can be shared by instances of service, e.g. backed by Redis.
+type Store interface {
+	// Get returns value of key, ok is false if key is missing or expired.
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set stores value of key for ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes keys, missing keys are ignored.
+	Delete(ctx context.Context, keys ...string) error
+}
@@ -0,0 +1,295 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/be/pkg/common/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeStore keeps values in a map, like a Redis without expiry.
+type fakeStore struct {
+	mu     sync.Mutex
+	values map[string][]byte
+	ttls   map[string]time.Duration
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{values: map[string][]byte{}, ttls: map[string]time.Duration{}}
+}
+
+func (s *fakeStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.values[key]
+
+	return value, ok, nil
+}
+
+func (s *fakeStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key], s.ttls[key] = value, ttl
+
+	return nil
+}
+
+func (s *fakeStore) Delete(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.values, key)
+	}
+
+	return nil
+}
+
+type item struct {
+	Name string `bson:"name"`
+}
+
+func TestAside(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeStore()
+	registry := metrics.NewRegistry()
+	aside := NewAside[item](store, Options{Name: "item", TTL: time.Minute, NegativeTTL: time.Second}, registry)
+	requests := aside.requests
+
+	var loads int32
+	load := func(value *item) func(context.Context) (*item, error) {
+		return func(context.Context) (*item, error) {
+			atomic.AddInt32(&loads, 1)
+
+			return value, nil
+		}
+	}
+
+	t.Run("loads on miss and returns copies on hit", func(t *testing.T) {
+		value, err := aside.Get(ctx, "a", load(&item{Name: "a"}))
+		assert.NoError(t, err)
+		assert.Equal(t, "a", value.Name)
+		assert.Equal(t, time.Minute, store.ttls["item:a"])
+
+		value.Name = "changed"
+
+		value, err = aside.Get(ctx, "a", load(&item{Name: "b"}))
+		assert.NoError(t, err)
+		assert.Equal(t, "a", value.Name)
+		assert.Equal(t, int32(1), loads)
+		assert.Equal(t, float64(1), testutil.ToFloat64(requests.WithLabelValues("item", resultHit)))
+		assert.Equal(t, float64(1), testutil.ToFloat64(requests.WithLabelValues("item", resultMiss)))
+	})
+
+	t.Run("caches not found", func(t *testing.T) {
+		atomic.StoreInt32(&loads, 0)
+
+		for i := 0; i < 2; i++ {
+			value, err := aside.Get(ctx, "missing", load(nil))
+			assert.NoError(t, err)
+			assert.Nil(t, value)
+		}
+
+		assert.Equal(t, int32(1), loads)
+		assert.Equal(t, time.Second, store.ttls["item:missing"])
+		assert.Equal(t, float64(1), testutil.ToFloat64(requests.WithLabelValues("item", resultNegativeHit)))
+	})
+
+	t.Run("doesn't cache errors", func(t *testing.T) {
+		loadErr := errors.New("load fail")
+
+		_, err := aside.Get(ctx, "err", func(context.Context) (*item, error) { return nil, loadErr })
+		assert.ErrorIs(t, err, loadErr)
+
+		_, ok, _ := store.Get(ctx, "item:err")
+		assert.False(t, ok)
+	})
+
+	t.Run("collapses concurrent misses", func(t *testing.T) {
+		atomic.StoreInt32(&loads, 0)
+		release := make(chan struct{})
+		slowLoad := func(context.Context) (*item, error) {
+			atomic.AddInt32(&loads, 1)
+			<-release
+
+			return &item{Name: "slow"}, nil
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				value, err := aside.Get(ctx, "slow", slowLoad)
+				assert.NoError(t, err)
+				assert.Equal(t, "slow", value.Name)
+			}()
+		}
+
+		// let callers join load before it completes
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), loads)
+	})
+
+	t.Run("invalidates", func(t *testing.T) {
+		assert.NoError(t, aside.Invalidate(ctx, "a"))
+
+		value, err := aside.Get(ctx, "a", load(&item{Name: "b"}))
+		assert.NoError(t, err)
+		assert.Equal(t, "b", value.Name)
+	})
+
+	t.Run("doesn't keep value loaded before invalidation", func(t *testing.T) {
+		loading, release := make(chan struct{}), make(chan struct{})
+		staleLoad := func(context.Context) (*item, error) {
+			close(loading)
+			<-release
+
+			return &item{Name: "stale"}, nil
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+
+			value, err := aside.Get(ctx, "stale", staleLoad)
+			assert.NoError(t, err)
+			assert.Equal(t, "stale", value.Name)
+		}()
+
+		<-loading
+		assert.NoError(t, aside.Invalidate(ctx, "stale"))
+		close(release)
+		<-done
+
+		_, ok, _ := store.Get(ctx, "item:stale")
+		assert.False(t, ok)
+	})
+
+	t.Run("shared load outlives cancelled caller", func(t *testing.T) {
+		loading, release := make(chan struct{}), make(chan struct{})
+		sharedLoad := func(ctx context.Context) (*item, error) {
+			close(loading)
+			<-release
+
+			return &item{Name: "shared"}, ctx.Err()
+		}
+
+		cancelled, cancel := context.WithCancel(ctx)
+		first := make(chan error, 1)
+		go func() {
+			_, err := aside.Get(cancelled, "shared", sharedLoad)
+			first <- err
+		}()
+
+		<-loading
+		cancel()
+		assert.ErrorIs(t, <-first, context.Canceled)
+
+		second := make(chan *item, 1)
+		go func() {
+			value, err := aside.Get(ctx, "shared", sharedLoad)
+			assert.NoError(t, err)
+			second <- value
+		}()
+
+		// let second caller join load before it completes
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		assert.Equal(t, "shared", (<-second).Name)
+	})
+
+	t.Run("loads directly if disabled", func(t *testing.T) {
+		disabled := NewAside[item](store, Options{Name: "disabled"}, registry)
+
+		_, err := disabled.Get(ctx, "a", load(&item{Name: "a"}))
+		assert.NoError(t, err)
+
+		_, ok, _ := store.Get(ctx, "disabled:a")
+		assert.False(t, ok)
+	})
+}
+
+func TestLRUStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newStore := func(maxEntries int, maxBytes int64) *LRUStore {
+		store := NewLRUStore(maxEntries, maxBytes)
+		store.now = func() time.Time { return now }
+
+		return store
+	}
+
+	t.Run("expires values", func(t *testing.T) {
+		store := newStore(0, 0)
+		assert.NoError(t, store.Set(ctx, "a", []byte("1"), time.Second))
+
+		value, ok, _ := store.Get(ctx, "a")
+		assert.True(t, ok)
+		assert.Equal(t, []byte("1"), value)
+
+		now = now.Add(time.Second)
+
+		_, ok, _ = store.Get(ctx, "a")
+		assert.False(t, ok)
+		assert.Equal(t, 0, store.Len())
+	})
+
+	t.Run("evicts least recently used by entries", func(t *testing.T) {
+		store := newStore(2, 0)
+		assert.NoError(t, store.Set(ctx, "a", []byte("1"), time.Minute))
+		assert.NoError(t, store.Set(ctx, "b", []byte("2"), time.Minute))
+		_, _, _ = store.Get(ctx, "a")
+		assert.NoError(t, store.Set(ctx, "c", []byte("3"), time.Minute))
+
+		_, ok, _ := store.Get(ctx, "b")
+		assert.False(t, ok)
+		_, ok, _ = store.Get(ctx, "a")
+		assert.True(t, ok)
+		assert.Equal(t, 2, store.Len())
+	})
+
+	t.Run("evicts by size", func(t *testing.T) {
+		store := newStore(0, 10)
+		assert.NoError(t, store.Set(ctx, "a", []byte("12345"), time.Minute))
+		assert.NoError(t, store.Set(ctx, "b", []byte("12345"), time.Minute))
+
+		_, ok, _ := store.Get(ctx, "a")
+		assert.False(t, ok)
+		_, ok, _ = store.Get(ctx, "b")
+		assert.True(t, ok)
+
+		assert.NoError(t, store.Set(ctx, "c", []byte("too large value"), time.Minute))
+		_, ok, _ = store.Get(ctx, "c")
+		assert.False(t, ok)
+		assert.Equal(t, 1, store.Len())
+	})
+
+	t.Run("replaces and deletes values", func(t *testing.T) {
+		store := newStore(0, 10)
+		assert.NoError(t, store.Set(ctx, "a", []byte("1234"), time.Minute))
+		assert.NoError(t, store.Set(ctx, "a", []byte("12345678"), time.Minute))
+
+		value, ok, _ := store.Get(ctx, "a")
+		assert.True(t, ok)
+		assert.Equal(t, []byte("12345678"), value)
+
+		assert.NoError(t, store.Delete(ctx, "a", "missing"))
+		assert.Equal(t, 0, store.Len())
+		assert.Equal(t, int64(0), store.size)
+	})
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRUStore keeps values in memory of process, least recently used values are evicted first
+// once MaxEntries or MaxBytes is reached, zero means unbounded.
+type LRUStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int64
+	size       int64
+	items      map[string]*list.Element
+	order      *list.List
+	now        func() time.Time
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+func (e *lruEntry) size() int64 {
+	return int64(len(e.key) + len(e.value))
+}
+
+func NewLRUStore(maxEntries int, maxBytes int64) *LRUStore {
+	return &LRUStore{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		items:      map[string]*list.Element{},
+		order:      list.New(),
+		now:        time.Now,
+	}
+}
+
+func (s *LRUStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := element.Value.(*lruEntry)
+	if !s.now().Before(entry.expiresAt) {
+		s.remove(element)
+
+		return nil, false, nil
+	}
+
+	s.order.MoveToFront(element)
+
+	return entry.value, true, nil
+}
+
+func (s *LRUStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.items[key]; ok {
+		s.remove(element)
+	}
+
+	entry := &lruEntry{key: key, value: value, expiresAt: s.now().Add(ttl)}
+
+	// a value larger than whole cache would evict everything
+	if s.maxBytes > 0 && entry.size() > s.maxBytes {
+		return nil
+	}
+
+	s.items[key] = s.order.PushFront(entry)
+	s.size += entry.size()
+
+	for (s.maxEntries > 0 && s.order.Len() > s.maxEntries) || (s.maxBytes > 0 && s.size > s.maxBytes) {
+		s.remove(s.order.Back())
+	}
+
+	return nil
+}
+
+func (s *LRUStore) Delete(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := s.items[key]; ok {
+			s.remove(element)
+		}
+	}
+
+	return nil
+}
+
+// Len returns number of values kept, expired ones included until they are evicted.
+func (s *LRUStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.order.Len()
+}
+
+// remove removes element, it must be called with lock held.
+func (s *LRUStore) remove(element *list.Element) {
+	entry := s.order.Remove(element).(*lruEntry)
+	delete(s.items, entry.key)
+	s.size -= entry.size()
+}
@@ -0,0 +1,11 @@
+// Package facet keeps counts of values of fields among items matching a query, e.g. to refine a catalog.
+package facet
+
+// Count specific number of items having value.
+type Count struct {
+	Value string `json:"value" bson:"_id"`
+	Count int64  `json:"count" bson:"count"`
+}
+
+// Facets specific counts of values by field, counts are ordered from the most common value.
+type Facets map[string][]Count
@@ -0,0 +1,178 @@
+// Package health aggregates readiness checks of service dependencies.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Statuses of checks and reports.
+const (
+	StatusOK       = "ok"
+	StatusFail     = "fail"
+	StatusShutdown = "shutting_down"
+)
+
+// CheckFunc returns error if dependency is not usable, it must return when ctx is done.
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name    string
+	timeout time.Duration
+	fn      CheckFunc
+}
+
+// Registry keeps checks of dependencies, service is ready when all of them pass and it is not shutting down.
+type Registry struct {
+	mu           sync.RWMutex
+	checks       []check
+	shuttingDown atomic.Bool
+}
+
+// CheckResult define result of a check, Duration is in milliseconds.
+type CheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration int64  `json:"duration_ms"`
+}
+
+// Report define readiness of service with result of each check by name.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds check with name, each run of fn is cancelled after timeout.
+func (r *Registry) Register(name string, timeout time.Duration, fn CheckFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks = append(r.checks, check{name: name, timeout: timeout, fn: fn})
+}
+
+// Cached reuses result of fn for ttl, so probes don't call rate limited or billed services each time.
+//
+// Results of runs cancelled by ctx are not kept, they say nothing about the dependency.
+func Cached(ttl time.Duration, fn CheckFunc) CheckFunc {
+	var (
+		mu      sync.Mutex
+		err     error
+		expires time.Time
+	)
+
+	return func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if time.Now().Before(expires) {
+			return err
+		}
+
+		res := fn(ctx)
+		if ctx.Err() != nil {
+			return res
+		}
+
+		err, expires = res, time.Now().Add(ttl)
+
+		return err
+	}
+}
+
+// SetShuttingDown makes service not ready, so load balancers stop sending new requests.
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// Check runs all checks concurrently and returns report.
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := append([]check{}, r.checks...)
+	r.mu.RUnlock()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks))}
+	results := make([]CheckResult, len(checks))
+
+	var wg sync.WaitGroup
+
+	for i := range checks {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			results[i] = run(ctx, checks[i])
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+
+	if r.shuttingDown.Load() {
+		report.Status = StatusShutdown
+	}
+
+	return report
+}
+
+func run(ctx context.Context, c check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+
+	// a check ignoring ctx must not block the report
+	go func() {
+		errCh <- c.fn(ctx)
+	}()
+
+	var err error
+
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{Status: StatusOK, Duration: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// LivenessHandler reports that process is running, it never checks dependencies so outages don't restart pods.
+func LivenessHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": StatusOK})
+}
+
+// ReadinessHandler responds report of registry, status is 503 unless service is ready.
+func (r *Registry) ReadinessHandler(c *gin.Context) {
+	report := r.Check(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, report)
+}
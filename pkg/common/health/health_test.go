@@ -0,0 +1,85 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run(
+		"all checks pass", func(t *testing.T) {
+			registry := NewRegistry()
+			registry.Register("mongo", time.Second, func(context.Context) error { return nil })
+
+			report := registry.Check(ctx)
+			assert.Equal(t, StatusOK, report.Status)
+			assert.Equal(t, StatusOK, report.Checks["mongo"].Status)
+		},
+	)
+
+	t.Run(
+		"failing and slow checks", func(t *testing.T) {
+			registry := NewRegistry()
+			registry.Register("mongo", time.Second, func(context.Context) error { return errors.New("no primary") })
+			registry.Register(
+				"storage", 10*time.Millisecond, func(context.Context) error {
+					// ignores ctx, report must not wait for it
+					time.Sleep(time.Second)
+
+					return nil
+				},
+			)
+
+			start := time.Now()
+			report := registry.Check(ctx)
+
+			assert.Less(t, time.Since(start), 500*time.Millisecond)
+			assert.Equal(t, StatusFail, report.Status)
+			assert.Equal(t, "no primary", report.Checks["mongo"].Error)
+			assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["storage"].Error)
+		},
+	)
+
+	t.Run(
+		"shutting down", func(t *testing.T) {
+			registry := NewRegistry()
+			registry.SetShuttingDown()
+
+			assert.Equal(t, StatusShutdown, registry.Check(ctx).Status)
+		},
+	)
+
+	t.Run(
+		"cached check", func(t *testing.T) {
+			calls := 0
+			fn := Cached(
+				time.Minute, func(context.Context) error {
+					calls++
+
+					return errors.New("unavailable")
+				},
+			)
+
+			registry := NewRegistry()
+			registry.Register("firebase", time.Second, fn)
+
+			assert.Equal(t, StatusFail, registry.Check(ctx).Status)
+			assert.Equal(t, "unavailable", registry.Check(ctx).Checks["firebase"].Error)
+			assert.Equal(t, 1, calls)
+
+			cancelled, cancel := context.WithCancel(ctx)
+			cancel()
+
+			other := Cached(time.Minute, func(ctx context.Context) error { calls++; return ctx.Err() })
+			assert.ErrorIs(t, other(cancelled), context.Canceled)
+			assert.NoError(t, other(ctx))
+			assert.Equal(t, 3, calls)
+		},
+	)
+}
@@ -0,0 +1,113 @@
+package httpresp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/golang/be/pkg/common/jsonlimit"
+)
+
+const jsonLimitsKey = "json_limits"
+
+// SetJSONLimits sets limits JSON body of request is checked against when it is bound.
+func SetJSONLimits(g *gin.Context, limits jsonlimit.Limits) {
+	g.Set(jsonLimitsKey, limits)
+}
+
+// BindJSON decodes JSON body of request to obj and validates it, error is responded if it fails.
+//
+// It returns whether obj is bound, handler must return if it isn't.
+func BindJSON(g *gin.Context, obj any) bool {
+	return bindJSON(g, obj, false)
+}
+
+// BindStrictJSON is BindJSON rejecting fields obj doesn't have, so typos of clients aren't silently ignored.
+func BindStrictJSON(g *gin.Context, obj any) bool {
+	return bindJSON(g, obj, true)
+}
+
+func bindJSON(g *gin.Context, obj any, strict bool) bool {
+	if g.Request.Body == nil {
+		DecodeFail(g, io.EOF.Error())
+
+		return false
+	}
+
+	data, err := io.ReadAll(g.Request.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			BodyTooLarge(g, maxBytesErr.Limit)
+
+			return false
+		}
+
+		DecodeFail(g, err.Error())
+
+		return false
+	}
+
+	value, _ := g.Get(jsonLimitsKey)
+	limits, _ := value.(jsonlimit.Limits)
+
+	if err = jsonlimit.Check(data, limits); err != nil {
+		switch {
+		case errors.Is(err, jsonlimit.ErrTooDeep):
+			Error(g, http.StatusBadRequest, ErrKeyHTTPValidatorsJSONTooDeep.Error(), map[string]any{"max": limits.MaxDepth})
+		case errors.Is(err, jsonlimit.ErrArrayTooLong):
+			Error(
+				g,
+				http.StatusBadRequest,
+				ErrKeyHTTPValidatorsJSONArrayTooLong.Error(),
+				map[string]any{"max": limits.MaxArrayLength},
+			)
+		default:
+			DecodeFail(g, err.Error())
+		}
+
+		return false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+
+	if err = decoder.Decode(obj); err != nil {
+		// encoding/json has no typed error of unknown field
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			Error(
+				g,
+				http.StatusBadRequest,
+				ErrKeyHTTPValidatorsUnknownField.Error(),
+				map[string]any{"field": strings.Trim(field, `"`)},
+			)
+
+			return false
+		}
+
+		DecodeFail(g, err.Error())
+
+		return false
+	}
+
+	if decoder.More() {
+		DecodeFail(g, "unexpected data after JSON value")
+
+		return false
+	}
+
+	if err = binding.Validator.ValidateStruct(obj); err != nil {
+		DecodeFail(g, err.Error())
+
+		return false
+	}
+
+	return true
+}
@@ -5,14 +5,42 @@ import "errors"
 type ErrorKey string
 
 var (
-	ErrKeySystemInternalServer                 = errors.New("error.system.internal")
-	ErrKeyAuthenticationNoPermission           = errors.New("error.authentication.no_permission")
-	ErrKeyAuthenticationInvalidAuthTokenFormat = errors.New("error.authentication.invalid_auth_token_format")
-	ErrKeyAuthenticationInvalidSignature       = errors.New("error.authentication.invalid_signature")
-	ErrKeyHTTPValidatorsMissingRequiredField   = errors.New("error.http_validator.missing_required_field")
-	ErrKeyHTTPValidatorsInvalidFieldType       = errors.New("error.http_validator.invalid_filed_type")
-	ErrKeyHTTPValidatorsDecodeFail             = errors.New("error.http_validator.decode_fail")
-	ErrKeyDatabaseNotFound                     = errors.New("error.database.not_found")
+	ErrKeySystemInternalServer                  = errors.New("error.system.internal")
+	ErrKeySystemTimeout                         = errors.New("error.system.timeout")
+	ErrKeyAuthenticationNoPermission            = errors.New("error.authentication.no_permission")
+	ErrKeyAuthenticationInvalidAuthTokenFormat  = errors.New("error.authentication.invalid_auth_token_format")
+	ErrKeyAuthenticationInvalidSignature        = errors.New("error.authentication.invalid_signature")
+	ErrKeyAuthenticationImpersonationNotAllowed = errors.New("error.authentication.impersonation_not_allowed")
+	ErrKeyAuthenticationImpersonatedNotFound    = errors.New("error.authentication.impersonated_user_not_found")
+	ErrKeyHTTPValidatorsMissingRequiredField    = errors.New("error.http_validator.missing_required_field")
+	ErrKeyHTTPValidatorsInvalidFieldType        = errors.New("error.http_validator.invalid_filed_type")
+	ErrKeyHTTPValidatorsDecodeFail              = errors.New("error.http_validator.decode_fail")
+	ErrKeyHTTPValidatorsBodyTooLarge            = errors.New("error.http_validator.body_too_large")
+	ErrKeyHTTPValidatorsUnknownField            = errors.New("error.http_validator.unknown_field")
+	ErrKeyHTTPValidatorsJSONTooDeep             = errors.New("error.http_validator.json_too_deep")
+	ErrKeyHTTPValidatorsJSONArrayTooLong        = errors.New("error.http_validator.json_array_too_long")
+	ErrKeyDatabaseNotFound                      = errors.New("error.database.not_found")
+	ErrKeyMediaInvalidType                      = errors.New("error.media.invalid_type")
+	ErrKeyMediaUnsupportedContentType           = errors.New("error.media.unsupported_content_type")
+	ErrKeyMediaFileTooLarge                     = errors.New("error.media.file_too_large")
+	ErrKeyMediaUploadOffsetMismatch             = errors.New("error.media.upload_offset_mismatch")
+	ErrKeyMediaUploadCompleted                  = errors.New("error.media.upload_completed")
+	ErrKeyMediaUploadFailed                     = errors.New("error.media.upload_failed")
+	ErrKeyMediaUploadChunkEmpty                 = errors.New("error.media.upload_chunk_empty")
+	ErrKeyMediaUploadChunkTooLarge              = errors.New("error.media.upload_chunk_too_large")
+	ErrKeyMediaInvalidContainer                 = errors.New("error.media.invalid_container")
+	ErrKeyMediaInvalidModel                     = errors.New("error.media.invalid_model")
+	ErrKeyMediaModelExternalReference           = errors.New("error.media.model_external_reference")
+	ErrKeyMediaModelTooComplex                  = errors.New("error.media.model_too_complex")
+	ErrKeyRateLimitExceeded                     = errors.New("error.rate_limit.exceeded")
+	ErrKeyIdempotencyInvalidKey                 = errors.New("error.idempotency.invalid_key")
+	ErrKeyIdempotencyKeyMismatch                = errors.New("error.idempotency.key_mismatch")
+	ErrKeyIdempotencyInProgress                 = errors.New("error.idempotency.in_progress")
+	ErrKeyIdempotencyBodyTooLarge               = errors.New("error.idempotency.body_too_large")
+	ErrKeyQueryUnknownField                     = errors.New("error.query.unknown_field")
+	ErrKeyQueryUnsupportedOperator              = errors.New("error.query.unsupported_operator")
+	ErrKeyQueryInvalidValue                     = errors.New("error.query.invalid_value")
+	ErrKeyQueryTooManyValues                    = errors.New("error.query.too_many_values")
 )
 
 func NewError(key string) error {
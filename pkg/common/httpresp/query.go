@@ -0,0 +1,40 @@
+package httpresp
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/be/pkg/common/listquery"
+)
+
+// ParseQuery parses filter, sort and fields of URL query by schema, error is responded if it fails.
+//
+// It returns nil if query isn't parsed, handler must return then.
+func ParseQuery(g *gin.Context, schema *listquery.Schema) *listquery.Query {
+	query, err := schema.Parse(g.Request.URL.Query())
+	if err == nil {
+		return query
+	}
+
+	var paramErr *listquery.ParamError
+	if !errors.As(err, &paramErr) {
+		DecodeFail(g, err.Error())
+
+		return nil
+	}
+
+	errorKey := ErrKeyQueryInvalidValue
+	switch {
+	case errors.Is(err, listquery.ErrUnknownField):
+		errorKey = ErrKeyQueryUnknownField
+	case errors.Is(err, listquery.ErrUnsupportedOperator):
+		errorKey = ErrKeyQueryUnsupportedOperator
+	case errors.Is(err, listquery.ErrTooManyValues):
+		errorKey = ErrKeyQueryTooManyValues
+	}
+
+	Error(g, http.StatusBadRequest, errorKey.Error(), map[string]any{"param": paramErr.Param})
+
+	return nil
+}
@@ -1,11 +1,15 @@
 package httpresp
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/be/pkg/common/facet"
 	"github.com/golang/be/pkg/common/msgtranslate"
 	"github.com/golang/be/pkg/common/pagination"
+	"github.com/golang/be/pkg/common/tracing"
 	"github.com/golang/be/pkg/common/utils"
 )
 
@@ -13,14 +17,18 @@ import (
 //
 // Data specific all data http request.
 // Pagination specific pagination information when http request get list items.
+// Facets specific counts of values of fields among all items matching filters of list, e.g. to refine it.
 // ErrorKey specific error key if http request have error.
 // Message specific detail error if http request have error, message will be translated to language based on header of
 // http request.
+// TraceID specific trace of http request if it has error, so it can be found in traces and logs.
 type Response struct {
 	Data       any                    `json:"data,omitempty"`
 	Pagination *pagination.Pagination `json:"pagination,omitempty"`
+	Facets     facet.Facets           `json:"facets,omitempty"`
 	ErrorKey   *string                `json:"error_key,omitempty" example:"error.system.internal"`
 	Message    *string                `json:"message,omitempty" example:"Internal System Error"`
+	TraceID    *string                `json:"trace_id,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
 }
 
 // Error returns error for rest api.
@@ -36,11 +44,17 @@ func Error(
 ) {
 	lang := GetLanguageCode(c)
 
+	var traceID *string
+	if id := tracing.TraceID(c.Request.Context()); id != "" {
+		traceID = &id
+	}
+
 	c.AbortWithStatusJSON(
 		status,
 		Response{
 			ErrorKey: &errorKey,
 			Message:  utils.ToPtr(msgtranslate.Translate(errorKey, &lang, msgArgs)),
+			TraceID:  traceID,
 		},
 	)
 }
@@ -58,7 +72,15 @@ func SuccessNoContent(c *gin.Context) {
 }
 
 // InternalServerError returns error result for rest api with internal system error.
+//
+// Error caused by deadline of request is returned as timeout.
 func InternalServerError(g *gin.Context) {
+	if errors.Is(g.Request.Context().Err(), context.DeadlineExceeded) {
+		GatewayTimeoutError(g)
+
+		return
+	}
+
 	Error(
 		g,
 		http.StatusInternalServerError,
@@ -67,6 +89,16 @@ func InternalServerError(g *gin.Context) {
 	)
 }
 
+// GatewayTimeoutError returns error result for rest api when request isn't done before its deadline.
+func GatewayTimeoutError(g *gin.Context) {
+	Error(
+		g,
+		http.StatusGatewayTimeout,
+		ErrKeySystemTimeout.Error(),
+		nil,
+	)
+}
+
 // UnauthorizedError returns error result for rest api with authentication no permission.
 //
 // errorMsg specific reason for error don't have permission.
@@ -81,6 +113,20 @@ func UnauthorizedError(g *gin.Context, errorMsg string) {
 	)
 }
 
+// ForbiddenError returns error result for rest api when authenticated user can't access resource.
+//
+// errorMsg specific reason for error don't have permission.
+func ForbiddenError(g *gin.Context, errorMsg string) {
+	Error(
+		g,
+		http.StatusForbidden,
+		ErrKeyAuthenticationNoPermission.Error(),
+		map[string]any{
+			"msg_err": errorMsg,
+		},
+	)
+}
+
 // MissingRequiredFieldError returns error result for rest api with.
 //
 // field specific field missing.
@@ -121,6 +167,18 @@ func DecodeFail(g *gin.Context, msgErr string) {
 	)
 }
 
+// BodyTooLarge returns error result for rest api when request body exceeds its limit.
+//
+// maxSize specific limit of body in bytes.
+func BodyTooLarge(g *gin.Context, maxSize int64) {
+	Error(
+		g,
+		http.StatusRequestEntityTooLarge,
+		ErrKeyHTTPValidatorsBodyTooLarge.Error(),
+		map[string]any{"max_size": maxSize},
+	)
+}
+
 func NotFound(g *gin.Context) {
 	Error(
 		g,
@@ -0,0 +1,70 @@
+// Package jsonlimit checks structure of JSON documents before they are decoded.
+package jsonlimit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+var (
+	ErrTooDeep      = errors.New("json is nested too deep")
+	ErrArrayTooLong = errors.New("json array is too long")
+)
+
+// Limits specific max nesting depth of objects and arrays and max number of elements of an array,
+// zero means unlimited.
+type Limits struct {
+	MaxDepth       int
+	MaxArrayLength int
+}
+
+// Check walks tokens of data and returns error if it exceeds limits, syntax errors are returned as is.
+func Check(data []byte, limits Limits) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	// number of elements of each open array, -1 for objects
+	var stack []int
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			if len(stack) > 0 {
+				return io.ErrUnexpectedEOF
+			}
+
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// a value inside array counts as its element, keys of objects are tokens too
+		if n := len(stack); n > 0 && stack[n-1] >= 0 && !isClosing(token) {
+			stack[n-1]++
+			if limits.MaxArrayLength > 0 && stack[n-1] > limits.MaxArrayLength {
+				return ErrArrayTooLong
+			}
+		}
+
+		switch token {
+		case json.Delim('['):
+			stack = append(stack, 0)
+		case json.Delim('{'):
+			stack = append(stack, -1)
+		case json.Delim(']'), json.Delim('}'):
+			stack = stack[:len(stack)-1]
+		}
+
+		if limits.MaxDepth > 0 && len(stack) > limits.MaxDepth {
+			return ErrTooDeep
+		}
+	}
+}
+
+func isClosing(token json.Token) bool {
+	return token == json.Delim(']') || token == json.Delim('}')
+}
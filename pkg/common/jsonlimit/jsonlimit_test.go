@@ -0,0 +1,35 @@
+package jsonlimit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	limits := Limits{MaxDepth: 2, MaxArrayLength: 3}
+
+	t.Run("within limits", func(t *testing.T) {
+		assert.NoError(t, Check([]byte(`{"a":[1,2,3],"b":{"c":"d"}}`), limits))
+		assert.NoError(t, Check([]byte(`"scalar"`), limits))
+		assert.NoError(t, Check([]byte(`[[1],[2],[3]]`), limits))
+	})
+
+	t.Run("too deep", func(t *testing.T) {
+		assert.ErrorIs(t, Check([]byte(`{"a":{"b":{"c":1}}}`), limits), ErrTooDeep)
+		assert.ErrorIs(t, Check([]byte(`[[[]]]`), limits), ErrTooDeep)
+	})
+
+	t.Run("array too long", func(t *testing.T) {
+		assert.ErrorIs(t, Check([]byte(`{"a":[1,2,3,4]}`), limits), ErrArrayTooLong)
+		assert.ErrorIs(t, Check([]byte(`[{},{},{},{}]`), limits), ErrArrayTooLong)
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		assert.NoError(t, Check([]byte(`[[[[1,2,3,4,5]]]]`), Limits{}))
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		assert.Error(t, Check([]byte(`{"a":`), limits))
+	})
+}
@@ -0,0 +1,384 @@
+// Package listquery parses filter, sort and sparse fields of list endpoints from URL query,
+// e.g. filter[rating_score][gte]=4&sort=-created_at,product_name&fields=product_name,image.
+//
+// Only fields of schema can be used and values are parsed by type of field, so query can't inject operators.
+package listquery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Names of URL query parameters.
+const (
+	ParamFilter = "filter"
+	ParamSort   = "sort"
+	ParamFields = "fields"
+)
+
+const (
+	// MaxFilters specific max number of filter parameters of query.
+	MaxFilters = 20
+	// MaxValues specific max number of values of in, nin and all operators.
+	MaxValues = 50
+	// MaxSortFields specific max number of fields query is sorted by.
+	MaxSortFields = 3
+)
+
+var (
+	ErrUnknownField        = errors.New("unknown field")
+	ErrUnsupportedOperator = errors.New("unsupported operator")
+	ErrInvalidValue        = errors.New("invalid value")
+	ErrTooManyValues       = errors.New("too many values")
+)
+
+var filterParamRegexp = regexp.MustCompile(`^filter\[([^\[\]]+)\](?:\[([^\[\]]+)\])?$`)
+
+// Operator of filter, a filter without operator is eq.
+type Operator string
+
+const (
+	Eq  Operator = "eq"
+	Ne  Operator = "ne"
+	Gt  Operator = "gt"
+	Gte Operator = "gte"
+	Lt  Operator = "lt"
+	Lte Operator = "lte"
+	In  Operator = "in"
+	Nin Operator = "nin"
+	// All matches array fields having all values.
+	All Operator = "all"
+)
+
+// Operators of common kinds of fields.
+var (
+	Equality   = []Operator{Eq, Ne, In, Nin}
+	Comparison = []Operator{Eq, Ne, Gt, Gte, Lt, Lte}
+	Array      = []Operator{Eq, In, Nin, All}
+)
+
+// Type of field, filter values are parsed by it.
+type Type int
+
+const (
+	String Type = iota
+	Number
+	Int
+	Bool
+	// Time is parsed from RFC 3339, e.g. 2023-01-02T15:04:05Z.
+	Time
+	ObjectID
+)
+
+// Field specific how field of entity can be queried, Name is name of field in both BSON and JSON.
+//
+// Operators specific operators field can be filtered by, field can't be filtered without them.
+type Field struct {
+	Name       string
+	Type       Type
+	Operators  []Operator
+	Sortable   bool
+	Selectable bool
+}
+
+// Schema specific fields of entity which can be queried.
+type Schema struct {
+	fields      map[string]Field
+	defaultSort string
+}
+
+// NewSchema returns schema of fields, defaultSort is sort of queries without sort, e.g. -created_at.
+func NewSchema(defaultSort string, fields ...Field) *Schema {
+	schema := &Schema{fields: map[string]Field{}, defaultSort: defaultSort}
+	for _, field := range fields {
+		schema.fields[field.Name] = field
+	}
+
+	return schema
+}
+
+// ParamError specific parameter of query which is invalid and why.
+type ParamError struct {
+	Param string
+	Err   error
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("query parameter %s: %v", e.Param, e.Err)
+}
+
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// Query specific parsed query.
+//
+// Projection and Fields are empty if all fields are selected.
+// Sort always ends with _id, so pages of equal values are stable.
+type Query struct {
+	Filter     bson.D
+	Sort       bson.D
+	Projection bson.D
+	Fields     []string
+}
+
+// Parse returns query of URL values, other parameters such as page are ignored.
+func (s *Schema) Parse(values url.Values) (*Query, error) {
+	filter, err := s.parseFilter(values)
+	if err != nil {
+		return nil, err
+	}
+
+	sortParam := values.Get(ParamSort)
+	if sortParam == "" {
+		sortParam = s.defaultSort
+	}
+
+	sortFields, err := s.parseSort(sortParam)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &Query{Filter: filter, Sort: sortFields}
+	if err = s.parseFields(values.Get(ParamFields), query); err != nil {
+		return nil, err
+	}
+
+	return query, nil
+}
+
+func (s *Schema) parseFilter(values url.Values) (bson.D, error) {
+	var params []string
+
+	for param := range values {
+		if strings.HasPrefix(param, ParamFilter+"[") {
+			params = append(params, param)
+		}
+	}
+
+	if len(params) > MaxFilters {
+		return nil, &ParamError{Param: ParamFilter, Err: ErrTooManyValues}
+	}
+
+	// parameters are sorted, so equal queries are equal filters
+	sort.Strings(params)
+
+	filter := bson.D{}
+	conditions := map[string]bson.D{}
+
+	for _, param := range params {
+		match := filterParamRegexp.FindStringSubmatch(param)
+		if match == nil {
+			return nil, &ParamError{Param: param, Err: ErrUnknownField}
+		}
+
+		field, ok := s.fields[match[1]]
+		if !ok || len(field.Operators) == 0 {
+			return nil, &ParamError{Param: param, Err: ErrUnknownField}
+		}
+
+		operator := Operator(match[2])
+		if operator == "" {
+			operator = Eq
+		}
+
+		if !field.allows(operator) {
+			return nil, &ParamError{Param: param, Err: ErrUnsupportedOperator}
+		}
+
+		value, err := field.parseValues(operator, values[param])
+		if err != nil {
+			return nil, &ParamError{Param: param, Err: err}
+		}
+
+		if _, ok = conditions[field.Name]; !ok {
+			filter = append(filter, bson.E{Key: field.Name})
+		}
+
+		conditions[field.Name] = append(conditions[field.Name], bson.E{Key: "$" + string(operator), Value: value})
+	}
+
+	for i := range filter {
+		filter[i].Value = conditions[filter[i].Key]
+	}
+
+	return filter, nil
+}
+
+func (s *Schema) parseSort(param string) (bson.D, error) {
+	names := strings.Split(param, ",")
+	if len(names) > MaxSortFields {
+		return nil, &ParamError{Param: ParamSort, Err: ErrTooManyValues}
+	}
+
+	var (
+		sortFields = bson.D{}
+		seen       = map[string]bool{}
+		direction  = 1
+	)
+
+	for _, name := range names {
+		direction = 1
+		if strings.HasPrefix(name, "-") {
+			name, direction = name[1:], -1
+		}
+
+		if name == "" {
+			continue
+		}
+
+		if field, ok := s.fields[name]; !ok || !field.Sortable || seen[name] {
+			return nil, &ParamError{Param: ParamSort, Err: ErrUnknownField}
+		}
+
+		seen[name] = true
+		sortFields = append(sortFields, bson.E{Key: name, Value: direction})
+	}
+
+	return append(sortFields, bson.E{Key: "_id", Value: direction}), nil
+}
+
+func (s *Schema) parseFields(param string, query *Query) error {
+	if param == "" {
+		return nil
+	}
+
+	seen := map[string]bool{}
+
+	for _, name := range strings.Split(param, ",") {
+		if name == "" || seen[name] {
+			continue
+		}
+
+		if field, ok := s.fields[name]; !ok || !field.Selectable {
+			return &ParamError{Param: ParamFields, Err: ErrUnknownField}
+		}
+
+		seen[name] = true
+		query.Fields = append(query.Fields, name)
+		query.Projection = append(query.Projection, bson.E{Key: name, Value: 1})
+	}
+
+	return nil
+}
+
+// Select returns JSON fields of item selected by query and its id, item is returned as is if all fields are selected.
+func (q *Query) Select(item any) (any, error) {
+	if len(q.Fields) == 0 {
+		return item, nil
+	}
+
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	selected := map[string]json.RawMessage{}
+	for _, name := range append([]string{"id"}, q.Fields...) {
+		if value, ok := fields[name]; ok {
+			selected[name] = value
+		}
+	}
+
+	return selected, nil
+}
+
+func (f *Field) allows(operator Operator) bool {
+	for _, allowed := range f.Operators {
+		if allowed == operator {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseValues returns value of operator, in, nin and all take values separated by comma or repeated.
+func (f *Field) parseValues(operator Operator, raw []string) (any, error) {
+	if operator != In && operator != Nin && operator != All {
+		if len(raw) != 1 {
+			return nil, ErrInvalidValue
+		}
+
+		return f.parseValue(raw[0])
+	}
+
+	values := bson.A{}
+
+	for _, param := range raw {
+		for _, value := range strings.Split(param, ",") {
+			if len(values) == MaxValues {
+				return nil, ErrTooManyValues
+			}
+
+			parsed, err := f.parseValue(value)
+			if err != nil {
+				return nil, err
+			}
+
+			values = append(values, parsed)
+		}
+	}
+
+	return values, nil
+}
+
+func (f *Field) parseValue(value string) (any, error) {
+	switch f.Type {
+	case String:
+		return value, nil
+	case Number:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, ErrInvalidValue
+		}
+
+		return number, nil
+	case Int:
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, ErrInvalidValue
+		}
+
+		return number, nil
+	case Bool:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, ErrInvalidValue
+		}
+
+		return boolean, nil
+	case Time:
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, ErrInvalidValue
+		}
+
+		return t, nil
+	case ObjectID:
+		id, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			return nil, ErrInvalidValue
+		}
+
+		return id, nil
+	default:
+		return nil, ErrInvalidValue
+	}
+}
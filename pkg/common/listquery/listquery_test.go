@@ -0,0 +1,114 @@
+package listquery
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestSchemaParse(t *testing.T) {
+	schema := NewSchema(
+		"-created_at",
+		Field{Name: "type", Type: String, Operators: Equality, Sortable: true, Selectable: true},
+		Field{Name: "tags", Type: String, Operators: Array, Selectable: true},
+		Field{Name: "rating_score", Type: Number, Operators: Comparison, Sortable: true},
+		Field{Name: "created_at", Type: Time, Operators: Comparison, Sortable: true},
+		Field{Name: "secret", Type: String},
+	)
+
+	parse := func(query string) (*Query, error) {
+		values, err := url.ParseQuery(query)
+		assert.NoError(t, err)
+
+		return schema.Parse(values)
+	}
+
+	t.Run("parses filter by types of fields", func(t *testing.T) {
+		query, err := parse(
+			"filter[rating_score][gte]=4&filter[rating_score][lt]=4.5&filter[type]=tea" +
+				"&filter[tags][all]=a,b&filter[tags][all]=c&filter[created_at][gt]=2023-01-02T00:00:00Z&page=2",
+		)
+		assert.NoError(t, err)
+		assert.Equal(
+			t,
+			bson.D{
+				{Key: "created_at", Value: bson.D{{Key: "$gt", Value: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)}}},
+				{Key: "rating_score", Value: bson.D{{Key: "$gte", Value: 4.0}, {Key: "$lt", Value: 4.5}}},
+				{Key: "tags", Value: bson.D{{Key: "$all", Value: bson.A{"a", "b", "c"}}}},
+				{Key: "type", Value: bson.D{{Key: "$eq", Value: "tea"}}},
+			},
+			query.Filter,
+		)
+		assert.Equal(t, bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}, query.Sort)
+		assert.Nil(t, query.Projection)
+	})
+
+	t.Run("keeps operators in values as strings", func(t *testing.T) {
+		query, err := parse("filter[type]={\"$ne\":null}")
+		assert.NoError(t, err)
+		assert.Equal(t, bson.D{{Key: "type", Value: bson.D{{Key: "$eq", Value: "{\"$ne\":null}"}}}}, query.Filter)
+	})
+
+	t.Run("parses sort and fields", func(t *testing.T) {
+		query, err := parse("sort=-rating_score,type&fields=type,tags,type")
+		assert.NoError(t, err)
+		assert.Equal(
+			t,
+			bson.D{{Key: "rating_score", Value: -1}, {Key: "type", Value: 1}, {Key: "_id", Value: 1}},
+			query.Sort,
+		)
+		assert.Equal(t, bson.D{{Key: "type", Value: 1}, {Key: "tags", Value: 1}}, query.Projection)
+		assert.Equal(t, []string{"type", "tags"}, query.Fields)
+	})
+
+	t.Run("rejects invalid params", func(t *testing.T) {
+		for query, expected := range map[string]error{
+			"filter[secret]=a":                              ErrUnknownField,
+			"filter[$where]=1":                              ErrUnknownField,
+			"filter[type][$ne]=a":                           ErrUnsupportedOperator,
+			"filter[type][regex]=a":                         ErrUnsupportedOperator,
+			"filter[type][all]=a":                           ErrUnsupportedOperator,
+			"filter[rating_score][gt]=NaN":                  ErrInvalidValue,
+			"filter[rating_score]=1&filter[rating_score]=2": ErrInvalidValue,
+			"filter[created_at][gt]=yesterday":              ErrInvalidValue,
+			"filter[type]x=a":                               ErrUnknownField,
+			"sort=tags":                                     ErrUnknownField,
+			"sort=type,-type":                               ErrUnknownField,
+			"sort=type,rating_score,created_at,-type":       ErrTooManyValues,
+			"fields=rating_score":                           ErrUnknownField,
+		} {
+			_, err := parse(query)
+
+			var paramErr *ParamError
+			assert.ErrorAs(t, err, &paramErr, query)
+			assert.ErrorIs(t, err, expected, query)
+		}
+	})
+}
+
+func TestQuerySelect(t *testing.T) {
+	item := struct {
+		ID    string `json:"id"`
+		Type  string `json:"type"`
+		Price int    `json:"price"`
+	}{ID: "1", Type: "tea", Price: 10}
+
+	selected, err := (&Query{}).Select(item)
+	assert.NoError(t, err)
+	assert.Equal(t, item, selected)
+
+	selected, err = (&Query{Fields: []string{"type"}}).Select(item)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":"1","type":"tea"}`, mustJSON(t, selected))
+}
+
+func mustJSON(t *testing.T, value any) string {
+	data, err := json.Marshal(value)
+	assert.NoError(t, err)
+
+	return string(data)
+}
@@ -0,0 +1,283 @@
+package logger
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LevelState specific current levels of logger.
+//
+// ExpiresAt specific when level reverts to configured level, nil if it doesn't.
+// Packages specific levels overridden for packages, e.g. debug for a single repo.
+type LevelState struct {
+	Level     string         `json:"level"`
+	ExpiresAt *time.Time     `json:"expires_at,omitempty"`
+	Packages  []PackageLevel `json:"packages"`
+}
+
+// PackageLevel specific level of package and its sub packages, it is removed at ExpiresAt if set.
+type PackageLevel struct {
+	Package   string     `json:"package"`
+	Level     string     `json:"level"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// levelOverride is a level changed at runtime, timer reverts it.
+type levelOverride struct {
+	level     zapcore.Level
+	expiresAt *time.Time
+	timer     *time.Timer
+}
+
+// levelRegistry keeps level of logger and levels of packages.
+type levelRegistry struct {
+	mu sync.RWMutex
+	// configured is level logger started with, level reverts to it
+	configured zapcore.Level
+	level      zap.AtomicLevel
+	expiry     *levelOverride
+	packages   map[string]*levelOverride
+	// min is lowest of all levels, entries below it are dropped without looking up their package
+	min atomic.Int32
+}
+
+func newLevelRegistry(level zapcore.Level) *levelRegistry {
+	registry := &levelRegistry{
+		configured: level,
+		level:      zap.NewAtomicLevelAt(level),
+		packages:   map[string]*levelOverride{},
+	}
+	registry.min.Store(int32(level))
+
+	return registry
+}
+
+// levels is level registry of singleton.
+var levels = newLevelRegistry(zapcore.InfoLevel)
+
+// Levels returns current levels of logger.
+func Levels() LevelState {
+	return levels.state()
+}
+
+// SetLevel changes level of logger, it reverts to configured level after ttl if ttl is positive.
+func SetLevel(level zapcore.Level, ttl time.Duration) {
+	levels.setLevel(level, ttl)
+}
+
+// ResetLevel reverts level of logger to configured level.
+func ResetLevel() {
+	levels.setLevel(levels.configured, 0)
+}
+
+// SetPackageLevel changes level of messages logged by pkg and its sub packages, e.g.
+// github.com/golang/be/internal/core_service/repo. Override is removed after ttl if ttl is positive.
+func SetPackageLevel(pkg string, level zapcore.Level, ttl time.Duration) {
+	levels.setPackageLevel(pkg, level, ttl)
+}
+
+// ResetPackageLevel removes level override of pkg.
+func ResetPackageLevel(pkg string) {
+	levels.resetPackageLevel(pkg)
+}
+
+func (r *levelRegistry) state() LevelState {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	state := LevelState{Level: r.level.Level().String(), Packages: []PackageLevel{}}
+	if r.expiry != nil {
+		state.ExpiresAt = r.expiry.expiresAt
+	}
+
+	for pkg, override := range r.packages {
+		state.Packages = append(
+			state.Packages,
+			PackageLevel{Package: pkg, Level: override.level.String(), ExpiresAt: override.expiresAt},
+		)
+	}
+
+	sort.Slice(state.Packages, func(i, j int) bool { return state.Packages[i].Package < state.Packages[j].Package })
+
+	return state
+}
+
+func (r *levelRegistry) setLevel(level zapcore.Level, ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.expiry != nil {
+		r.expiry.timer.Stop()
+		r.expiry = nil
+	}
+
+	r.level.SetLevel(level)
+
+	if ttl > 0 {
+		override := newLevelOverride(level, ttl)
+		override.timer = time.AfterFunc(
+			ttl, func() {
+				r.mu.Lock()
+				defer r.mu.Unlock()
+
+				// level was changed again meanwhile
+				if r.expiry != override {
+					return
+				}
+
+				r.expiry = nil
+				r.level.SetLevel(r.configured)
+				r.updateMin()
+			},
+		)
+		r.expiry = override
+	}
+
+	r.updateMin()
+}
+
+func (r *levelRegistry) setPackageLevel(pkg string, level zapcore.Level, ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if current, ok := r.packages[pkg]; ok && current.timer != nil {
+		current.timer.Stop()
+	}
+
+	override := newLevelOverride(level, ttl)
+	if ttl > 0 {
+		override.timer = time.AfterFunc(
+			ttl, func() {
+				r.mu.Lock()
+				defer r.mu.Unlock()
+
+				if r.packages[pkg] != override {
+					return
+				}
+
+				delete(r.packages, pkg)
+				r.updateMin()
+			},
+		)
+	}
+
+	r.packages[pkg] = override
+	r.updateMin()
+}
+
+func (r *levelRegistry) resetPackageLevel(pkg string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if current, ok := r.packages[pkg]; ok {
+		if current.timer != nil {
+			current.timer.Stop()
+		}
+
+		delete(r.packages, pkg)
+		r.updateMin()
+	}
+}
+
+func newLevelOverride(level zapcore.Level, ttl time.Duration) *levelOverride {
+	override := &levelOverride{level: level}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		override.expiresAt = &expiresAt
+	}
+
+	return override
+}
+
+// updateMin must be called with lock held.
+func (r *levelRegistry) updateMin() {
+	min := r.level.Level()
+	for _, override := range r.packages {
+		if override.level < min {
+			min = override.level
+		}
+	}
+
+	r.min.Store(int32(min))
+}
+
+// enabled returns whether level may be logged by any package.
+func (r *levelRegistry) enabled(level zapcore.Level) bool {
+	return level >= zapcore.Level(r.min.Load())
+}
+
+// levelOf returns level of function, it is level of package of function with longest match or logger level.
+//
+// function is fully qualified, e.g. github.com/golang/be/internal/core_service/repo/product.(*Repo).FindOneByID.
+func (r *levelRegistry) levelOf(function string) zapcore.Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	level := r.level.Level()
+	if len(r.packages) == 0 || function == "" {
+		return level
+	}
+
+	pkg := packageOf(function)
+	matched := ""
+
+	for name, override := range r.packages {
+		if (pkg == name || strings.HasPrefix(pkg, name+"/")) && len(name) > len(matched) {
+			matched = name
+			level = override.level
+		}
+	}
+
+	return level
+}
+
+// packageOf returns package path of fully qualified function name.
+func packageOf(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+
+	return function
+}
+
+// levelCore drops entries below level of package logging them, caller of entries must be added.
+type levelCore struct {
+	zapcore.Core
+	levels *levelRegistry
+}
+
+func newLevelCore(core zapcore.Core, levels *levelRegistry) zapcore.Core {
+	return &levelCore{Core: core, levels: levels}
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return c.levels.enabled(level)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(entry.Level) {
+		return checked
+	}
+
+	return checked.AddCore(entry, c)
+}
+
+// Write writes entry if it is enabled for its package, caller is known only after Check.
+func (c *levelCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if entry.Level < c.levels.levelOf(entry.Caller.Function) {
+		return nil
+	}
+
+	return c.Core.Write(entry, fields)
+}
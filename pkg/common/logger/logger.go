@@ -1,98 +1,157 @@
 package logger
 
 import (
-	"log"
+	"context"
+	"fmt"
 
 	config "github.com/golang/be/config/core_service"
+	"github.com/golang/be/pkg/common/tracing"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-var singleton *zap.Logger
+// singleton is no-op until Init, so packages logging can be used in tests.
+var singleton = zap.NewNop()
+
+// wrapped is singleton used by functions of package, it skips them so callers are reported.
+var wrapped = singleton
 
 // Init initializes a thread-safe singleton logger
 // This would be called from a main method when the application starts up
-// This function would ideally, take zap configuration, but is left out
-// in favor of simplicity using the example logger.
-func Init(cfg *config.Config) *zap.Logger {
-	if cfg.Log.Prod || cfg.Env == config.EnvProd {
-		singleton, _ = zap.NewProduction()
-
-		return singleton
-	}
-
-	// once ensures the singleton is initialized only once
-	zaplv, err := zapcore.ParseLevel(cfg.Log.Level)
+//
+// Level of logger can be changed at runtime by SetLevel and SetPackageLevel.
+// Values of fields named in cfg.Log.RedactFields, tokens and emails are masked.
+func Init(cfg *config.Config) (*zap.Logger, error) {
+	level, err := zapcore.ParseLevel(cfg.Log.Level)
 	if err != nil {
-		log.Fatalf("Cannot init logger")
+		return nil, fmt.Errorf("parse log level: %w", err)
 	}
 
-	zapcfg := zap.Config{
-		Encoding:    "console",
-		Level:       zap.NewAtomicLevelAt(zaplv),
-		OutputPaths: []string{"stderr"},
-
-		EncoderConfig: zapcore.EncoderConfig{
-			MessageKey:    "message",
-			StacktraceKey: "stacktrace",
-			TimeKey:       "time",
-			LevelKey:      "level",
-			CallerKey:     "caller",
-			FunctionKey:   zapcore.OmitKey,
-			EncodeLevel:   zapcore.CapitalLevelEncoder,
-			EncodeCaller:  zapcore.FullCallerEncoder,
-			EncodeTime:    zapcore.RFC3339TimeEncoder,
-		},
+	var zapcfg zap.Config
+
+	if cfg.Log.Prod || cfg.Env == config.EnvProd {
+		zapcfg = zap.NewProductionConfig()
+		// sampling would drop entries while a package is debugged, levels are changed at runtime instead
+		zapcfg.Sampling = nil
+	} else {
+		zapcfg = zap.Config{
+			Encoding:    "console",
+			OutputPaths: []string{"stderr"},
+
+			EncoderConfig: zapcore.EncoderConfig{
+				MessageKey:    "message",
+				StacktraceKey: "stacktrace",
+				TimeKey:       "time",
+				LevelKey:      "level",
+				CallerKey:     "caller",
+				FunctionKey:   zapcore.OmitKey,
+				EncodeLevel:   zapcore.CapitalLevelEncoder,
+				EncodeCaller:  zapcore.FullCallerEncoder,
+				EncodeTime:    zapcore.RFC3339TimeEncoder,
+			},
+		}
 	}
 
-	logger, err := zapcfg.Build()
+	// levels are checked by level core, output accepts all of them
+	zapcfg.Level = zap.NewAtomicLevelAt(zapcore.DebugLevel)
+	levels = newLevelRegistry(level)
+
+	logger, err := zapcfg.Build(
+		zap.WrapCore(
+			func(core zapcore.Core) zapcore.Core {
+				return newLevelCore(newRedactCore(core, cfg.Log.RedactFields), levels)
+			},
+		),
+	)
 	if err != nil {
-		log.Fatalf("Cannot init logger")
+		return nil, fmt.Errorf("build logger: %w", err)
 	}
 
 	singleton = logger
+	wrapped = logger.WithOptions(zap.AddCallerSkip(1))
+
+	return singleton, nil
+}
 
-	return singleton
+// Log logs a message at level with the given fields.
+func Log(level zapcore.Level, message string, fields ...zap.Field) {
+	wrapped.Log(level, message, fields...)
 }
 
 // Debug logs a debug message with the given fields.
 func Debug(message string, fields ...zap.Field) {
-	singleton.Debug(message, fields...)
+	wrapped.Debug(message, fields...)
 }
 
 // Info logs a debug message with the given fields.
 func Info(message string, fields ...zap.Field) {
-	singleton.Info(message, fields...)
+	wrapped.Info(message, fields...)
 }
 
 // Info logs a debug message with the given fields.
 func Infow(message string, keysAndValues ...any) {
-	singleton.Sugar().Infow(message, keysAndValues...)
+	wrapped.Sugar().Infow(message, keysAndValues...)
 }
 
 // Warn logs a debug message with the given fields.
 func Warn(message string, fields ...zap.Field) {
-	singleton.Warn(message, fields...)
+	wrapped.Warn(message, fields...)
 }
 
 // Warnw logs a debug message with the given fields.
 func Warnw(message string, keysAndValues ...any) {
-	singleton.Sugar().Warnw(message, keysAndValues...)
+	wrapped.Sugar().Warnw(message, keysAndValues...)
 }
 
 // Error logs a debug message with the given fields.
 func Error(message string, fields ...zap.Field) {
-	singleton.Error(message, fields...)
+	wrapped.Error(message, fields...)
 }
 
 // Errorw logs a debug message with the given key-value pairs.
 func Errorw(message string, keysAndValues ...any) {
-	singleton.Sugar().Errorw(message, keysAndValues...)
+	wrapped.Sugar().Errorw(message, keysAndValues...)
 }
 
 // Fatal logs a message.
 func Fatal(message string, keysAndValues ...any) {
-	singleton.Sugar().Fatalw(message, keysAndValues...)
+	wrapped.Sugar().Fatalw(message, keysAndValues...)
+}
+
+// fieldsKey is context key of fields logged with messages of context.
+type fieldsKey struct{}
+
+// ContextWithFields returns ctx whose logs carry keysAndValues in addition to fields ctx already carries,
+// e.g. request ID set by middleware is logged by repos called with ctx.
+func ContextWithFields(ctx context.Context, keysAndValues ...any) context.Context {
+	fields := append(append([]any{}, Fields(ctx)...), keysAndValues...)
+
+	return context.WithValue(ctx, fieldsKey{}, fields)
+}
+
+// Fields returns fields carried by ctx.
+func Fields(ctx context.Context) []any {
+	fields, _ := ctx.Value(fieldsKey{}).([]any)
+
+	return fields
+}
+
+// CopyFields returns dst carrying fields of src too, it relates logs of background job to request starting it
+// without job being cancelled with request.
+func CopyFields(dst, src context.Context) context.Context {
+	return ContextWithFields(dst, Fields(src)...)
+}
+
+// WithContext returns logger adding fields of ctx and its trace and span to messages,
+// so they can be related to request and found from traces.
+func WithContext(ctx context.Context) *zap.SugaredLogger {
+	sugar := singleton.Sugar().With(Fields(ctx)...)
+
+	if traceID := tracing.TraceID(ctx); traceID != "" {
+		sugar = sugar.With("trace_id", traceID, "span_id", tracing.SpanID(ctx))
+	}
+
+	return sugar
 }
 
 func ErrWrap(err error) zap.Field {
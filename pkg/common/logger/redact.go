@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const redacted = "[REDACTED]"
+
+// defaultRedactFields are names of fields whose values are always masked, configured names are added to them.
+var defaultRedactFields = []string{
+	"password", "secret", "token", "access_token", "refresh_token", "id_token", "authorization", "cookie", "api_key",
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@([A-Za-z0-9-]+\.)+[A-Za-z]{2,}`)
+	// tokenPattern matches bearer credentials and JWTs such as Firebase ID tokens
+	tokenPattern = regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._~+/-]+=*|eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+)
+
+// redactCore masks values of sensitive fields, tokens and emails before entries are written.
+type redactCore struct {
+	zapcore.Core
+	fields map[string]bool
+}
+
+func newRedactCore(core zapcore.Core, fields []string) zapcore.Core {
+	names := make(map[string]bool, len(defaultRedactFields)+len(fields))
+	for _, name := range append(append([]string{}, defaultRedactFields...), fields...) {
+		names[strings.ToLower(name)] = true
+	}
+
+	return &redactCore{Core: core, fields: names}
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redactFields(fields)), fields: c.fields}
+}
+
+func (c *redactCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+
+	return checked
+}
+
+func (c *redactCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = redactString(entry.Message)
+
+	return c.Core.Write(entry, c.redactFields(fields))
+}
+
+func (c *redactCore) redactFields(fields []zapcore.Field) []zapcore.Field {
+	redactedFields := make([]zapcore.Field, len(fields))
+
+	for i, field := range fields {
+		redactedFields[i] = c.redactField(field)
+	}
+
+	return redactedFields
+}
+
+func (c *redactCore) redactField(field zapcore.Field) zapcore.Field {
+	if c.fields[strings.ToLower(field.Key)] {
+		return zap.String(field.Key, redacted)
+	}
+
+	switch field.Type {
+	case zapcore.StringType:
+		field.String = redactString(field.String)
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok {
+			if msg := redactString(err.Error()); msg != err.Error() {
+				return zap.String(field.Key, msg)
+			}
+		}
+	}
+
+	return field
+}
+
+// redactString masks tokens and local part of emails in s, domain of emails is kept to help debugging.
+func redactString(s string) string {
+	if !strings.Contains(s, "@") && !strings.Contains(s, "eyJ") && !strings.Contains(strings.ToLower(s), "bearer") {
+		return s
+	}
+
+	s = tokenPattern.ReplaceAllString(s, redacted)
+
+	return emailPattern.ReplaceAllStringFunc(
+		s, func(email string) string {
+			return "***" + email[strings.LastIndex(email, "@"):]
+		},
+	)
+}
@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute is route label of requests no route matches, so unknown paths don't create new series.
+const unmatchedRoute = "unmatched"
+
+// Middleware returns gin middleware counting requests and observing their latency,
+// requests are labeled by route template instead of path, e.g. /products/:productId.
+// It must be registered before gin.Recovery, otherwise panicking requests are not recorded.
+func (r *Registry) Middleware() gin.HandlerFunc {
+	requests := r.Counter(
+		"http_requests_total", "Number of HTTP requests.",
+		"route", "method", "status",
+	)
+	duration := r.Histogram(
+		"http_request_duration_seconds", "Latency of HTTP requests.", nil,
+		"route", "method", "status",
+	)
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		status := strconv.Itoa(c.Writer.Status())
+
+		requests.WithLabelValues(route, c.Request.Method, status).Inc()
+		duration.WithLabelValues(route, c.Request.Method, status).Observe(time.Since(start).Seconds())
+	}
+}
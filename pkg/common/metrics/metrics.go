@@ -0,0 +1,69 @@
+// Package metrics exposes Prometheus metrics of http server, MongoDB and use cases.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry keeps collectors exposed by Handler, use cases register their own counters by Counter.
+type Registry struct {
+	registry *prometheus.Registry
+}
+
+// NewRegistry returns registry with Go runtime and process collectors.
+func NewRegistry() *Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return &Registry{registry: registry}
+}
+
+// Handler returns http handler serving metrics in Prometheus exposition format.
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{Registry: r.registry})
+}
+
+// Counter registers counter partitioned by labels, counter registered before by same name is returned.
+//
+// name should follow Prometheus naming, e.g. media_uploads_total.
+func (r *Registry) Counter(name, help string, labels ...string) *prometheus.CounterVec {
+	return register(r, prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labels))
+}
+
+// Histogram registers histogram partitioned by labels, histogram registered before by same name is returned.
+//
+// buckets are prometheus.DefBuckets if empty.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *prometheus.HistogramVec {
+	return register(
+		r, prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: help, Buckets: buckets}, labels),
+	)
+}
+
+// Gauge registers gauge partitioned by labels, gauge registered before by same name is returned.
+func (r *Registry) Gauge(name, help string, labels ...string) *prometheus.GaugeVec {
+	return register(r, prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, labels))
+}
+
+// register registers collector, it panics if another collector with same name but different labels exists.
+func register[T prometheus.Collector](r *Registry, collector T) T {
+	if err := r.registry.Register(collector); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+		if errors.As(err, &registered) {
+			if existing, ok := registered.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+
+		panic(err)
+	}
+
+	return collector
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run(
+		"counter registered twice", func(t *testing.T) {
+			registry := NewRegistry()
+
+			first := registry.Counter("uploads_total", "Uploads.", "type")
+			second := registry.Counter("uploads_total", "Uploads.", "type")
+			second.WithLabelValues("image").Inc()
+
+			assert.Equal(t, 1.0, testutil.ToFloat64(first.WithLabelValues("image")))
+		},
+	)
+
+	t.Run(
+		"requests labeled by route template", func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			registry := NewRegistry()
+			engine := gin.New()
+			engine.Use(registry.Middleware(), gin.RecoveryWithWriter(io.Discard))
+			engine.GET("/products/:productId", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+			engine.GET("/panic", func(c *gin.Context) { panic("boom") })
+			engine.GET("/metrics", gin.WrapH(registry.Handler()))
+
+			for _, path := range []string{"/products/1", "/products/2", "/unknown", "/panic"} {
+				engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+			}
+
+			recorder := httptest.NewRecorder()
+			engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			body := recorder.Body.String()
+
+			assert.Contains(t, body, `http_requests_total{method="GET",route="/products/:productId",status="204"} 2`)
+			assert.Contains(t, body, `http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+			assert.Contains(t, body, `http_requests_total{method="GET",route="/panic",status="500"} 1`)
+			assert.False(t, strings.Contains(body, "/products/1"))
+		},
+	)
+}
@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// MongoMonitor observes commands and connection pool of MongoDB client.
+type MongoMonitor struct {
+	duration       *prometheus.HistogramVec
+	errors         *prometheus.CounterVec
+	open           *prometheus.GaugeVec
+	inUse          *prometheus.GaugeVec
+	checkoutFailed *prometheus.CounterVec
+	poolCleared    *prometheus.CounterVec
+
+	// collections keeps collection of started commands by request ID until they finish
+	collections sync.Map
+}
+
+// NewMongoMonitor returns monitor registering MongoDB metrics in registry.
+func (r *Registry) NewMongoMonitor() *MongoMonitor {
+	return &MongoMonitor{
+		duration: r.Histogram(
+			"mongo_command_duration_seconds", "Latency of MongoDB commands.", nil,
+			"command", "collection",
+		),
+		errors: r.Counter(
+			"mongo_command_errors_total", "Number of failed MongoDB commands.",
+			"command", "collection",
+		),
+		open: r.Gauge(
+			"mongo_pool_connections", "Number of open connections of MongoDB connection pool.",
+			"address",
+		),
+		inUse: r.Gauge(
+			"mongo_pool_connections_in_use", "Number of connections checked out from MongoDB connection pool.",
+			"address",
+		),
+		checkoutFailed: r.Counter(
+			"mongo_pool_checkout_failures_total", "Number of failed checkouts from MongoDB connection pool.",
+			"address", "reason",
+		),
+		poolCleared: r.Counter(
+			"mongo_pool_cleared_total", "Number of times MongoDB connection pool was cleared.",
+			"address",
+		),
+	}
+}
+
+// CommandMonitor returns monitor to be set by options.Client().SetMonitor.
+func (m *MongoMonitor) CommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			m.collections.Store(e.RequestID, collectionName(e))
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			m.duration.WithLabelValues(e.CommandName, m.collection(e.RequestID)).Observe(e.Duration.Seconds())
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			collection := m.collection(e.RequestID)
+			m.duration.WithLabelValues(e.CommandName, collection).Observe(e.Duration.Seconds())
+			m.errors.WithLabelValues(e.CommandName, collection).Inc()
+		},
+	}
+}
+
+// PoolMonitor returns monitor to be set by options.Client().SetPoolMonitor.
+func (m *MongoMonitor) PoolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch e.Type {
+			case event.ConnectionCreated:
+				m.open.WithLabelValues(e.Address).Inc()
+			case event.ConnectionClosed:
+				m.open.WithLabelValues(e.Address).Dec()
+			case event.GetSucceeded:
+				m.inUse.WithLabelValues(e.Address).Inc()
+			case event.ConnectionReturned:
+				m.inUse.WithLabelValues(e.Address).Dec()
+			case event.GetFailed:
+				m.checkoutFailed.WithLabelValues(e.Address, e.Reason).Inc()
+			case event.PoolCleared:
+				m.poolCleared.WithLabelValues(e.Address).Inc()
+			}
+		},
+	}
+}
+
+// collection returns and forgets collection of started command.
+func (m *MongoMonitor) collection(requestID int64) string {
+	name, ok := m.collections.LoadAndDelete(requestID)
+	if !ok {
+		return ""
+	}
+
+	return name.(string)
+}
+
+// collectionName returns collection command runs on, it is value of first element of command by convention,
+// e.g. {find: "products"}, except getMore keeping it in collection field.
+// Commands not on collection like ping get empty name.
+func collectionName(e *event.CommandStartedEvent) string {
+	if e.CommandName == "getMore" {
+		name, _ := e.Command.Lookup("collection").StringValueOK()
+
+		return name
+	}
+
+	element, err := e.Command.IndexErr(0)
+	if err != nil {
+		return ""
+	}
+
+	name, _ := element.Value().StringValueOK()
+
+	return name
+}
@@ -3,45 +3,81 @@ package mongo
 
 import (
 	"context"
+	"time"
 
 	config "github.com/golang/be/config/common"
 	cmentity "github.com/golang/be/internal/common/entity"
 	"github.com/golang/be/pkg/common/logger"
+	"github.com/golang/be/pkg/common/metrics"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/mgocompat"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 )
 
 // NewMongoDatabase returns mongo database.
 //
 // cfg specific for service config.
-// lc fx lifecycle to handle disconnect database when service down.
-func New(cfg *config.Config, lc fx.Lifecycle) (*mongo.Database, error) {
+// metricsRegistry keeps duration of commands and connection pool stats.
+// tracerProvider traces commands as children of span in their context.
+// lc fx lifecycle to ping database when service starts and disconnect it when service down.
+func New(
+	cfg *config.Config,
+	metricsRegistry *metrics.Registry,
+	tracerProvider trace.TracerProvider,
+	lc fx.Lifecycle,
+) (*mongo.Database, error) {
 	ctx := context.Background()
+	monitor := metricsRegistry.NewMongoMonitor()
+
+	configOptions, err := clientOptions(cfg.Mongo)
+	if err != nil {
+		return nil, err
+	}
 
 	client, err := mongo.Connect(
 		ctx,
 		options.Client().SetRegistry(mgocompat.Registry),
 		options.Client().ApplyURI(cfg.Mongo.ConnURI),
+		configOptions,
+		options.Client().SetMonitor(
+			chainCommandMonitors(
+				monitor.CommandMonitor(),
+				// commands are not recorded, they may contain personal data
+				otelmongo.NewMonitor(
+					otelmongo.WithTracerProvider(tracerProvider),
+					otelmongo.WithCommandAttributeDisabled(true),
+				),
+			),
+		),
+		options.Client().SetPoolMonitor(monitor.PoolMonitor()),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := client.Ping(ctx, readpref.Primary()); err != nil {
-		return nil, err
-	}
-
-	logger.Info("Connect to MongoDB successfully")
-
 	db := client.Database(cfg.Mongo.DBName)
 
 	lc.Append(
 		fx.Hook{
+			// ping waits for database within start timeout of app, hooks of repositories run after it
+			OnStart: func(ctx context.Context) error {
+				if err := pingWithRetry(ctx, client, cfg.Mongo); err != nil {
+					_ = client.Disconnect(context.Background())
+
+					return err
+				}
+
+				logger.Info("Connect to MongoDB successfully")
+
+				return nil
+			},
 			OnStop: func(ctx context.Context) error {
 				logger.Info("Closing MongoDB connection")
 				return client.Disconnect(ctx)
@@ -52,6 +88,61 @@ func New(cfg *config.Config, lc fx.Lifecycle) (*mongo.Database, error) {
 	return db, nil
 }
 
+// pingWithRetry pings primary until it answers, waiting longer after each failure.
+// It stops waiting when ctx is done, ctx of OnStart hook is done when start timeout of app is reached.
+func pingWithRetry(ctx context.Context, client *mongo.Client, cfg config.Mongo) error {
+	backoff := cfg.ConnectBackoff
+
+	for attempt := 0; ; attempt++ {
+		err := client.Ping(ctx, readpref.Primary())
+		if err == nil || attempt >= cfg.ConnectRetries {
+			return err
+		}
+
+		logger.Warnw(
+			"ping MongoDB fail, retrying",
+			"attempt", attempt+1,
+			"retry_in", backoff,
+			"err", err,
+		)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if cfg.ConnectMaxBackoff > 0 && backoff > cfg.ConnectMaxBackoff {
+			backoff = cfg.ConnectMaxBackoff
+		}
+	}
+}
+
+// chainCommandMonitors returns monitor calling monitors in order, client accepts only one monitor.
+func chainCommandMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			for _, monitor := range monitors {
+				monitor.Started(ctx, e)
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			for _, monitor := range monitors {
+				monitor.Succeeded(ctx, e)
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			for _, monitor := range monitors {
+				monitor.Failed(ctx, e)
+			}
+		},
+	}
+}
+
 func MapToBSON(val map[string]any) bson.D {
 	newFilter := bson.D{}
 	for k, v := range val {
@@ -61,6 +152,9 @@ func MapToBSON(val map[string]any) bson.D {
 	return newFilter
 }
 
+// ConvertFilterToBSON returns filter as is, keys and values of filter can contain operators.
+//
+// Deprecated: filter built from request can inject operators, use listquery to parse filter of request.
 func ConvertFilterToBSON(filter map[string]any) bson.D {
 	newFilter := bson.D{}
 	for k, v := range filter {
@@ -88,7 +182,7 @@ func CountDocuments(
 
 	cursors, err := coll.Aggregate(ctx, countPipeline)
 	if err != nil {
-		logger.Errorw(
+		logger.WithContext(ctx).Errorw(
 			"cannot count document for",
 			"collection", coll.Name(),
 			"err", err,
@@ -99,7 +193,7 @@ func CountDocuments(
 
 	var result []countResult
 	if err := cursors.All(ctx, &result); err != nil {
-		logger.Errorw(
+		logger.WithContext(ctx).Errorw(
 			"decode when count document err",
 			"collection", coll.Name(),
 			"err", err,
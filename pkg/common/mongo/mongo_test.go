@@ -0,0 +1,31 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	config "github.com/golang/be/config/common"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestPingWithRetry(t *testing.T) {
+	t.Run("stop waiting when ctx is done", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		// nothing listens on the port, so ping fails when ctx is done
+		client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+		assert.NoError(t, err)
+
+		defer func() { _ = client.Disconnect(context.Background()) }()
+
+		start := time.Now()
+		err = pingWithRetry(ctx, client, config.Mongo{ConnectRetries: 5, ConnectBackoff: time.Hour})
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
@@ -0,0 +1,154 @@
+package mongo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	config "github.com/golang/be/config/common"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+// clientOptions returns options of client from config, they are applied after ConnURI so they override it.
+func clientOptions(cfg config.Mongo) (*options.ClientOptions, error) {
+	opts := options.Client()
+
+	if cfg.AppName != "" {
+		opts.SetAppName(cfg.AppName)
+	}
+
+	// any timeout, even zero, makes driver ignore max time of operations
+	if cfg.Timeout > 0 {
+		opts.SetTimeout(cfg.Timeout)
+	}
+
+	if cfg.ConnectTimeout > 0 {
+		opts.SetConnectTimeout(cfg.ConnectTimeout)
+	}
+
+	if cfg.ServerSelectionTimeout > 0 {
+		opts.SetServerSelectionTimeout(cfg.ServerSelectionTimeout)
+	}
+
+	if cfg.MinPoolSize > 0 {
+		opts.SetMinPoolSize(cfg.MinPoolSize)
+	}
+
+	if cfg.MaxPoolSize > 0 {
+		opts.SetMaxPoolSize(cfg.MaxPoolSize)
+	}
+
+	if cfg.MaxConnIdleTime > 0 {
+		opts.SetMaxConnIdleTime(cfg.MaxConnIdleTime)
+	}
+
+	if cfg.RetryReads != nil {
+		opts.SetRetryReads(*cfg.RetryReads)
+	}
+
+	if cfg.RetryWrites != nil {
+		opts.SetRetryWrites(*cfg.RetryWrites)
+	}
+
+	if cfg.ReadPreference != "" {
+		readPref, err := readPreference(cfg.ReadPreference, cfg.MaxStaleness)
+		if err != nil {
+			return nil, err
+		}
+
+		opts.SetReadPreference(readPref)
+	}
+
+	if cfg.ReadConcern != "" {
+		opts.SetReadConcern(readconcern.New(readconcern.Level(cfg.ReadConcern)))
+	}
+
+	if writeConcern := newWriteConcern(cfg.WriteConcern); writeConcern != nil {
+		opts.SetWriteConcern(writeConcern)
+	}
+
+	if len(cfg.Compressors) > 0 {
+		opts.SetCompressors(cfg.Compressors)
+	}
+
+	return opts, opts.Validate()
+}
+
+// readPreference returns read preference of mode, primary can't have max staleness.
+func readPreference(mode string, maxStaleness time.Duration) (*readpref.ReadPref, error) {
+	readMode, err := readpref.ModeFromString(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []readpref.Option
+	if readMode != readpref.PrimaryMode && maxStaleness > 0 {
+		opts = append(opts, readpref.WithMaxStaleness(maxStaleness))
+	}
+
+	readPref, err := readpref.New(readMode, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("read preference %s: %w", mode, err)
+	}
+
+	return readPref, nil
+}
+
+func newWriteConcern(cfg config.MongoWriteConcern) *writeconcern.WriteConcern {
+	var opts []writeconcern.Option
+
+	switch w := strings.TrimSpace(cfg.W); {
+	case w == "":
+	case strings.EqualFold(w, "majority"):
+		opts = append(opts, writeconcern.WMajority())
+	default:
+		if n, err := strconv.Atoi(w); err == nil {
+			opts = append(opts, writeconcern.W(n))
+		} else {
+			opts = append(opts, writeconcern.WTagSet(w))
+		}
+	}
+
+	if cfg.Journal != nil {
+		opts = append(opts, writeconcern.J(*cfg.Journal))
+	}
+
+	if len(opts) == 0 {
+		return nil
+	}
+
+	return writeconcern.New(opts...)
+}
+
+// ReadPreferences specific read preferences repositories choose by query.
+type ReadPreferences struct {
+	list *readpref.ReadPref
+}
+
+func NewReadPreferences(cfg *config.Config) (*ReadPreferences, error) {
+	if cfg.Mongo.ListReadPreference == "" {
+		return &ReadPreferences{}, nil
+	}
+
+	list, err := readPreference(cfg.Mongo.ListReadPreference, cfg.Mongo.MaxStaleness)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReadPreferences{list: list}, nil
+}
+
+// List returns options of collection serving heavy list queries, they may read slightly stale data from secondaries,
+// so primary is kept for writes and reads which must see them.
+func (p *ReadPreferences) List() *options.CollectionOptions {
+	opts := options.Collection()
+	if p != nil && p.list != nil {
+		opts.SetReadPreference(p.list)
+	}
+
+	return opts
+}
@@ -0,0 +1,70 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	config "github.com/golang/be/config/common"
+	"github.com/golang/be/pkg/common/utils"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestClientOptions(t *testing.T) {
+	t.Run("apply config", func(t *testing.T) {
+		opts, err := clientOptions(config.Mongo{
+			AppName:        "core-service",
+			Timeout:        5 * time.Second,
+			MaxPoolSize:    50,
+			RetryWrites:    utils.ToPtr(false),
+			ReadPreference: "secondaryPreferred",
+			MaxStaleness:   2 * time.Minute,
+			ReadConcern:    "majority",
+			WriteConcern:   config.MongoWriteConcern{W: "majority", Journal: utils.ToPtr(true)},
+			Compressors:    []string{"zstd"},
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "core-service", *opts.AppName)
+		assert.Equal(t, 5*time.Second, *opts.Timeout)
+		assert.Equal(t, uint64(50), *opts.MaxPoolSize)
+		assert.False(t, *opts.RetryWrites)
+		assert.Nil(t, opts.RetryReads)
+		assert.Equal(t, readpref.SecondaryPreferredMode, opts.ReadPreference.Mode())
+		maxStaleness, _ := opts.ReadPreference.MaxStaleness()
+		assert.Equal(t, 2*time.Minute, maxStaleness)
+		assert.Equal(t, "majority", opts.ReadConcern.GetLevel())
+		assert.Equal(t, "majority", opts.WriteConcern.GetW())
+		assert.True(t, opts.WriteConcern.GetJ())
+		assert.Equal(t, []string{"zstd"}, opts.Compressors)
+	})
+
+	t.Run("keep defaults", func(t *testing.T) {
+		opts, err := clientOptions(config.Mongo{})
+		assert.NoError(t, err)
+
+		assert.Nil(t, opts.Timeout)
+		assert.Nil(t, opts.ReadPreference)
+		assert.Nil(t, opts.WriteConcern)
+	})
+
+	t.Run("reject invalid read preference", func(t *testing.T) {
+		_, err := clientOptions(config.Mongo{ReadPreference: "fastest"})
+		assert.Error(t, err)
+	})
+
+	t.Run("parse write concern", func(t *testing.T) {
+		assert.Equal(t, 2, newWriteConcern(config.MongoWriteConcern{W: "2"}).GetW())
+		assert.Equal(t, "dc-east", newWriteConcern(config.MongoWriteConcern{W: "dc-east"}).GetW())
+	})
+}
+
+func TestReadPreferences(t *testing.T) {
+	prefs, err := NewReadPreferences(&config.Config{Mongo: config.Mongo{ListReadPreference: "secondaryPreferred"}})
+	assert.NoError(t, err)
+	assert.Equal(t, readpref.SecondaryPreferredMode, prefs.List().ReadPreference.Mode())
+
+	prefs, err = NewReadPreferences(&config.Config{})
+	assert.NoError(t, err)
+	assert.Nil(t, prefs.List().ReadPreference)
+}
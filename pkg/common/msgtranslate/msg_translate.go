@@ -32,6 +32,11 @@ func Translate(translationKey string, lang *string, data map[string]any) string
 		langKey = *lang
 	}
 
+	// keys are returned untranslated until translator is initialized
+	if singleton == nil {
+		return translationKey
+	}
+
 	translator := singleton.translators[langKey]
 	if translator == nil {
 		return translationKey
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := Limit{Requests: 60, Period: time.Minute, Burst: 2}
+
+	t.Run("allows burst then limits", func(t *testing.T) {
+		for i := 1; i >= 0; i-- {
+			result, err := store.Take(context.Background(), "client", limit)
+			assert.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, 2, result.Limit)
+			assert.Equal(t, i, result.Remaining)
+		}
+
+		result, err := store.Take(context.Background(), "client", limit)
+		assert.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, time.Second, result.RetryAfter)
+		assert.Equal(t, 2*time.Second, result.Reset)
+	})
+
+	t.Run("keeps buckets by key", func(t *testing.T) {
+		result, err := store.Take(context.Background(), "other", limit)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("refills tokens", func(t *testing.T) {
+		now = now.Add(time.Second)
+
+		result, err := store.Take(context.Background(), "client", limit)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+	})
+
+	t.Run("sweeps full buckets", func(t *testing.T) {
+		now = now.Add(2 * sweepInterval)
+
+		_, err := store.Take(context.Background(), "client", limit)
+		assert.NoError(t, err)
+		assert.Len(t, store.buckets, 1)
+	})
+
+	t.Run("zero limit is disabled", func(t *testing.T) {
+		assert.False(t, Limit{}.Enabled())
+		assert.True(t, limit.Enabled())
+	})
+}
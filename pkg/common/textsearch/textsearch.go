@@ -0,0 +1,126 @@
+// Package textsearch normalizes texts for search, so words are matched regardless of case and Vietnamese diacritics.
+package textsearch
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/golang/be/pkg/common/utils"
+)
+
+// MaxTerms specific max number of terms of query, the rest are ignored.
+const MaxTerms = 10
+
+// Highlight tags wrap matched words in highlighted text.
+const (
+	HighlightStart = "<em>"
+	HighlightEnd   = "</em>"
+)
+
+// Query specific normalized terms searched.
+//
+// Prefix specific whether last term matches words starting with it, e.g. while user is typing.
+type Query struct {
+	Terms  []string
+	Prefix bool
+}
+
+// ParseQuery returns query of normalized words of text.
+func ParseQuery(text string, prefix bool) Query {
+	terms := Tokens(text)
+	if len(terms) > MaxTerms {
+		terms = terms[:MaxTerms]
+	}
+
+	return Query{Terms: terms, Prefix: prefix}
+}
+
+func (q Query) IsEmpty() bool {
+	return len(q.Terms) == 0
+}
+
+// Text returns terms joined by space, it has no search operators such as negation or phrases.
+func (q Query) Text() string {
+	return strings.Join(q.Terms, " ")
+}
+
+// Matches reports whether normalized word matches a term of query.
+func (q Query) Matches(word string) bool {
+	for i, term := range q.Terms {
+		if word == term || (q.Prefix && i == len(q.Terms)-1 && strings.HasPrefix(word, term)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Normalize returns words of text in lower case without diacritics joined by space, e.g. "Được!" becomes "duoc".
+func Normalize(text string) string {
+	return strings.Join(strings.FieldsFunc(utils.TextNormalizer(text), isSeparator), " ")
+}
+
+// Tokens returns distinct normalized words of texts in order they appear.
+func Tokens(texts ...string) []string {
+	var (
+		tokens []string
+		seen   = map[string]bool{}
+	)
+
+	for _, text := range texts {
+		for _, word := range strings.FieldsFunc(utils.TextNormalizer(text), isSeparator) {
+			if !seen[word] {
+				seen[word] = true
+				tokens = append(tokens, word)
+			}
+		}
+	}
+
+	return tokens
+}
+
+// Highlight returns HTML escaped text with words matching query wrapped in highlight tags,
+// matched is false if no word matches.
+func Highlight(text string, query Query) (highlighted string, matched bool) {
+	var (
+		builder strings.Builder
+		start   = -1
+	)
+
+	flush := func(end int) {
+		word := text[start:end]
+		if query.Matches(utils.TextNormalizer(word)) {
+			matched = true
+			builder.WriteString(HighlightStart + html.EscapeString(word) + HighlightEnd)
+		} else {
+			builder.WriteString(html.EscapeString(word))
+		}
+
+		start = -1
+	}
+
+	for i, r := range text {
+		switch {
+		case !isSeparator(r) && start < 0:
+			start = i
+		case isSeparator(r):
+			if start >= 0 {
+				flush(i)
+			}
+
+			builder.WriteString(html.EscapeString(string(r)))
+		}
+	}
+
+	if start >= 0 {
+		flush(len(text))
+	}
+
+	return builder.String(), matched
+}
+
+// isSeparator reports whether r separates words, combining marks belong to words of decomposed texts.
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r)
+}
@@ -0,0 +1,56 @@
+package textsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery(t *testing.T) {
+	t.Run("normalizes terms", func(t *testing.T) {
+		query := ParseQuery("  Được, \"trà\" -xanh  được ", false)
+		assert.Equal(t, []string{"duoc", "tra", "xanh"}, query.Terms)
+		assert.Equal(t, "duoc tra xanh", query.Text())
+	})
+
+	t.Run("matches words", func(t *testing.T) {
+		query := ParseQuery("duoc tra", false)
+		assert.True(t, query.Matches("duoc"))
+		assert.False(t, query.Matches("tran"))
+
+		query.Prefix = true
+		assert.True(t, query.Matches("tran"))
+		assert.False(t, query.Matches("duocs"))
+	})
+
+	t.Run("is empty without words", func(t *testing.T) {
+		assert.True(t, ParseQuery(" -,. ", true).IsEmpty())
+	})
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "tra xanh thai nguyen", Normalize("Trà xanh - Thái Nguyên"))
+	assert.Equal(t, []string{"duoc", "ha", "noi"}, Tokens("ĐƯỢC", "Hà Nội", "được"))
+	// decomposed text keeps combining marks apart from letters
+	assert.Equal(t, "duoc", Normalize("\u0110u\u031bo\u031b\u0323c"))
+}
+
+func TestHighlight(t *testing.T) {
+	t.Run("wraps matched words", func(t *testing.T) {
+		highlighted, matched := Highlight("Trà Được <Đặc biệt>", ParseQuery("duoc dac", false))
+		assert.True(t, matched)
+		assert.Equal(t, "Trà <em>Được</em> &lt;<em>Đặc</em> biệt&gt;", highlighted)
+	})
+
+	t.Run("wraps words by prefix", func(t *testing.T) {
+		highlighted, matched := Highlight("Trà Tân Cương", ParseQuery("tra ta", true))
+		assert.True(t, matched)
+		assert.Equal(t, "<em>Trà</em> <em>Tân</em> Cương", highlighted)
+	})
+
+	t.Run("reports no match", func(t *testing.T) {
+		highlighted, matched := Highlight("Cà phê", ParseQuery("tra", false))
+		assert.False(t, matched)
+		assert.Equal(t, "Cà phê", highlighted)
+	})
+}
@@ -0,0 +1,151 @@
+// Package tlsreload keeps TLS certificate of server in sync with files on disk, so rotated certificates
+// are served without restart.
+package tlsreload
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/be/pkg/common/logger"
+)
+
+// ErrInvalidClientAuth is returned when client auth mode is unknown.
+var ErrInvalidClientAuth = errors.New("invalid tls client auth")
+
+// Reloader serves certificate loaded from cert and key files, it is reloaded when either file changes.
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	version string
+}
+
+// New returns reloader with certificate loaded from cert and key files.
+func New(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetCertificate returns current certificate, it is used as tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// Reload loads certificate again if cert or key file changed since last load, it returns whether it did.
+//
+// Current certificate is kept when files can't be loaded, e.g. while they are being rotated.
+func (r *Reloader) Reload() (bool, error) {
+	version, err := fileVersion(r.certFile, r.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	unchanged := version == r.version
+	r.mu.RUnlock()
+
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("load tls certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.version = version
+	r.mu.Unlock()
+
+	return true, nil
+}
+
+// Watch checks files every interval and reloads certificate when they change, until ctx is done.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.Reload()
+			if err != nil {
+				logger.Errorw("Reload TLS certificate failed", "cert_file", r.certFile, "error", err)
+
+				continue
+			}
+
+			if reloaded {
+				logger.Infow("Reloaded TLS certificate", "cert_file", r.certFile)
+			}
+		}
+	}
+}
+
+// fileVersion returns a value changing whenever one of files is modified or replaced.
+func fileVersion(files ...string) (string, error) {
+	versions := make([]string, 0, len(files))
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", err
+		}
+
+		versions = append(versions, fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size()))
+	}
+
+	return strings.Join(versions, "/"), nil
+}
+
+// ClientAuth returns client auth type by name, empty name means no client certificate is requested.
+func ClientAuth(name string) (tls.ClientAuthType, error) {
+	switch name {
+	case "", "none":
+		return tls.NoClientCert, nil
+	case "request":
+		return tls.RequestClientCert, nil
+	case "require":
+		return tls.RequireAnyClientCert, nil
+	case "verify_if_given":
+		return tls.VerifyClientCertIfGiven, nil
+	case "require_and_verify":
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("%w: %s", ErrInvalidClientAuth, name)
+	}
+}
+
+// LoadCertPool returns pool of PEM encoded certificates in file.
+func LoadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificate found in %s", file)
+	}
+
+	return pool, nil
+}
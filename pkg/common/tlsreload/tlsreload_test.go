@@ -0,0 +1,101 @@
+package tlsreload
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeCert(t *testing.T, certFile, keyFile, commonName string, modTime time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	assert.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	assert.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+}
+
+func commonName(t *testing.T, r *Reloader) string {
+	cert, err := r.GetCertificate(nil)
+	assert.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	assert.NoError(t, err)
+
+	return leaf.Subject.CommonName
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	now := time.Now()
+
+	writeCert(t, certFile, keyFile, "first", now.Add(-time.Minute))
+
+	reloader, err := New(certFile, keyFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", commonName(t, reloader))
+
+	t.Run(
+		"unchanged files", func(t *testing.T) {
+			reloaded, err := reloader.Reload()
+			assert.NoError(t, err)
+			assert.False(t, reloaded)
+		},
+	)
+
+	t.Run(
+		"rotated files", func(t *testing.T) {
+			writeCert(t, certFile, keyFile, "second", now)
+
+			reloaded, err := reloader.Reload()
+			assert.NoError(t, err)
+			assert.True(t, reloaded)
+			assert.Equal(t, "second", commonName(t, reloader))
+		},
+	)
+
+	t.Run(
+		"broken files keep current certificate", func(t *testing.T) {
+			assert.NoError(t, os.WriteFile(keyFile, []byte("garbage"), 0o600))
+
+			_, err := reloader.Reload()
+			assert.Error(t, err)
+			assert.Equal(t, "second", commonName(t, reloader))
+		},
+	)
+}
+
+func TestClientAuth(t *testing.T) {
+	clientAuth, err := ClientAuth("require_and_verify")
+	assert.NoError(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, clientAuth)
+
+	_, err = ClientAuth("always")
+	assert.ErrorIs(t, err, ErrInvalidClientAuth)
+}
@@ -0,0 +1,151 @@
+// Package tracing configures OpenTelemetry tracing and W3C trace context propagation.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	config "github.com/golang/be/config/core_service"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+
+	// instrumentationName is name of tracer of spans started by Start.
+	instrumentationName = "github.com/golang/be"
+)
+
+var ErrInvalidExporter = errors.New("invalid tracing exporter")
+
+// New returns tracer provider exporting spans by configured exporter and sets it as global provider.
+//
+// Spans are flushed when service stops. Trace context is propagated even if exporter is none,
+// so traces of callers are not broken by service.
+func New(cfg *config.Config, lc fx.Lifecycle) (trace.TracerProvider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if cfg.Tracing.Exporter == "" || cfg.Tracing.Exporter == ExporterNone {
+		return otel.GetTracerProvider(), nil
+	}
+
+	exporter, closer, err := newExporter(cfg.Tracing)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(cfg.App.Name),
+			semconv.ServiceVersion(cfg.App.Version),
+			semconv.DeploymentEnvironment(cfg.App.Env),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Tracing.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(provider)
+
+	lc.Append(
+		fx.Hook{
+			OnStop: func(ctx context.Context) error {
+				err := provider.Shutdown(ctx)
+				if closer != nil {
+					err = errors.Join(err, closer.Close())
+				}
+
+				return err
+			},
+		},
+	)
+
+	return provider, nil
+}
+
+// newExporter returns span exporter and file it writes to if it must be closed.
+func newExporter(cfg config.Tracing) (sdktrace.SpanExporter, io.Closer, error) {
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+
+		exporter, err := otlptracehttp.New(context.Background(), opts...)
+
+		return exporter, nil, err
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+
+		return exporter, nil, err
+	case ExporterFile:
+		file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			_ = file.Close()
+
+			return nil, nil, err
+		}
+
+		return exporter, file, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidExporter, cfg.Exporter)
+	}
+}
+
+// Start starts span as child of span in ctx, e.g. span of use case method called by http handler.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// TraceID returns ID of trace in ctx, it is empty if ctx is not traced.
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+
+	return spanContext.TraceID().String()
+}
+
+// SpanID returns ID of span in ctx, it is empty if ctx is not traced.
+func SpanID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasSpanID() {
+		return ""
+	}
+
+	return spanContext.SpanID().String()
+}
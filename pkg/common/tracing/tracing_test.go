@@ -0,0 +1,45 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	config "github.com/golang/be/config/core_service"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestNew(t *testing.T) {
+	t.Run(
+		"propagate trace context without exporter", func(t *testing.T) {
+			_, err := New(&config.Config{}, fxtest.NewLifecycle(t))
+			assert.NoError(t, err)
+
+			header := http.Header{}
+			header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+			ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(header))
+			ctx, span := Start(ctx, "child")
+			defer span.End()
+
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", TraceID(ctx))
+		},
+	)
+
+	t.Run(
+		"untraced context", func(t *testing.T) {
+			assert.Empty(t, TraceID(context.Background()))
+			assert.Empty(t, SpanID(context.Background()))
+		},
+	)
+
+	t.Run(
+		"invalid exporter", func(t *testing.T) {
+			_, err := New(&config.Config{Tracing: config.Tracing{Exporter: "jaeger"}}, fxtest.NewLifecycle(t))
+			assert.ErrorIs(t, err, ErrInvalidExporter)
+		},
+	)
+}
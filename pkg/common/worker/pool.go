@@ -0,0 +1,123 @@
+// Package worker implements a pool of goroutines running background jobs.
+package worker
+
+import (
+	"context"
+	"sync"
+
+	"github.com/golang/be/pkg/common/logger"
+)
+
+// Job define a background job, ctx is cancelled when pool is stopped.
+type Job func(ctx context.Context)
+
+// Pool runs jobs by a fixed number of workers, jobs wait in a bounded queue.
+type Pool struct {
+	name    string
+	size    int
+	jobs    chan Job
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.RWMutex
+	stopped bool
+}
+
+// NewPool returns a pool of size workers, Start must be called before jobs are run.
+func NewPool(name string, size, queueSize int) *Pool {
+	if size < 1 {
+		size = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Pool{
+		name:   name,
+		size:   size,
+		jobs:   make(chan Job, queueSize),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start starts workers of pool.
+func (p *Pool) Start() {
+	for i := 0; i < p.size; i++ {
+		p.wg.Add(1)
+
+		go p.work()
+	}
+}
+
+// Submit enqueues job, it returns false without blocking when queue is full or pool is stopped.
+func (p *Pool) Submit(job Job) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.stopped {
+		return false
+	}
+
+	select {
+	case p.jobs <- job:
+		return true
+	default:
+		logger.Errorw(
+			"worker queue is full",
+			"pool", p.name,
+		)
+
+		return false
+	}
+}
+
+// Stop stops accepting jobs and waits for queued jobs to finish until ctx is done, then running jobs are cancelled.
+func (p *Pool) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.stopped {
+		p.stopped = true
+		close(p.jobs)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancel()
+
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		<-done
+
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for job := range p.jobs {
+		p.run(job)
+	}
+}
+
+func (p *Pool) run(job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorw(
+				"worker job panic",
+				"pool", p.name,
+				"panic", r,
+			)
+		}
+	}()
+
+	job(p.ctx)
+}
@@ -3,7 +3,9 @@ package firebase
 import (
 	"context"
 
+	config "github.com/golang/be/config/core_service"
 	"github.com/golang/be/pkg/common/logger"
+	"github.com/golang/be/pkg/core_service/objectstorage"
 
 	firebase "firebase.google.com/go"
 	"firebase.google.com/go/auth"
@@ -19,7 +21,9 @@ type Apps struct {
 }
 
 // NewApps returns Apps, which contains all instances firebase used in service.
-func NewApps() Apps {
+//
+// Storage client is only initialized when firebase storage driver is used.
+func NewApps(cfg *config.Config) Apps {
 	ctx := context.Background()
 	app, err := firebase.NewApp(ctx, nil)
 	if err != nil {
@@ -31,6 +35,10 @@ func NewApps() Apps {
 		logger.Fatal("Fail to init Firebase Auth client", "error", err)
 	}
 
+	if cfg.Storage.Driver == objectstorage.DriverLocal {
+		return Apps{Auth: authClient}
+	}
+
 	storageClient, err := app.Storage(ctx)
 	if err != nil {
 		logger.Fatal("Fail to init Storage client", "error", err)
@@ -0,0 +1,397 @@
+// Package gltf inspects glTF 2.0 assets, see https://registry.khronos.org/glTF/specs/2.0/glTF-2.0.html.
+package gltf
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strings"
+)
+
+const (
+	glbMagic      = "glTF"
+	glbVersion    = 2
+	glbHeaderSize = 12
+
+	chunkTypeJSON = 0x4e4f534a
+	chunkTypeBIN  = 0x004e4942
+
+	// maxJSONSize limits size of JSON document kept in memory.
+	maxJSONSize = 16 << 20
+)
+
+// Primitive topology modes, triangles is the default.
+const (
+	modeTriangles     = 4
+	modeTriangleStrip = 5
+	modeTriangleFan   = 6
+)
+
+var (
+	ErrInvalidAsset      = errors.New("invalid gltf asset")
+	ErrUnsupportedAsset  = errors.New("unsupported gltf version")
+	ErrExternalReference = errors.New("gltf asset references external file")
+)
+
+// Stats define metadata of an asset.
+//
+// Triangles counts triangles of all mesh primitives, each mesh counts once however many nodes use it.
+// Triangles and Vertices are capped at math.MaxInt.
+// BoundsMin and BoundsMax specific axis aligned bounding box of default scene in world space.
+type Stats struct {
+	Version    string
+	Generator  string
+	Meshes     int
+	Materials  int
+	Triangles  int
+	Vertices   int
+	Nodes      int
+	Textures   int
+	Images     int
+	Animations int
+	BoundsMin  [3]float64
+	BoundsMax  [3]float64
+}
+
+type document struct {
+	Asset struct {
+		Version    string `json:"version"`
+		MinVersion string `json:"minVersion"`
+		Generator  string `json:"generator"`
+	} `json:"asset"`
+	Scene     *int       `json:"scene"`
+	Scenes    []scene    `json:"scenes"`
+	Nodes     []node     `json:"nodes"`
+	Meshes    []mesh     `json:"meshes"`
+	Accessors []accessor `json:"accessors"`
+	Buffers   []buffer   `json:"buffers"`
+	Images    []struct {
+		URI string `json:"uri"`
+	} `json:"images"`
+	Materials  []json.RawMessage `json:"materials"`
+	Textures   []json.RawMessage `json:"textures"`
+	Animations []json.RawMessage `json:"animations"`
+}
+
+type scene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type node struct {
+	Children    []int     `json:"children"`
+	Mesh        *int      `json:"mesh"`
+	Matrix      []float64 `json:"matrix"`
+	Translation []float64 `json:"translation"`
+	Rotation    []float64 `json:"rotation"`
+	Scale       []float64 `json:"scale"`
+}
+
+type mesh struct {
+	Primitives []struct {
+		Attributes map[string]int `json:"attributes"`
+		Indices    *int           `json:"indices"`
+		Mode       *int           `json:"mode"`
+	} `json:"primitives"`
+}
+
+type accessor struct {
+	Count int       `json:"count"`
+	Min   []float64 `json:"min"`
+	Max   []float64 `json:"max"`
+}
+
+type buffer struct {
+	URI        string `json:"uri"`
+	ByteLength int64  `json:"byteLength"`
+}
+
+// InspectGLB reads binary glTF r of size bytes, binary chunk is skipped.
+func InspectGLB(r io.Reader, size int64) (*Stats, error) {
+	var header [glbHeaderSize + 8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, ErrInvalidAsset
+	}
+
+	if string(header[:4]) != glbMagic {
+		return nil, ErrInvalidAsset
+	}
+
+	if binary.LittleEndian.Uint32(header[4:8]) != glbVersion {
+		return nil, ErrUnsupportedAsset
+	}
+
+	length := int64(binary.LittleEndian.Uint32(header[8:12]))
+	jsonLength := int64(binary.LittleEndian.Uint32(header[12:16]))
+
+	if length != size || binary.LittleEndian.Uint32(header[16:20]) != chunkTypeJSON ||
+		jsonLength > maxJSONSize || glbHeaderSize+8+jsonLength > length {
+		return nil, ErrInvalidAsset
+	}
+
+	content := make([]byte, jsonLength)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, ErrInvalidAsset
+	}
+
+	// binary chunk is optional, it must follow JSON chunk
+	var binLength int64 = -1
+
+	if remaining := length - glbHeaderSize - 8 - jsonLength; remaining > 0 {
+		var chunkHeader [8]byte
+		if _, err := io.ReadFull(r, chunkHeader[:]); err != nil {
+			return nil, ErrInvalidAsset
+		}
+
+		binLength = int64(binary.LittleEndian.Uint32(chunkHeader[:4]))
+		if binary.LittleEndian.Uint32(chunkHeader[4:]) != chunkTypeBIN || 8+binLength > remaining {
+			return nil, ErrInvalidAsset
+		}
+	}
+
+	return inspect(content, binLength)
+}
+
+// InspectJSON reads glTF JSON document r.
+func InspectJSON(r io.Reader) (*Stats, error) {
+	content, err := io.ReadAll(io.LimitReader(r, maxJSONSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(content) > maxJSONSize {
+		return nil, ErrInvalidAsset
+	}
+
+	return inspect(content, -1)
+}
+
+// inspect validates document content, binLength is size of GLB binary chunk or -1 if there is none.
+func inspect(content []byte, binLength int64) (*Stats, error) {
+	var doc document
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, ErrInvalidAsset
+	}
+
+	if !strings.HasPrefix(doc.Asset.Version, "2.") {
+		return nil, ErrUnsupportedAsset
+	}
+
+	if err := checkReferences(&doc, binLength); err != nil {
+		return nil, err
+	}
+
+	stats := &Stats{
+		Version:    doc.Asset.Version,
+		Generator:  doc.Asset.Generator,
+		Meshes:     len(doc.Meshes),
+		Materials:  len(doc.Materials),
+		Nodes:      len(doc.Nodes),
+		Textures:   len(doc.Textures),
+		Images:     len(doc.Images),
+		Animations: len(doc.Animations),
+	}
+
+	for _, m := range doc.Meshes {
+		for _, primitive := range m.Primitives {
+			position, ok := primitive.Attributes["POSITION"]
+			if !ok {
+				continue
+			}
+
+			if !doc.validAccessor(position) {
+				return nil, ErrInvalidAsset
+			}
+
+			count := doc.Accessors[position].Count
+			stats.Vertices = saturatingAdd(stats.Vertices, count)
+
+			if primitive.Indices != nil {
+				if !doc.validAccessor(*primitive.Indices) {
+					return nil, ErrInvalidAsset
+				}
+
+				count = doc.Accessors[*primitive.Indices].Count
+			}
+
+			mode := modeTriangles
+			if primitive.Mode != nil {
+				mode = *primitive.Mode
+			}
+
+			stats.Triangles = saturatingAdd(stats.Triangles, triangles(mode, count))
+		}
+	}
+
+	if err := doc.bounds(stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// checkReferences allows embedded data URIs only, an uploaded asset is a single file so other files can't be resolved.
+func checkReferences(doc *document, binLength int64) error {
+	for i, buf := range doc.Buffers {
+		if buf.URI == "" {
+			// only first buffer of GLB may refer to binary chunk
+			if i != 0 || binLength < buf.ByteLength {
+				return ErrInvalidAsset
+			}
+
+			continue
+		}
+
+		if !strings.HasPrefix(buf.URI, "data:") {
+			return ErrExternalReference
+		}
+	}
+
+	for _, img := range doc.Images {
+		if img.URI != "" && !strings.HasPrefix(img.URI, "data:") {
+			return ErrExternalReference
+		}
+	}
+
+	return nil
+}
+
+func (d *document) validAccessor(index int) bool {
+	return index >= 0 && index < len(d.Accessors) && d.Accessors[index].Count >= 0
+}
+
+// saturatingAdd adds non negative counts, sum is capped at math.MaxInt so it still exceeds any limit instead of wrapping.
+func saturatingAdd(a, b int) int {
+	if b > math.MaxInt-a {
+		return math.MaxInt
+	}
+
+	return a + b
+}
+
+func triangles(mode, count int) int {
+	switch mode {
+	case modeTriangles:
+		return count / 3
+	case modeTriangleStrip, modeTriangleFan:
+		if count < 3 {
+			return 0
+		}
+
+		return count - 2
+	default:
+		return 0
+	}
+}
+
+// bounds sets bounding box of default scene, or first scene if default is not declared.
+//
+// Position accessors must declare min and max, corners of their boxes are transformed by node hierarchy.
+func (d *document) bounds(stats *Stats) error {
+	if len(d.Scenes) == 0 {
+		return nil
+	}
+
+	sceneIndex := 0
+	if d.Scene != nil {
+		sceneIndex = *d.Scene
+	}
+
+	if sceneIndex < 0 || sceneIndex >= len(d.Scenes) {
+		return ErrInvalidAsset
+	}
+
+	box := newBoundingBox()
+	visited := make([]bool, len(d.Nodes))
+
+	for _, root := range d.Scenes[sceneIndex].Nodes {
+		if err := d.visit(root, identity(), visited, box); err != nil {
+			return err
+		}
+	}
+
+	if box.empty() {
+		return nil
+	}
+
+	stats.BoundsMin, stats.BoundsMax = box.min, box.max
+
+	return nil
+}
+
+// visit adds meshes of node and its descendants to box, node hierarchy must be a tree.
+func (d *document) visit(index int, parent matrix, visited []bool, box *boundingBox) error {
+	if index < 0 || index >= len(d.Nodes) || visited[index] {
+		return ErrInvalidAsset
+	}
+
+	visited[index] = true
+	n := d.Nodes[index]
+
+	world := parent.mul(n.localMatrix())
+
+	if n.Mesh != nil {
+		if *n.Mesh < 0 || *n.Mesh >= len(d.Meshes) {
+			return ErrInvalidAsset
+		}
+
+		for _, primitive := range d.Meshes[*n.Mesh].Primitives {
+			position, ok := primitive.Attributes["POSITION"]
+			if !ok {
+				continue
+			}
+
+			acc := d.Accessors[position]
+			if len(acc.Min) != 3 || len(acc.Max) != 3 {
+				return ErrInvalidAsset
+			}
+
+			box.addBox(world, acc.Min, acc.Max)
+		}
+	}
+
+	for _, child := range n.Children {
+		if err := d.visit(child, world, visited, box); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type boundingBox struct {
+	min, max [3]float64
+}
+
+func newBoundingBox() *boundingBox {
+	return &boundingBox{
+		min: [3]float64{math.Inf(1), math.Inf(1), math.Inf(1)},
+		max: [3]float64{math.Inf(-1), math.Inf(-1), math.Inf(-1)},
+	}
+}
+
+func (b *boundingBox) empty() bool {
+	return b.min[0] > b.max[0]
+}
+
+// addBox extends b by corners of box min, max transformed by m.
+func (b *boundingBox) addBox(m matrix, min, max []float64) {
+	for corner := 0; corner < 8; corner++ {
+		var p [3]float64
+
+		for axis := 0; axis < 3; axis++ {
+			p[axis] = min[axis]
+			if corner&(1<<axis) != 0 {
+				p[axis] = max[axis]
+			}
+		}
+
+		p = m.transform(p)
+
+		for axis := 0; axis < 3; axis++ {
+			b.min[axis] = math.Min(b.min[axis], p[axis])
+			b.max[axis] = math.Max(b.max[axis], p[axis])
+		}
+	}
+}
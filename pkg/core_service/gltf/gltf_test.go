@@ -0,0 +1,132 @@
+package gltf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testDocument has a unit cube mesh of 12 triangles used by 2 nodes, second one is moved by 10 on x axis.
+const testDocument = `{
+	"asset": {"version": "2.0", "generator": "test"},
+	"scene": 0,
+	"scenes": [{"nodes": [0]}],
+	"nodes": [
+		{"mesh": 0, "children": [1], "scale": [2, 2, 2]},
+		{"mesh": 0, "translation": [10, 0, 0]}
+	],
+	"meshes": [{"primitives": [{"attributes": {"POSITION": 0}, "indices": 1, "material": 0}]}],
+	"materials": [{}],
+	"accessors": [
+		{"count": 8, "min": [-0.5, -0.5, -0.5], "max": [0.5, 0.5, 0.5]},
+		{"count": 36}
+	],
+	"buffers": [{"byteLength": 120}]
+}`
+
+func glb(document string, binLength int) []byte {
+	for len(document)%4 != 0 {
+		document += " "
+	}
+
+	length := glbHeaderSize + 8 + len(document) + 8 + binLength
+
+	res := append([]byte(glbMagic), binary.LittleEndian.AppendUint32(nil, glbVersion)...)
+	res = binary.LittleEndian.AppendUint32(res, uint32(length))
+	res = binary.LittleEndian.AppendUint32(res, uint32(len(document)))
+	res = binary.LittleEndian.AppendUint32(res, chunkTypeJSON)
+	res = append(res, document...)
+	res = binary.LittleEndian.AppendUint32(res, uint32(binLength))
+	res = binary.LittleEndian.AppendUint32(res, chunkTypeBIN)
+
+	return append(res, make([]byte, binLength)...)
+}
+
+func TestInspect(t *testing.T) {
+	t.Run(
+		"glb", func(t *testing.T) {
+			data := glb(testDocument, 120)
+
+			stats, err := InspectGLB(bytes.NewReader(data), int64(len(data)))
+			assert.NoError(t, err)
+			assert.Equal(t, "2.0", stats.Version)
+			assert.Equal(t, "test", stats.Generator)
+			assert.Equal(t, 1, stats.Meshes)
+			assert.Equal(t, 1, stats.Materials)
+			assert.Equal(t, 12, stats.Triangles)
+			assert.Equal(t, 8, stats.Vertices)
+			assert.Equal(t, 2, stats.Nodes)
+
+			// child is scaled by its parent, so it is moved by 20 and 2 units wide
+			assert.Equal(t, [3]float64{-1, -1, -1}, stats.BoundsMin)
+			assert.Equal(t, [3]float64{21, 1, 1}, stats.BoundsMax)
+		},
+	)
+
+	t.Run(
+		"glb with wrong length", func(t *testing.T) {
+			data := glb(testDocument, 120)
+
+			_, err := InspectGLB(bytes.NewReader(data), int64(len(data)+1))
+			assert.ErrorIs(t, err, ErrInvalidAsset)
+		},
+	)
+
+	t.Run(
+		"binary chunk smaller than buffer", func(t *testing.T) {
+			data := glb(testDocument, 60)
+
+			_, err := InspectGLB(bytes.NewReader(data), int64(len(data)))
+			assert.ErrorIs(t, err, ErrInvalidAsset)
+		},
+	)
+
+	t.Run(
+		"external reference", func(t *testing.T) {
+			document := strings.Replace(testDocument, `{"byteLength": 120}`, `{"uri": "cube.bin", "byteLength": 120}`, 1)
+
+			_, err := InspectJSON(strings.NewReader(document))
+			assert.ErrorIs(t, err, ErrExternalReference)
+
+			document = strings.Replace(document, "cube.bin", "data:application/octet-stream;base64,AAAA", 1)
+
+			_, err = InspectJSON(strings.NewReader(document))
+			assert.NoError(t, err)
+		},
+	)
+
+	t.Run(
+		"node cycle", func(t *testing.T) {
+			document := strings.Replace(testDocument, `"translation"`, `"children": [0], "translation"`, 1)
+
+			_, err := InspectJSON(strings.NewReader(document))
+			assert.ErrorIs(t, err, ErrInvalidAsset)
+		},
+	)
+
+	t.Run(
+		"counts do not overflow", func(t *testing.T) {
+			document := strings.Replace(testDocument, `"primitives": [`, `"primitives": [{"attributes": {"POSITION": 2}, "mode": 5}, `, 1)
+			document = strings.Replace(document, `{"count": 36}`, fmt.Sprintf(`{"count": 36}, {"count": %d, "min": [0, 0, 0], "max": [1, 1, 1]}`, math.MaxInt), 1)
+
+			data := glb(document, 120)
+
+			stats, err := InspectGLB(bytes.NewReader(data), int64(len(data)))
+			assert.NoError(t, err)
+			assert.Equal(t, math.MaxInt, stats.Vertices)
+			assert.Equal(t, math.MaxInt, stats.Triangles)
+		},
+	)
+
+	t.Run(
+		"unsupported version", func(t *testing.T) {
+			_, err := InspectJSON(strings.NewReader(`{"asset": {"version": "1.0"}}`))
+			assert.ErrorIs(t, err, ErrUnsupportedAsset)
+		},
+	)
+}
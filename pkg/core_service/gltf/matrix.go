@@ -0,0 +1,70 @@
+package gltf
+
+// matrix define a 4x4 column major transformation matrix as glTF stores it.
+type matrix [16]float64
+
+func identity() matrix {
+	return matrix{0: 1, 5: 1, 10: 1, 15: 1}
+}
+
+func (m matrix) mul(o matrix) matrix {
+	var res matrix
+
+	for col := 0; col < 4; col++ {
+		for row := 0; row < 4; row++ {
+			var sum float64
+			for k := 0; k < 4; k++ {
+				sum += m[k*4+row] * o[col*4+k]
+			}
+
+			res[col*4+row] = sum
+		}
+	}
+
+	return res
+}
+
+func (m matrix) transform(p [3]float64) [3]float64 {
+	var res [3]float64
+
+	for row := 0; row < 3; row++ {
+		res[row] = m[row]*p[0] + m[4+row]*p[1] + m[8+row]*p[2] + m[12+row]
+	}
+
+	return res
+}
+
+// localMatrix returns transformation of node, given by either a matrix or translation, rotation and scale.
+func (n *node) localMatrix() matrix {
+	if len(n.Matrix) == 16 {
+		var m matrix
+		copy(m[:], n.Matrix)
+
+		return m
+	}
+
+	t := [3]float64{0, 0, 0}
+	if len(n.Translation) == 3 {
+		copy(t[:], n.Translation)
+	}
+
+	// rotation is a unit quaternion x, y, z, w
+	q := [4]float64{0, 0, 0, 1}
+	if len(n.Rotation) == 4 {
+		copy(q[:], n.Rotation)
+	}
+
+	s := [3]float64{1, 1, 1}
+	if len(n.Scale) == 3 {
+		copy(s[:], n.Scale)
+	}
+
+	x, y, z, w := q[0], q[1], q[2], q[3]
+
+	return matrix{
+		(1 - 2*(y*y+z*z)) * s[0], (2 * (x*y + z*w)) * s[0], (2 * (x*z - y*w)) * s[0], 0,
+		(2 * (x*y - z*w)) * s[1], (1 - 2*(x*x+z*z)) * s[1], (2 * (y*z + x*w)) * s[1], 0,
+		(2 * (x*z + y*w)) * s[2], (2 * (y*z - x*w)) * s[2], (1 - 2*(x*x+y*y)) * s[2], 0,
+		t[0], t[1], t[2], 1,
+	}
+}
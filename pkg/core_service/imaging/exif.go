@@ -0,0 +1,223 @@
+package imaging
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// JPEG markers, see https://www.w3.org/Graphics/JPEG/itu-t81.pdf.
+const (
+	markerSOI  = 0xd8
+	markerSOS  = 0xda
+	markerEOI  = 0xd9
+	markerAPP1 = 0xe1
+	markerAPPD = 0xed
+	markerCOM  = 0xfe
+
+	tagOrientation = 0x0112
+	typeShort      = 3
+
+	// maxJPEGHeaderSize limits bytes of segments before image data kept in memory while stripping metadata.
+	maxJPEGHeaderSize = 4 << 20
+)
+
+var (
+	ErrInvalidJPEG = errors.New("invalid jpeg")
+
+	exifHeader = []byte("Exif\x00\x00")
+)
+
+// Orientation values of EXIF, 1 means image is stored upright.
+const (
+	OrientationNormal = 1
+	OrientationMax    = 8
+)
+
+// JPEGOrientation returns EXIF orientation of a JPEG image, OrientationNormal is returned when it is missing.
+func JPEGOrientation(data []byte) int {
+	orientation := OrientationNormal
+
+	_ = walkJPEGSegments(
+		bufio.NewReader(bytes.NewReader(data)), func(marker byte, payload []byte) bool {
+			if marker == markerAPP1 && bytes.HasPrefix(payload, exifHeader) {
+				orientation = parseEXIFOrientation(payload[len(exifHeader):])
+
+				return false
+			}
+
+			return true
+		},
+	)
+
+	return orientation
+}
+
+// StripJPEGMetadata returns a reader of JPEG image r without EXIF, XMP, IPTC and comment segments.
+//
+// Orientation is the only EXIF tag kept so the image is still displayed upright.
+// Color profile segments are kept as they are needed to display the image correctly.
+func StripJPEGMetadata(r io.Reader) (io.Reader, error) {
+	reader := bufio.NewReader(r)
+	header := &bytes.Buffer{}
+	orientation := OrientationNormal
+
+	header.Write([]byte{0xff, markerSOI})
+
+	err := walkJPEGSegments(
+		reader, func(marker byte, payload []byte) bool {
+			switch marker {
+			case markerAPP1:
+				if bytes.HasPrefix(payload, exifHeader) {
+					orientation = parseEXIFOrientation(payload[len(exifHeader):])
+				}
+			case markerAPPD, markerCOM:
+			default:
+				writeJPEGSegment(header, marker, payload)
+			}
+
+			return header.Len() <= maxJPEGHeaderSize
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.Len() > maxJPEGHeaderSize {
+		return nil, ErrInvalidJPEG
+	}
+
+	if orientation != OrientationNormal {
+		stripped := header.Bytes()
+		header = bytes.NewBuffer(append([]byte{}, stripped[:2]...))
+		writeJPEGSegment(header, markerAPP1, orientationEXIF(orientation))
+		header.Write(stripped[2:])
+	}
+
+	// image data starts right after start of scan segment, it is streamed as is
+	return io.MultiReader(header, reader), nil
+}
+
+// walkJPEGSegments calls fn with each segment of r until start of scan segment or fn returns false.
+//
+// The start of scan segment is also passed to fn, r is left right after it.
+func walkJPEGSegments(r *bufio.Reader, fn func(marker byte, payload []byte) bool) error {
+	var soi [2]byte
+	if _, err := io.ReadFull(r, soi[:]); err != nil || soi != [2]byte{0xff, markerSOI} {
+		return ErrInvalidJPEG
+	}
+
+	for {
+		marker, err := readJPEGMarker(r)
+		if err != nil {
+			return err
+		}
+
+		if marker == markerEOI {
+			return ErrInvalidJPEG
+		}
+
+		var size [2]byte
+		if _, err = io.ReadFull(r, size[:]); err != nil {
+			return ErrInvalidJPEG
+		}
+
+		length := int(binary.BigEndian.Uint16(size[:]))
+		if length < 2 {
+			return ErrInvalidJPEG
+		}
+
+		payload := make([]byte, length-2)
+		if _, err = io.ReadFull(r, payload); err != nil {
+			return ErrInvalidJPEG
+		}
+
+		if !fn(marker, payload) || marker == markerSOS {
+			return nil
+		}
+	}
+}
+
+func readJPEGMarker(r *bufio.Reader) (byte, error) {
+	b, err := r.ReadByte()
+	if err != nil || b != 0xff {
+		return 0, ErrInvalidJPEG
+	}
+
+	// markers can be padded by any number of 0xff
+	for b == 0xff {
+		if b, err = r.ReadByte(); err != nil {
+			return 0, ErrInvalidJPEG
+		}
+	}
+
+	return b, nil
+}
+
+func writeJPEGSegment(w *bytes.Buffer, marker byte, payload []byte) {
+	w.Write([]byte{0xff, marker})
+	_ = binary.Write(w, binary.BigEndian, uint16(len(payload)+2))
+	w.Write(payload)
+}
+
+// parseEXIFOrientation returns orientation tag in IFD0 of TIFF structure of EXIF.
+func parseEXIFOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return OrientationNormal
+	}
+
+	var order binary.ByteOrder
+
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return OrientationNormal
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return OrientationNormal
+	}
+
+	count := int(order.Uint16(tiff[offset:]))
+
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+
+		if order.Uint16(tiff[entry:]) != tagOrientation || order.Uint16(tiff[entry+2:]) != typeShort {
+			continue
+		}
+
+		orientation := int(order.Uint16(tiff[entry+8:]))
+		if orientation < OrientationNormal || orientation > OrientationMax {
+			return OrientationNormal
+		}
+
+		return orientation
+	}
+
+	return OrientationNormal
+}
+
+// orientationEXIF returns payload of an EXIF segment with only orientation tag.
+func orientationEXIF(orientation int) []byte {
+	payload := append([]byte{}, exifHeader...)
+	payload = append(payload, "MM\x00\x2a\x00\x00\x00\x08"...)
+	payload = binary.BigEndian.AppendUint16(payload, 1)
+	payload = binary.BigEndian.AppendUint16(payload, tagOrientation)
+	payload = binary.BigEndian.AppendUint16(payload, typeShort)
+	payload = binary.BigEndian.AppendUint32(payload, 1)
+	payload = binary.BigEndian.AppendUint16(payload, uint16(orientation))
+	payload = binary.BigEndian.AppendUint16(payload, 0)
+
+	// no next IFD
+	return binary.BigEndian.AppendUint32(payload, 0)
+}
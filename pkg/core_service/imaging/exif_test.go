@@ -0,0 +1,88 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripJPEGMetadata(t *testing.T) {
+	var encoded bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&encoded, image.NewRGBA(image.Rect(0, 0, 3, 2)), nil))
+
+	// insert EXIF with orientation and a comment right after start of image
+	var withMetadata bytes.Buffer
+	withMetadata.Write(encoded.Bytes()[:2])
+	writeJPEGSegment(&withMetadata, markerAPP1, orientationEXIF(6))
+	writeJPEGSegment(&withMetadata, markerCOM, []byte("taken at home"))
+	withMetadata.Write(encoded.Bytes()[2:])
+
+	t.Run(
+		"read orientation", func(t *testing.T) {
+			assert.Equal(t, 6, JPEGOrientation(withMetadata.Bytes()))
+			assert.Equal(t, OrientationNormal, JPEGOrientation(encoded.Bytes()))
+			assert.Equal(t, OrientationNormal, JPEGOrientation([]byte("not a jpeg")))
+		},
+	)
+
+	t.Run(
+		"strip keeps orientation", func(t *testing.T) {
+			reader, err := StripJPEGMetadata(bytes.NewReader(withMetadata.Bytes()))
+			assert.NoError(t, err)
+
+			stripped, err := io.ReadAll(reader)
+			assert.NoError(t, err)
+			assert.NotContains(t, string(stripped), "taken at home")
+			assert.Equal(t, 6, JPEGOrientation(stripped))
+
+			_, err = jpeg.Decode(bytes.NewReader(stripped))
+			assert.NoError(t, err)
+		},
+	)
+
+	t.Run(
+		"invalid jpeg", func(t *testing.T) {
+			_, err := StripJPEGMetadata(bytes.NewReader([]byte("not a jpeg")))
+			assert.ErrorIs(t, err, ErrInvalidJPEG)
+		},
+	)
+}
+
+func TestOrient(t *testing.T) {
+	// 2x1 image, red on the left and blue on the right
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+	img.SetNRGBA(1, 0, color.NRGBA{B: 255, A: 255})
+
+	red, blue := color.NRGBA{R: 255, A: 255}, color.NRGBA{B: 255, A: 255}
+
+	t.Run(
+		"flip horizontal", func(t *testing.T) {
+			dst := Orient(img, 2)
+			assert.Equal(t, blue, dst.NRGBAAt(0, 0))
+			assert.Equal(t, red, dst.NRGBAAt(1, 0))
+		},
+	)
+
+	t.Run(
+		"rotate clockwise", func(t *testing.T) {
+			dst := Orient(img, 6)
+			assert.Equal(t, image.Rect(0, 0, 1, 2), dst.Bounds())
+			assert.Equal(t, red, dst.NRGBAAt(0, 0))
+			assert.Equal(t, blue, dst.NRGBAAt(0, 1))
+		},
+	)
+
+	t.Run(
+		"rotate counterclockwise", func(t *testing.T) {
+			dst := Orient(img, 8)
+			assert.Equal(t, blue, dst.NRGBAAt(0, 0))
+			assert.Equal(t, red, dst.NRGBAAt(0, 1))
+		},
+	)
+}
@@ -0,0 +1,167 @@
+package imaging
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// huffmanCode define a canonical prefix code, codes are stored bit-reversed as VP8L writes them LSB first.
+type huffmanCode struct {
+	lengths []uint32
+	codes   []uint32
+}
+
+// newHuffmanCode builds a canonical prefix code of histo with code lengths at most maxLength.
+//
+// A dummy symbol is added when histo has less than two symbols so the code is always a complete tree.
+func newHuffmanCode(histo []uint32, maxLength uint32) huffmanCode {
+	lengths := huffmanCodeLengths(histo, maxLength)
+
+	return huffmanCode{
+		lengths: lengths,
+		codes:   canonicalCodes(lengths),
+	}
+}
+
+type huffmanNode struct {
+	count  uint32
+	symbol int
+	left   *huffmanNode
+	right  *huffmanNode
+}
+
+type huffmanHeap []*huffmanNode
+
+func (h huffmanHeap) Len() int { return len(h) }
+
+func (h huffmanHeap) Less(i, j int) bool {
+	if h[i].count != h[j].count {
+		return h[i].count < h[j].count
+	}
+
+	return h[i].symbol < h[j].symbol
+}
+
+func (h huffmanHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *huffmanHeap) Push(x any) { *h = append(*h, x.(*huffmanNode)) }
+
+func (h *huffmanHeap) Pop() any {
+	old := *h
+	node := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return node
+}
+
+// huffmanCodeLengths returns Huffman code lengths of histo limited to maxLength.
+//
+// When the tree is too deep, small counts are raised and the tree is rebuilt, same as libwebp does.
+func huffmanCodeLengths(histo []uint32, maxLength uint32) []uint32 {
+	counts := make([]uint32, len(histo))
+	copy(counts, histo)
+
+	used := 0
+	for _, count := range counts {
+		if count > 0 {
+			used++
+		}
+	}
+
+	for symbol := 0; used < 2 && symbol < len(counts); symbol++ {
+		if counts[symbol] == 0 {
+			counts[symbol] = 1
+			used++
+		}
+	}
+
+	lengths := make([]uint32, len(counts))
+
+	for countMin := uint32(1); ; countMin *= 2 {
+		h := make(huffmanHeap, 0, used)
+
+		for symbol, count := range counts {
+			if count == 0 {
+				continue
+			}
+
+			if count < countMin {
+				count = countMin
+			}
+
+			h = append(h, &huffmanNode{count: count, symbol: symbol})
+		}
+
+		heap.Init(&h)
+
+		for nextSymbol := len(counts); h.Len() > 1; nextSymbol++ {
+			left := heap.Pop(&h).(*huffmanNode)
+			right := heap.Pop(&h).(*huffmanNode)
+			heap.Push(&h, &huffmanNode{count: left.count + right.count, symbol: nextSymbol, left: left, right: right})
+		}
+
+		for i := range lengths {
+			lengths[i] = 0
+		}
+
+		if assignLengths(h[0], 0, lengths) <= maxLength {
+			return lengths
+		}
+	}
+}
+
+// assignLengths sets depth of leaves under node as their code length and returns max depth.
+func assignLengths(node *huffmanNode, depth uint32, lengths []uint32) uint32 {
+	if node.left == nil {
+		lengths[node.symbol] = depth
+
+		return depth
+	}
+
+	left := assignLengths(node.left, depth+1, lengths)
+	right := assignLengths(node.right, depth+1, lengths)
+
+	if left > right {
+		return left
+	}
+
+	return right
+}
+
+// canonicalCodes assigns canonical codes to lengths, codes are returned bit-reversed.
+func canonicalCodes(lengths []uint32) []uint32 {
+	symbols := make([]int, 0, len(lengths))
+
+	for symbol, length := range lengths {
+		if length > 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	sort.SliceStable(symbols, func(i, j int) bool { return lengths[symbols[i]] < lengths[symbols[j]] })
+
+	codes := make([]uint32, len(lengths))
+	code, prevLength := uint32(0), uint32(0)
+
+	for i, symbol := range symbols {
+		length := lengths[symbol]
+		if i > 0 {
+			code = (code + 1) << (length - prevLength)
+		}
+
+		codes[symbol] = reverseBits(code, length)
+		prevLength = length
+	}
+
+	return codes
+}
+
+func reverseBits(code, length uint32) uint32 {
+	var res uint32
+
+	for i := uint32(0); i < length; i++ {
+		res = res<<1 | code>>i&1
+	}
+
+	return res
+}
@@ -0,0 +1,115 @@
+package imaging
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+
+	"golang.org/x/image/draw"
+)
+
+// Output formats of Encode.
+const (
+	FormatWebP = "webp"
+	FormatJPEG = "jpeg"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+// ContentType returns content type of format.
+func ContentType(format string) string {
+	return "image/" + format
+}
+
+// Extension returns file extension of format.
+func Extension(format string) string {
+	if format == FormatJPEG {
+		return ".jpg"
+	}
+
+	return "." + format
+}
+
+// Orient returns img transformed to be displayed upright according to its EXIF orientation.
+func Orient(img image.Image, orientation int) *image.NRGBA {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	// orientations from 5 to 8 swap width and height
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 && orientation <= OrientationMax {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < dstHeight; y++ {
+		for x := 0; x < dstWidth; x++ {
+			srcX, srcY := x, y
+
+			switch orientation {
+			case 2:
+				srcX = width - 1 - x
+			case 3:
+				srcX, srcY = width-1-x, height-1-y
+			case 4:
+				srcY = height - 1 - y
+			case 5:
+				srcX, srcY = y, x
+			case 6:
+				srcX, srcY = y, height-1-x
+			case 7:
+				srcX, srcY = width-1-y, height-1-x
+			case 8:
+				srcX, srcY = width-1-y, x
+			}
+
+			dst.Set(x, y, img.At(bounds.Min.X+srcX, bounds.Min.Y+srcY))
+		}
+	}
+
+	return dst
+}
+
+// Resize returns img scaled to width, height is scaled to keep aspect ratio.
+func Resize(img image.Image, width int) *image.NRGBA {
+	bounds := img.Bounds()
+
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+	return dst
+}
+
+// Encode writes img to w in format, quality only applies to JPEG.
+//
+// JPEG has no transparency so transparent pixels are drawn on white background.
+func Encode(w io.Writer, img image.Image, format string, quality int) error {
+	switch format {
+	case FormatWebP:
+		return EncodeWebP(w, img)
+	case FormatJPEG:
+		return jpeg.Encode(w, flatten(img), &jpeg.Options{Quality: quality})
+	default:
+		return ErrUnsupportedFormat
+	}
+}
+
+func flatten(img image.Image) image.Image {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+		return img
+	}
+
+	dst := image.NewRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	return dst
+}
@@ -0,0 +1,516 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+)
+
+// VP8L bitstream constants, see https://developers.google.com/speed/webp/docs/webp_lossless_bitstream_specification.
+const (
+	vp8lSignature          = 0x2f
+	vp8lMaxDimension       = 1 << 14
+	vp8lPredictorBits      = 4
+	transformPredictor     = 0
+	transformSubtractGreen = 2
+
+	numLiteralCodes  = 256
+	numLengthCodes   = 24
+	numDistanceCodes = 40
+
+	maxCodeLength           = 15
+	maxCodeLengthCodeLength = 7
+	numCodeLengthCodes      = 19
+)
+
+var (
+	ErrInvalidDimension = errors.New("image dimension is not supported")
+
+	codeLengthCodeOrder = [numCodeLengthCodes]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+	// predictorModes specific modes tried for each tile, the one with smallest residuals is used.
+	predictorModes = []uint32{predictorL, predictorT, predictorAverageLT, predictorSelect, predictorClampAddSubtract}
+)
+
+const (
+	predictorL                = 1
+	predictorT                = 2
+	predictorAverageLT        = 7
+	predictorSelect           = 11
+	predictorClampAddSubtract = 12
+)
+
+// EncodeWebP writes img to w as lossless WebP.
+//
+// The encoder only uses subtract green and predictor transforms without backward references,
+// it trades compression ratio for simplicity while staying pure Go.
+func EncodeWebP(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width < 1 || height < 1 || width > vp8lMaxDimension || height > vp8lMaxDimension {
+		return ErrInvalidDimension
+	}
+
+	argb, alphaUsed := toARGB(img)
+
+	bw := &bitWriter{}
+	bw.writeBits(vp8lSignature, 8)
+	bw.writeBits(uint32(width-1), 14)
+	bw.writeBits(uint32(height-1), 14)
+	bw.writeBits(boolToBit(alphaUsed), 1)
+	bw.writeBits(0, 3)
+
+	// transforms are applied by encoder in the order they are written
+	bw.writeBits(1, 1)
+	bw.writeBits(transformSubtractGreen, 2)
+	subtractGreen(argb)
+
+	bw.writeBits(1, 1)
+	bw.writeBits(transformPredictor, 2)
+	bw.writeBits(vp8lPredictorBits-2, 3)
+
+	residuals, modes, tilesX, tilesY := predict(argb, width, height)
+	writeEntropyCodedImage(bw, modes, tilesX, tilesY, false)
+
+	bw.writeBits(0, 1)
+	writeEntropyCodedImage(bw, residuals, width, height, true)
+
+	data := bw.bytes()
+	chunkSize := len(data)
+	padding := chunkSize & 1
+
+	header := make([]byte, 0, 20)
+	header = append(header, "RIFF"...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(4+8+chunkSize+padding))
+	header = append(header, "WEBPVP8L"...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(chunkSize))
+
+	if padding == 1 {
+		data = append(data, 0)
+	}
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	_, err := w.Write(data)
+
+	return err
+}
+
+// toARGB returns pixels of img packed as 0xAARRGGBB, non-premultiplied.
+func toARGB(img image.Image) ([]uint32, bool) {
+	bounds := img.Bounds()
+	argb := make([]uint32, 0, bounds.Dx()*bounds.Dy())
+	alphaUsed := false
+
+	nrgba, isNRGBA := img.(*image.NRGBA)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			var c color.NRGBA
+			if isNRGBA {
+				c = nrgba.NRGBAAt(x, y)
+			} else {
+				c, _ = color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			}
+
+			if c.A != 0xff {
+				alphaUsed = true
+			}
+
+			argb = append(argb, uint32(c.A)<<24|uint32(c.R)<<16|uint32(c.G)<<8|uint32(c.B))
+		}
+	}
+
+	return argb, alphaUsed
+}
+
+func subtractGreen(argb []uint32) {
+	for i, p := range argb {
+		green := p >> 8 & 0xff
+		red := (p>>16 - green) & 0xff
+		blue := (p - green) & 0xff
+		argb[i] = p&0xff00ff00 | red<<16 | blue
+	}
+}
+
+// predict returns residuals of argb with the predictor mode of each tile.
+func predict(argb []uint32, width, height int) ([]uint32, []uint32, int, int) {
+	tileSize := 1 << vp8lPredictorBits
+	tilesX := (width + tileSize - 1) >> vp8lPredictorBits
+	tilesY := (height + tileSize - 1) >> vp8lPredictorBits
+	modes := make([]uint32, tilesX*tilesY)
+	residuals := make([]uint32, len(argb))
+
+	for ty := 0; ty < tilesY; ty++ {
+		for tx := 0; tx < tilesX; tx++ {
+			bestMode, bestCost := uint32(predictorL), -1
+
+			for _, mode := range predictorModes {
+				cost := 0
+
+				forEachTilePixel(tx, ty, width, height, func(idx int) {
+					cost += residualCost(sub(argb[idx], predictPixel(argb, idx, width, mode)))
+				})
+
+				if bestCost < 0 || cost < bestCost {
+					bestMode, bestCost = mode, cost
+				}
+			}
+
+			// predictor mode is kept in green channel
+			modes[ty*tilesX+tx] = 0xff000000 | bestMode<<8
+
+			forEachTilePixel(tx, ty, width, height, func(idx int) {
+				residuals[idx] = sub(argb[idx], predictPixel(argb, idx, width, bestMode))
+			})
+		}
+	}
+
+	return residuals, modes, tilesX, tilesY
+}
+
+func forEachTilePixel(tx, ty, width, height int, fn func(idx int)) {
+	tileSize := 1 << vp8lPredictorBits
+
+	for y := ty * tileSize; y < (ty+1)*tileSize && y < height; y++ {
+		for x := tx * tileSize; x < (tx+1)*tileSize && x < width; x++ {
+			fn(y*width + x)
+		}
+	}
+}
+
+// predictPixel returns prediction of pixel at idx, borders always use fixed modes defined by the spec.
+func predictPixel(argb []uint32, idx, width int, mode uint32) uint32 {
+	x, y := idx%width, idx/width
+
+	switch {
+	case x == 0 && y == 0:
+		return 0xff000000
+	case y == 0:
+		return argb[idx-1]
+	case x == 0:
+		return argb[idx-width]
+	}
+
+	left, top, topLeft := argb[idx-1], argb[idx-width], argb[idx-width-1]
+
+	switch mode {
+	case predictorT:
+		return top
+	case predictorAverageLT:
+		return average2(left, top)
+	case predictorSelect:
+		return selectPredictor(left, top, topLeft)
+	case predictorClampAddSubtract:
+		return clampAddSubtractFull(left, top, topLeft)
+	default:
+		return left
+	}
+}
+
+func channel(p uint32, shift uint) int {
+	return int(p >> shift & 0xff)
+}
+
+func average2(a, b uint32) uint32 {
+	return (((a ^ b) & 0xfefefefe) >> 1) + (a & b)
+}
+
+func selectPredictor(left, top, topLeft uint32) uint32 {
+	distLeft, distTop := 0, 0
+
+	for shift := uint(0); shift < 32; shift += 8 {
+		distLeft += abs(channel(top, shift) - channel(topLeft, shift))
+		distTop += abs(channel(left, shift) - channel(topLeft, shift))
+	}
+
+	if distLeft < distTop {
+		return left
+	}
+
+	return top
+}
+
+func clampAddSubtractFull(a, b, c uint32) uint32 {
+	var res uint32
+
+	for shift := uint(0); shift < 32; shift += 8 {
+		v := channel(a, shift) + channel(b, shift) - channel(c, shift)
+		if v < 0 {
+			v = 0
+		} else if v > 0xff {
+			v = 0xff
+		}
+
+		res |= uint32(v) << shift
+	}
+
+	return res
+}
+
+// sub subtracts b from a per channel modulo 256.
+func sub(a, b uint32) uint32 {
+	var res uint32
+
+	for shift := uint(0); shift < 32; shift += 8 {
+		res |= uint32((channel(a, shift)-channel(b, shift))&0xff) << shift
+	}
+
+	return res
+}
+
+// residualCost estimates cost of encoding residual by magnitude of its signed channels.
+func residualCost(residual uint32) int {
+	cost := 0
+
+	for shift := uint(0); shift < 32; shift += 8 {
+		cost += abs(int(int8(channel(residual, shift))))
+	}
+
+	return cost
+}
+
+// writeEntropyCodedImage writes pixels using a single group of prefix codes without backward references.
+func writeEntropyCodedImage(bw *bitWriter, argb []uint32, width, height int, topLevel bool) {
+	// no color cache
+	bw.writeBits(0, 1)
+
+	if topLevel {
+		// no meta prefix codes
+		bw.writeBits(0, 1)
+	}
+
+	green := make([]uint32, numLiteralCodes+numLengthCodes)
+	red := make([]uint32, numLiteralCodes)
+	blue := make([]uint32, numLiteralCodes)
+	alpha := make([]uint32, numLiteralCodes)
+	distance := make([]uint32, numDistanceCodes)
+
+	for _, p := range argb[:width*height] {
+		green[p>>8&0xff]++
+		red[p>>16&0xff]++
+		blue[p&0xff]++
+		alpha[p>>24]++
+	}
+
+	codes := [5]huffmanCode{
+		writeHuffmanCode(bw, green),
+		writeHuffmanCode(bw, red),
+		writeHuffmanCode(bw, blue),
+		writeHuffmanCode(bw, alpha),
+		writeHuffmanCode(bw, distance),
+	}
+
+	for _, p := range argb[:width*height] {
+		bw.writeCode(codes[0], p>>8&0xff)
+		bw.writeCode(codes[1], p>>16&0xff)
+		bw.writeCode(codes[2], p&0xff)
+		bw.writeCode(codes[3], p>>24)
+	}
+}
+
+// writeHuffmanCode writes prefix code of histo and returns it.
+func writeHuffmanCode(bw *bitWriter, histo []uint32) huffmanCode {
+	var symbols []uint32
+
+	for symbol, count := range histo {
+		if count > 0 {
+			symbols = append(symbols, uint32(symbol))
+		}
+	}
+
+	// unused alphabet is written as a single symbol which takes no bits
+	if len(symbols) == 0 {
+		symbols = append(symbols, 0)
+	}
+
+	if len(symbols) <= 2 && symbols[len(symbols)-1] < numLiteralCodes {
+		return writeSimpleHuffmanCode(bw, symbols, len(histo))
+	}
+
+	code := newHuffmanCode(histo, maxCodeLength)
+
+	bw.writeBits(0, 1)
+	writeCodeLengths(bw, code.lengths)
+
+	return code
+}
+
+// writeSimpleHuffmanCode writes a code of one or two symbols, one symbol takes no bits and two take one bit each.
+func writeSimpleHuffmanCode(bw *bitWriter, symbols []uint32, alphabetSize int) huffmanCode {
+	bw.writeBits(1, 1)
+	bw.writeBits(uint32(len(symbols)-1), 1)
+
+	if symbols[0] < 2 {
+		bw.writeBits(0, 1)
+		bw.writeBits(symbols[0], 1)
+	} else {
+		bw.writeBits(1, 1)
+		bw.writeBits(symbols[0], 8)
+	}
+
+	code := huffmanCode{
+		lengths: make([]uint32, alphabetSize),
+		codes:   make([]uint32, alphabetSize),
+	}
+
+	if len(symbols) == 2 {
+		bw.writeBits(symbols[1], 8)
+		code.lengths[symbols[0]], code.lengths[symbols[1]] = 1, 1
+		code.codes[symbols[1]] = 1
+	}
+
+	return code
+}
+
+type codeLengthToken struct {
+	symbol    uint32
+	extraBits uint
+	extra     uint32
+}
+
+// writeCodeLengths writes code lengths of a normal prefix code, runs are compressed by repeat codes 16, 17 and 18.
+func writeCodeLengths(bw *bitWriter, lengths []uint32) {
+	tokens := codeLengthTokens(lengths)
+
+	histo := make([]uint32, numCodeLengthCodes)
+	for _, token := range tokens {
+		histo[token.symbol]++
+	}
+
+	code := newHuffmanCode(histo, maxCodeLengthCodeLength)
+
+	numCodes := numCodeLengthCodes
+	for numCodes > 4 && code.lengths[codeLengthCodeOrder[numCodes-1]] == 0 {
+		numCodes--
+	}
+
+	bw.writeBits(uint32(numCodes-4), 4)
+
+	for _, symbol := range codeLengthCodeOrder[:numCodes] {
+		bw.writeBits(code.lengths[symbol], 3)
+	}
+
+	// all code lengths are written, max_symbol is not used
+	bw.writeBits(0, 1)
+
+	for _, token := range tokens {
+		bw.writeCode(code, token.symbol)
+		bw.writeBits(token.extra, token.extraBits)
+	}
+}
+
+func codeLengthTokens(lengths []uint32) []codeLengthToken {
+	var tokens []codeLengthToken
+
+	// code 16 repeats previous non-zero length, which is 8 before any is written
+	prev := uint32(8)
+
+	for i := 0; i < len(lengths); {
+		length := lengths[i]
+
+		run := 1
+		for i+run < len(lengths) && lengths[i+run] == length {
+			run++
+		}
+
+		i += run
+
+		if length == 0 {
+			for run >= 11 {
+				repeat := minInt(run, 138)
+				tokens = append(tokens, codeLengthToken{symbol: 18, extraBits: 7, extra: uint32(repeat - 11)})
+				run -= repeat
+			}
+
+			if run >= 3 {
+				tokens = append(tokens, codeLengthToken{symbol: 17, extraBits: 3, extra: uint32(run - 3)})
+				run = 0
+			}
+
+			for ; run > 0; run-- {
+				tokens = append(tokens, codeLengthToken{symbol: 0})
+			}
+
+			continue
+		}
+
+		if length != prev {
+			tokens = append(tokens, codeLengthToken{symbol: length})
+			prev = length
+			run--
+		}
+
+		for run >= 3 {
+			repeat := minInt(run, 6)
+			tokens = append(tokens, codeLengthToken{symbol: 16, extraBits: 2, extra: uint32(repeat - 3)})
+			run -= repeat
+		}
+
+		for ; run > 0; run-- {
+			tokens = append(tokens, codeLengthToken{symbol: length})
+		}
+	}
+
+	return tokens
+}
+
+// bitWriter packs bits LSB first as VP8L requires.
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nBits uint
+}
+
+func (w *bitWriter) writeBits(value uint32, n uint) {
+	w.acc |= uint64(value) << w.nBits
+	w.nBits += n
+
+	for w.nBits >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.nBits -= 8
+	}
+}
+
+func (w *bitWriter) writeCode(code huffmanCode, symbol uint32) {
+	w.writeBits(code.codes[symbol], uint(code.lengths[symbol]))
+}
+
+func (w *bitWriter) bytes() []byte {
+	if w.nBits > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.nBits = 0, 0
+	}
+
+	return w.buf
+}
+
+func boolToBit(b bool) uint32 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
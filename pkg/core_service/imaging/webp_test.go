@@ -0,0 +1,90 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/webp"
+)
+
+func TestEncodeWebP(t *testing.T) {
+	roundTrip := func(t *testing.T, img *image.NRGBA) {
+		var buf bytes.Buffer
+		assert.NoError(t, EncodeWebP(&buf, img))
+
+		decoded, err := webp.Decode(&buf)
+		assert.NoError(t, err)
+		assert.Equal(t, img.Bounds(), decoded.Bounds())
+
+		for y := 0; y < img.Bounds().Dy(); y++ {
+			for x := 0; x < img.Bounds().Dx(); x++ {
+				want := img.NRGBAAt(x, y)
+				got := color.NRGBAModel.Convert(decoded.At(x, y)).(color.NRGBA)
+
+				// fully transparent pixels may lose their color when decoded as premultiplied
+				if want.A == 0 {
+					assert.Equal(t, uint8(0), got.A)
+
+					continue
+				}
+
+				if !assert.Equal(t, want, got, "pixel (%d, %d)", x, y) {
+					return
+				}
+			}
+		}
+	}
+
+	t.Run(
+		"single color", func(t *testing.T) {
+			img := image.NewNRGBA(image.Rect(0, 0, 7, 5))
+			for i := 0; i < len(img.Pix); i += 4 {
+				copy(img.Pix[i:], []byte{10, 200, 30, 255})
+			}
+
+			roundTrip(t, img)
+		},
+	)
+
+	t.Run(
+		"gradient", func(t *testing.T) {
+			img := image.NewNRGBA(image.Rect(0, 0, 67, 41))
+			for y := 0; y < 41; y++ {
+				for x := 0; x < 67; x++ {
+					img.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 3), G: uint8(y * 5), B: uint8(x + y), A: 255})
+				}
+			}
+
+			roundTrip(t, img)
+		},
+	)
+
+	t.Run(
+		"noise with alpha", func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(1))
+			img := image.NewNRGBA(image.Rect(0, 0, 50, 33))
+			rnd.Read(img.Pix)
+
+			roundTrip(t, img)
+		},
+	)
+
+	t.Run(
+		"single pixel", func(t *testing.T) {
+			img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+			img.SetNRGBA(0, 0, color.NRGBA{R: 1, G: 2, B: 3, A: 255})
+
+			roundTrip(t, img)
+		},
+	)
+
+	t.Run(
+		"empty image", func(t *testing.T) {
+			assert.ErrorIs(t, EncodeWebP(&bytes.Buffer{}, image.NewNRGBA(image.Rect(0, 0, 0, 0))), ErrInvalidDimension)
+		},
+	)
+}
@@ -0,0 +1,164 @@
+package objectstorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"cloud.google.com/go/storage"
+	fbstorage "firebase.google.com/go/storage"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	gcsPublicHost     = "https://storage.googleapis.com"
+	gcsPublicReadACL  = "publicRead"
+	gcsPrivateReadACL = "private"
+)
+
+// GCSStorage stores objects in a Google Cloud Storage bucket managed by Firebase.
+type GCSStorage struct {
+	bucket     *storage.BucketHandle
+	bucketName string
+}
+
+// NewGCSStorage returns storage of bucketName.
+func NewGCSStorage(client *fbstorage.Client, bucketName string) (*GCSStorage, error) {
+	if client == nil {
+		return nil, errors.New("firebase storage client is required")
+	}
+
+	bucket, err := client.Bucket(bucketName)
+	if err != nil {
+		return nil, fmt.Errorf("client.Bucket(): %w", err)
+	}
+
+	return &GCSStorage{bucket: bucket, bucketName: bucketName}, nil
+}
+
+func (s *GCSStorage) Put(ctx context.Context, key string, r io.Reader, opts PutOptions) (*ObjectInfo, error) {
+	if key == "" {
+		return nil, ErrInvalidKey
+	}
+
+	// cancelling context is the only way to abort a write without committing partial object
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// never overwrite existing objects
+	obj := s.bucket.Object(key).If(storage.Conditions{DoesNotExist: true})
+
+	wc := obj.NewWriter(ctx)
+	wc.ContentType = opts.ContentType
+	wc.PredefinedACL = gcsPrivateReadACL
+	if opts.Public {
+		wc.PredefinedACL = gcsPublicReadACL
+	}
+
+	if _, err := io.Copy(wc, r); err != nil {
+		cancel()
+		_ = wc.Close()
+
+		return nil, fmt.Errorf("io.Copy(): %w", err)
+	}
+
+	if err := wc.Close(); err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed {
+			return nil, ErrObjectExists
+		}
+
+		return nil, fmt.Errorf("wc.Close(): %w", err)
+	}
+
+	return toObjectInfo(wc.Attrs()), nil
+}
+
+func (s *GCSStorage) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	reader, err := s.bucket.Object(key).NewReader(ctx)
+	if err != nil {
+		return nil, nil, convertGCSError(err)
+	}
+
+	return reader, &ObjectInfo{
+		Key:         key,
+		Size:        reader.Attrs.Size,
+		ContentType: reader.Attrs.ContentType,
+		UpdatedAt:   reader.Attrs.LastModified,
+	}, nil
+}
+
+func (s *GCSStorage) Delete(ctx context.Context, key string) error {
+	return convertGCSError(s.bucket.Object(key).Delete(ctx))
+}
+
+func (s *GCSStorage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	attrs, err := s.bucket.Object(key).Attrs(ctx)
+	if err != nil {
+		return nil, convertGCSError(err)
+	}
+
+	return toObjectInfo(attrs), nil
+}
+
+func (s *GCSStorage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var res []ObjectInfo
+
+	it := s.bucket.Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("it.Next(): %w", err)
+		}
+
+		res = append(res, *toObjectInfo(attrs))
+	}
+
+	return res, nil
+}
+
+func (s *GCSStorage) URL(key string) string {
+	return gcsPublicHost + "/" + s.bucketName + "/" + (&url.URL{Path: key}).EscapedPath()
+}
+
+func (s *GCSStorage) SignedURL(key string, expires time.Time) (string, error) {
+	signedURL, err := s.bucket.SignedURL(
+		key,
+		&storage.SignedURLOptions{
+			Scheme:  storage.SigningSchemeV4,
+			Method:  http.MethodGet,
+			Expires: expires,
+		},
+	)
+	if err != nil {
+		return "", fmt.Errorf("bucket.SignedURL(): %w", err)
+	}
+
+	return signedURL, nil
+}
+
+func toObjectInfo(attrs *storage.ObjectAttrs) *ObjectInfo {
+	return &ObjectInfo{
+		Key:         attrs.Name,
+		Size:        attrs.Size,
+		ContentType: attrs.ContentType,
+		UpdatedAt:   attrs.Updated,
+	}
+}
+
+func convertGCSError(err error) error {
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return ErrObjectNotFound
+	}
+
+	return err
+}
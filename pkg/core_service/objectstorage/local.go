@@ -0,0 +1,385 @@
+package objectstorage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	localDirPerm       = 0o755
+	localFilePerm      = 0o644
+	localTempPrefix    = ".upload-"
+	localPrivateSuffix = ".private"
+	sniffLen           = 512
+	signingKeyLen      = 32
+
+	// query params of signed URL.
+	expiresParam   = "expires"
+	signatureParam = "signature"
+)
+
+// LocalStorage stores objects in a directory of local filesystem.
+//
+// It is intended for development and tests, objects are served by the HTTP server under path of baseURL.
+// Private objects are marked by a hidden file next to them and are only served by HMAC signed URL.
+type LocalStorage struct {
+	rootDir    string
+	baseURL    string
+	signingKey []byte
+}
+
+// NewLocalStorage returns storage keeping objects under rootDir.
+//
+// baseURL specific public URL objects are served at, e.g. http://localhost:8080/files.
+// signingKey specific secret of signed URLs, a random one is generated if empty.
+func NewLocalStorage(rootDir, baseURL, signingKey string) (*LocalStorage, error) {
+	if err := os.MkdirAll(rootDir, localDirPerm); err != nil {
+		return nil, fmt.Errorf("os.MkdirAll(): %w", err)
+	}
+
+	key := []byte(signingKey)
+	if len(key) == 0 {
+		key = make([]byte, signingKeyLen)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("rand.Read(): %w", err)
+		}
+	}
+
+	return &LocalStorage{
+		rootDir:    rootDir,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		signingKey: key,
+	}, nil
+}
+
+func (s *LocalStorage) Put(_ context.Context, key string, r io.Reader, opts PutOptions) (*ObjectInfo, error) {
+	filePath, err := s.filePath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(filePath), localDirPerm); err != nil {
+		return nil, fmt.Errorf("os.MkdirAll(): %w", err)
+	}
+
+	// write to a temp file first so readers never see a partial object
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), localTempPrefix+"*")
+	if err != nil {
+		return nil, fmt.Errorf("os.CreateTemp(): %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+
+		return nil, fmt.Errorf("io.Copy(): %w", err)
+	}
+
+	if err = tmp.Close(); err != nil {
+		return nil, fmt.Errorf("tmp.Close(): %w", err)
+	}
+
+	// mark object private before it becomes visible
+	markerCreated := false
+	if !opts.Public {
+		if markerCreated, err = createPrivateMarker(filePath); err != nil {
+			return nil, err
+		}
+	}
+
+	// link fails when target exists, which keeps object immutable like DoesNotExist condition of GCS
+	if err = os.Link(tmp.Name(), filePath); err != nil {
+		if markerCreated {
+			_ = os.Remove(privateMarkerPath(filePath))
+		}
+
+		if errors.Is(err, fs.ErrExist) {
+			return nil, ErrObjectExists
+		}
+
+		return nil, fmt.Errorf("os.Link(): %w", err)
+	}
+
+	info, err := s.Stat(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.ContentType != "" {
+		info.ContentType = opts.ContentType
+	}
+
+	return info, nil
+}
+
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	info, err := s.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filePath, _ := s.filePath(key)
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, convertFSError(err)
+	}
+
+	return file, info, nil
+}
+
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	filePath, err := s.filePath(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(filePath); err != nil {
+		return convertFSError(err)
+	}
+
+	if err = os.Remove(privateMarkerPath(filePath)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("os.Remove(): %w", err)
+	}
+
+	return nil
+}
+
+func (s *LocalStorage) Stat(_ context.Context, key string) (*ObjectInfo, error) {
+	filePath, err := s.filePath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(filePath)
+	if err != nil {
+		return nil, convertFSError(err)
+	}
+
+	if stat.IsDir() {
+		return nil, ErrObjectNotFound
+	}
+
+	return &ObjectInfo{
+		Key:         key,
+		Size:        stat.Size(),
+		ContentType: detectContentType(filePath),
+		UpdatedAt:   stat.ModTime(),
+	}, nil
+}
+
+func (s *LocalStorage) List(_ context.Context, prefix string) ([]ObjectInfo, error) {
+	var res []ObjectInfo
+
+	err := filepath.WalkDir(s.rootDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// hidden files are temp files and private markers
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.rootDir, filePath)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		stat, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		res = append(res, ObjectInfo{
+			Key:         key,
+			Size:        stat.Size(),
+			ContentType: detectContentType(filePath),
+			UpdatedAt:   stat.ModTime(),
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("filepath.WalkDir(): %w", err)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Key < res[j].Key })
+
+	return res, nil
+}
+
+func (s *LocalStorage) URL(key string) string {
+	return s.baseURL + "/" + (&url.URL{Path: key}).EscapedPath()
+}
+
+// SignedURL returns URL of key with expiry and HMAC signature in query.
+func (s *LocalStorage) SignedURL(key string, expires time.Time) (string, error) {
+	if _, err := s.filePath(key); err != nil {
+		return "", err
+	}
+
+	expiresAt := strconv.FormatInt(expires.Unix(), 10)
+	query := url.Values{
+		expiresParam:   []string{expiresAt},
+		signatureParam: []string{s.sign(key, expiresAt)},
+	}
+
+	return s.URL(key) + "?" + query.Encode(), nil
+}
+
+// VerifySignedURL checks signature and expiry in query of a signed URL of key.
+func (s *LocalStorage) VerifySignedURL(key string, query url.Values) error {
+	expiresAt := query.Get(expiresParam)
+
+	expires, err := strconv.ParseInt(expiresAt, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return ErrInvalidSigning
+	}
+
+	signature := query.Get(signatureParam)
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, expiresAt))) {
+		return ErrInvalidSigning
+	}
+
+	return nil
+}
+
+// RoutePath returns path of HTTP server objects are served at.
+func (s *LocalStorage) RoutePath() string {
+	baseURL, err := url.Parse(s.baseURL)
+	if err != nil || baseURL.Path == "" {
+		return "/"
+	}
+
+	return baseURL.Path
+}
+
+// ServeObject is gin handler serving object by `key` wildcard param.
+//
+// Private objects require a valid signed URL.
+func (s *LocalStorage) ServeObject(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	filePath, err := s.filePath(key)
+	if err != nil {
+		c.Status(http.StatusNotFound)
+
+		return
+	}
+
+	if _, err = os.Stat(privateMarkerPath(filePath)); err == nil {
+		if s.VerifySignedURL(key, c.Request.URL.Query()) != nil {
+			c.Status(http.StatusForbidden)
+
+			return
+		}
+	}
+
+	reader, info, err := s.Get(c, key)
+	if err != nil {
+		c.Status(http.StatusNotFound)
+
+		return
+	}
+	defer reader.Close()
+
+	file, ok := reader.(*os.File)
+	if !ok {
+		c.Status(http.StatusInternalServerError)
+
+		return
+	}
+
+	c.Header("Content-Type", info.ContentType)
+	http.ServeContent(c.Writer, c.Request, path.Base(key), info.UpdatedAt, file)
+}
+
+// filePath returns location of key in filesystem, keys escaping root dir are rejected.
+func (s *LocalStorage) filePath(key string) (string, error) {
+	if key == "" || strings.HasSuffix(key, "/") {
+		return "", ErrInvalidKey
+	}
+
+	cleaned := path.Clean("/" + key)
+	if cleaned != "/"+key || strings.Contains(cleaned, "/.") {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(s.rootDir, filepath.FromSlash(cleaned)), nil
+}
+
+func (s *LocalStorage) sign(key, expiresAt string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(key + "\n" + expiresAt))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// createPrivateMarker marks object at filePath private, it returns false if object is already marked.
+func createPrivateMarker(filePath string) (bool, error) {
+	marker, err := os.OpenFile(privateMarkerPath(filePath), os.O_CREATE|os.O_EXCL|os.O_WRONLY, localFilePerm)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("os.OpenFile(): %w", err)
+	}
+
+	return true, marker.Close()
+}
+
+// privateMarkerPath returns path of hidden file marking object at filePath private.
+func privateMarkerPath(filePath string) string {
+	return filepath.Join(filepath.Dir(filePath), "."+filepath.Base(filePath)+localPrivateSuffix)
+}
+
+func detectContentType(filePath string) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(filePath)); contentType != "" {
+		return contentType
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	buf := make([]byte, sniffLen)
+	n, _ := io.ReadFull(file, buf)
+
+	return http.DetectContentType(buf[:n])
+}
+
+func convertFSError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrObjectNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,146 @@
+package objectstorage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+
+	storage, err := NewLocalStorage(t.TempDir(), "http://localhost:8080/files/", "secret")
+	assert.NoError(t, err)
+
+	t.Run(
+		"put and get", func(t *testing.T) {
+			info, err := storage.Put(ctx, "products/a.txt", strings.NewReader("hello"), PutOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, int64(5), info.Size)
+
+			reader, info, err := storage.Get(ctx, "products/a.txt")
+			assert.NoError(t, err)
+
+			defer reader.Close()
+
+			content, err := io.ReadAll(reader)
+			assert.NoError(t, err)
+			assert.Equal(t, "hello", string(content))
+			assert.Contains(t, info.ContentType, "text/plain")
+		},
+	)
+
+	t.Run(
+		"never overwrite", func(t *testing.T) {
+			_, err := storage.Put(ctx, "products/b.txt", strings.NewReader("first"), PutOptions{})
+			assert.NoError(t, err)
+
+			_, err = storage.Put(ctx, "products/b.txt", strings.NewReader("second"), PutOptions{})
+			assert.ErrorIs(t, err, ErrObjectExists)
+		},
+	)
+
+	t.Run(
+		"list by prefix", func(t *testing.T) {
+			_, err := storage.Put(ctx, "others/c.txt", strings.NewReader("c"), PutOptions{})
+			assert.NoError(t, err)
+
+			objects, err := storage.List(ctx, "products/")
+			assert.NoError(t, err)
+
+			var keys []string
+			for _, item := range objects {
+				keys = append(keys, item.Key)
+			}
+
+			assert.Equal(t, []string{"products/a.txt", "products/b.txt"}, keys)
+		},
+	)
+
+	t.Run(
+		"delete", func(t *testing.T) {
+			assert.NoError(t, storage.Delete(ctx, "others/c.txt"))
+
+			_, err := storage.Stat(ctx, "others/c.txt")
+			assert.ErrorIs(t, err, ErrObjectNotFound)
+			assert.ErrorIs(t, storage.Delete(ctx, "others/c.txt"), ErrObjectNotFound)
+		},
+	)
+
+	t.Run(
+		"reject invalid key", func(t *testing.T) {
+			for _, key := range []string{"", "../escape.txt", "a/../../b.txt", "dir/", "a/.hidden"} {
+				_, err := storage.Put(ctx, key, strings.NewReader("x"), PutOptions{})
+				assert.ErrorIs(t, err, ErrInvalidKey, key)
+			}
+		},
+	)
+
+	t.Run(
+		"url", func(t *testing.T) {
+			assert.Equal(t, "http://localhost:8080/files/products/a%20b.png", storage.URL("products/a b.png"))
+			assert.Equal(t, "/files", storage.RoutePath())
+		},
+	)
+
+	t.Run(
+		"signed url", func(t *testing.T) {
+			signedURL, err := storage.SignedURL("products/a.txt", time.Now().Add(time.Minute))
+			assert.NoError(t, err)
+
+			parsed, err := url.Parse(signedURL)
+			assert.NoError(t, err)
+			assert.Equal(t, "/files/products/a.txt", parsed.Path)
+			assert.NoError(t, storage.VerifySignedURL("products/a.txt", parsed.Query()))
+			assert.ErrorIs(t, storage.VerifySignedURL("products/b.txt", parsed.Query()), ErrInvalidSigning)
+
+			expiredURL, err := storage.SignedURL("products/a.txt", time.Now().Add(-time.Minute))
+			assert.NoError(t, err)
+
+			parsed, err = url.Parse(expiredURL)
+			assert.NoError(t, err)
+			assert.ErrorIs(t, storage.VerifySignedURL("products/a.txt", parsed.Query()), ErrInvalidSigning)
+		},
+	)
+
+	t.Run(
+		"serve private object by signed url only", func(t *testing.T) {
+			_, err := storage.Put(ctx, "private/d.txt", strings.NewReader("secret"), PutOptions{})
+			assert.NoError(t, err)
+			_, err = storage.Put(ctx, "public/e.txt", strings.NewReader("hi"), PutOptions{Public: true})
+			assert.NoError(t, err)
+
+			engine := gin.New()
+			engine.GET(storage.RoutePath()+"/*key", storage.ServeObject)
+
+			serve := func(target string) *httptest.ResponseRecorder {
+				recorder := httptest.NewRecorder()
+				engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+
+				return recorder
+			}
+
+			assert.Equal(t, http.StatusOK, serve("/files/public/e.txt").Code)
+			assert.Equal(t, http.StatusForbidden, serve("/files/private/d.txt").Code)
+
+			signedURL, err := storage.SignedURL("private/d.txt", time.Now().Add(time.Minute))
+			assert.NoError(t, err)
+
+			recorder := serve(strings.TrimPrefix(signedURL, "http://localhost:8080"))
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, "secret", recorder.Body.String())
+
+			objects, err := storage.List(ctx, "private/")
+			assert.NoError(t, err)
+			assert.Len(t, objects, 1)
+		},
+	)
+}
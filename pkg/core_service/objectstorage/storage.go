@@ -0,0 +1,75 @@
+// Package objectstorage implements object storage used to keep uploaded media.
+package objectstorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	fbstorage "firebase.google.com/go/storage"
+	config "github.com/golang/be/config/core_service"
+)
+
+const (
+	DriverFirebase = "firebase"
+	DriverLocal    = "local"
+)
+
+var (
+	ErrObjectNotFound = errors.New("object not found")
+	ErrObjectExists   = errors.New("object already exists")
+	ErrInvalidKey     = errors.New("invalid object key")
+	ErrUnknownDriver  = errors.New("unknown storage driver")
+	ErrInvalidSigning = errors.New("invalid signed url")
+)
+
+// Storage define operations on an object storage, objects are addressed by slash separated key.
+type Storage interface {
+	// Put writes content of r to key, it returns ErrObjectExists if key is taken.
+	Put(ctx context.Context, key string, r io.Reader, opts PutOptions) (*ObjectInfo, error)
+	// Get returns reader of object content, caller must close the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	// Delete removes object, it returns ErrObjectNotFound if key does not exist.
+	Delete(ctx context.Context, key string) error
+	// Stat returns object information without reading its content.
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// List returns all objects which key starts with prefix.
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+	// URL returns URL clients use to download object, private objects can only be downloaded by signed URL.
+	URL(key string) string
+	// SignedURL returns URL granting read access to object until expires.
+	SignedURL(key string, expires time.Time) (string, error)
+}
+
+// PutOptions specific how object is written.
+//
+// ContentType specific media type of object, it is detected by storage if empty.
+// Public specific whether object can be read by anonymous users.
+type PutOptions struct {
+	ContentType string
+	Public      bool
+}
+
+// ObjectInfo specific information of a stored object.
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	UpdatedAt   time.Time
+}
+
+// New returns Storage based on driver in config.
+//
+// client is only required with firebase driver.
+func New(cfg *config.Config, client *fbstorage.Client) (Storage, error) {
+	switch cfg.Storage.Driver {
+	case DriverFirebase, "":
+		return NewGCSStorage(client, cfg.FirebaseStorage.BucketName)
+	case DriverLocal:
+		return NewLocalStorage(cfg.Storage.Local.RootDir, cfg.Storage.Local.BaseURL, cfg.Storage.Local.SigningKey)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDriver, cfg.Storage.Driver)
+	}
+}
@@ -0,0 +1,248 @@
+package videoprobe
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// Handler types of MP4 tracks.
+const (
+	handlerVideo = "vide"
+	handlerAudio = "soun"
+)
+
+// mp4Codecs maps MP4 sample entry types to codec names.
+var mp4Codecs = map[string]string{
+	"avc1": "h264",
+	"avc3": "h264",
+	"hvc1": "hevc",
+	"hev1": "hevc",
+	"av01": "av1",
+	"vp08": "vp8",
+	"vp09": "vp9",
+	"mp4v": "mpeg4",
+	"mp4a": "aac",
+	"ac-3": "ac3",
+	"ec-3": "eac3",
+	"Opus": "opus",
+	"fLaC": "flac",
+}
+
+// probeMP4 reads metadata from moov box of ISO base media file, see ISO/IEC 14496-12.
+func probeMP4(r *reader) (*Info, error) {
+	first := true
+
+	for {
+		// file without moov box ends here
+		boxType, payloadSize, err := readBoxHeader(r)
+		if err != nil {
+			return nil, ErrInvalidContainer
+		}
+
+		// file type box must be the first one
+		if first && boxType != "ftyp" {
+			return nil, ErrInvalidContainer
+		}
+
+		first = false
+
+		if boxType != "moov" {
+			if err = r.skip(payloadSize); err != nil {
+				return nil, ErrInvalidContainer
+			}
+
+			continue
+		}
+
+		moov, err := r.readFull(payloadSize)
+		if err != nil {
+			return nil, ErrInvalidContainer
+		}
+
+		return parseMoov(moov)
+	}
+}
+
+// readBoxHeader returns type and payload size of next box, size of last box may extend to end of file.
+func readBoxHeader(r *reader) (string, int64, error) {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return "", 0, err
+	}
+
+	size := int64(binary.BigEndian.Uint32(header[:4]))
+	boxType := string(header[4:])
+	headerSize := int64(8)
+
+	switch size {
+	case 0:
+		// box extends to end of file, only media data does so
+		return boxType, maxHeaderSize + 1, nil
+	case 1:
+		var largeSize [8]byte
+		if _, err := io.ReadFull(r, largeSize[:]); err != nil {
+			return "", 0, err
+		}
+
+		size = int64(binary.BigEndian.Uint64(largeSize[:]))
+		headerSize += 8
+	}
+
+	if size < headerSize {
+		return "", 0, ErrInvalidContainer
+	}
+
+	return boxType, size - headerSize, nil
+}
+
+// boxes returns child boxes of payload by type, only first box of each type is kept.
+func boxes(payload []byte) map[string][]byte {
+	res := map[string][]byte{}
+
+	for len(payload) >= 8 {
+		size := int(binary.BigEndian.Uint32(payload[:4]))
+		boxType := string(payload[4:8])
+		headerSize := 8
+
+		if size == 1 && len(payload) >= 16 {
+			size = int(binary.BigEndian.Uint64(payload[8:16]))
+			headerSize = 16
+		} else if size == 0 {
+			size = len(payload)
+		}
+
+		if size < headerSize || size > len(payload) {
+			break
+		}
+
+		if _, ok := res[boxType]; !ok {
+			res[boxType] = payload[headerSize:size]
+		}
+
+		payload = payload[size:]
+	}
+
+	return res
+}
+
+// allBoxes returns all child boxes of payload with boxType.
+func allBoxes(payload []byte, boxType string) [][]byte {
+	var res [][]byte
+
+	for len(payload) >= 8 {
+		size := int(binary.BigEndian.Uint32(payload[:4]))
+		if size < 8 || size > len(payload) {
+			break
+		}
+
+		if string(payload[4:8]) == boxType {
+			res = append(res, payload[8:size])
+		}
+
+		payload = payload[size:]
+	}
+
+	return res
+}
+
+func parseMoov(moov []byte) (*Info, error) {
+	children := boxes(moov)
+
+	info := &Info{}
+
+	if mvhd, ok := children["mvhd"]; ok {
+		info.Duration = parseDuration(mvhd)
+	}
+
+	for _, trak := range allBoxes(moov, "trak") {
+		mdia := boxes(boxes(trak)["mdia"])
+		stbl := boxes(boxes(mdia["minf"])["stbl"])
+
+		handler := parseHandler(mdia["hdlr"])
+		codec, width, height := parseSampleDescription(stbl["stsd"])
+
+		switch {
+		case handler == handlerVideo && info.VideoCodec == "":
+			info.VideoCodec = codec
+			info.Width, info.Height = width, height
+
+			if info.Duration == 0 {
+				info.Duration = parseDuration(mdia["mdhd"])
+			}
+		case handler == handlerAudio && info.AudioCodec == "":
+			info.AudioCodec = codec
+		}
+	}
+
+	return info, nil
+}
+
+// parseDuration returns duration of a movie or media header box.
+func parseDuration(header []byte) time.Duration {
+	if len(header) < 4 {
+		return 0
+	}
+
+	var timescale, duration uint64
+
+	// version 1 uses 64 bits creation, modification time and duration
+	if header[0] == 1 {
+		if len(header) < 32 {
+			return 0
+		}
+
+		timescale = uint64(binary.BigEndian.Uint32(header[20:24]))
+		duration = binary.BigEndian.Uint64(header[24:32])
+	} else {
+		if len(header) < 20 {
+			return 0
+		}
+
+		timescale = uint64(binary.BigEndian.Uint32(header[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(header[16:20]))
+	}
+
+	// all bits set means duration is unknown
+	if timescale == 0 || duration == 0xffffffff || duration == 0xffffffffffffffff {
+		return 0
+	}
+
+	return time.Duration(float64(duration) / float64(timescale) * float64(time.Second))
+}
+
+func parseHandler(hdlr []byte) string {
+	// version and flags, pre defined then handler type
+	if len(hdlr) < 12 {
+		return ""
+	}
+
+	return string(hdlr[8:12])
+}
+
+// parseSampleDescription returns codec of first sample entry, with dimensions if it is a visual sample entry.
+func parseSampleDescription(stsd []byte) (string, int, int) {
+	// version and flags then entry count
+	if len(stsd) < 16 {
+		return "", 0, 0
+	}
+
+	entry := stsd[8:]
+	entryType := string(entry[4:8])
+
+	codec, ok := mp4Codecs[entryType]
+	if !ok {
+		codec = entryType
+	}
+
+	// reserved, data reference index, pre defined and reserved fields come before width and height
+	const dimensionOffset = 8 + 6 + 2 + 16
+	if len(entry) < dimensionOffset+4 {
+		return codec, 0, 0
+	}
+
+	width := int(binary.BigEndian.Uint16(entry[dimensionOffset:]))
+	height := int(binary.BigEndian.Uint16(entry[dimensionOffset+2:]))
+
+	return codec, width, height
+}
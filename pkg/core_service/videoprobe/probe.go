@@ -0,0 +1,144 @@
+// Package videoprobe reads metadata of MP4 and WebM videos from their container, frames are never decoded.
+package videoprobe
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"time"
+)
+
+// Containers supported by Probe.
+const (
+	ContainerMP4  = "mp4"
+	ContainerWebM = "webm"
+)
+
+// maxHeaderSize limits size of a metadata element kept in memory, e.g. MP4 moov box.
+const maxHeaderSize = 32 << 20
+
+var (
+	ErrInvalidContainer     = errors.New("invalid video container")
+	ErrUnsupportedContainer = errors.New("unsupported video container")
+	ErrNoVideoTrack         = errors.New("no video track")
+)
+
+// Info define metadata of a video.
+//
+// Duration is zero if container doesn't declare it, e.g. WebM recorded by browsers.
+// Bitrate specific average bits per second of whole file, zero if duration is unknown.
+type Info struct {
+	Container  string
+	Duration   time.Duration
+	Width      int
+	Height     int
+	VideoCodec string
+	AudioCodec string
+	Bitrate    int64
+}
+
+// Probe reads metadata of video r in container, size is total bytes of r.
+//
+// Media data is skipped by seeking when r is an io.Seeker, otherwise it is read and discarded.
+func Probe(r io.Reader, container string, size int64) (*Info, error) {
+	var (
+		info *Info
+		err  error
+	)
+
+	switch container {
+	case ContainerMP4:
+		info, err = probeMP4(newReader(r))
+	case ContainerWebM:
+		info, err = probeWebM(newReader(r))
+	default:
+		return nil, ErrUnsupportedContainer
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if info.VideoCodec == "" || info.Width <= 0 || info.Height <= 0 {
+		return nil, ErrNoVideoTrack
+	}
+
+	info.Container = container
+
+	if info.Duration > 0 {
+		info.Bitrate = int64(float64(size*8) / info.Duration.Seconds())
+	}
+
+	return info, nil
+}
+
+// reader reads container elements, it tracks offset so elements can be skipped.
+type reader struct {
+	src    io.Reader
+	br     *bufio.Reader
+	seeker io.Seeker
+	offset int64
+}
+
+func newReader(r io.Reader) *reader {
+	seeker, _ := r.(io.Seeker)
+
+	return &reader{src: r, br: bufio.NewReader(r), seeker: seeker}
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.br.Read(p)
+	r.offset += int64(n)
+
+	return n, err
+}
+
+func (r *reader) ReadByte() (byte, error) {
+	b, err := r.br.ReadByte()
+	if err == nil {
+		r.offset++
+	}
+
+	return b, err
+}
+
+// readFull reads n bytes, io.ErrUnexpectedEOF is returned if r ends before.
+func (r *reader) readFull(n int64) ([]byte, error) {
+	if n > maxHeaderSize {
+		return nil, ErrInvalidContainer
+	}
+
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// skip moves n bytes forward.
+func (r *reader) skip(n int64) error {
+	if n < 0 {
+		return ErrInvalidContainer
+	}
+
+	buffered := int64(r.br.Buffered())
+	if r.seeker == nil || n <= buffered {
+		copied, err := io.CopyN(io.Discard, r, n)
+		if err == io.EOF && copied < n {
+			return io.ErrUnexpectedEOF
+		}
+
+		return err
+	}
+
+	// position of underlying reader is ahead of offset by buffered bytes
+	if _, err := r.seeker.Seek(n-buffered, io.SeekCurrent); err != nil {
+		return err
+	}
+
+	r.br.Reset(r.src)
+	r.offset += n
+
+	return nil
+}
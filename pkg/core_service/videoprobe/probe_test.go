@@ -0,0 +1,148 @@
+package videoprobe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func box(boxType string, payload ...[]byte) []byte {
+	content := bytes.Join(payload, nil)
+	res := binary.BigEndian.AppendUint32(nil, uint32(8+len(content)))
+
+	return append(append(res, boxType...), content...)
+}
+
+func mp4Track(handler, sampleEntry string, width, height uint16) []byte {
+	hdlr := append(make([]byte, 8), handler...)
+	hdlr = append(hdlr, make([]byte, 12)...)
+
+	entry := make([]byte, 24)
+	entry = binary.BigEndian.AppendUint16(entry, width)
+	entry = binary.BigEndian.AppendUint16(entry, height)
+	stsd := append(binary.BigEndian.AppendUint32(make([]byte, 4), 1), box(sampleEntry, entry)...)
+
+	return box("trak", box("mdia", box("hdlr", hdlr), box("minf", box("stbl", box("stsd", stsd)))))
+}
+
+func testMP4(moovFirst bool) []byte {
+	// version 0 header with timescale 1000 and duration 2500
+	mvhd := make([]byte, 12)
+	mvhd = binary.BigEndian.AppendUint32(mvhd, 1000)
+	mvhd = binary.BigEndian.AppendUint32(mvhd, 2500)
+
+	ftyp := box("ftyp", []byte("isom\x00\x00\x02\x00mp41"))
+	moov := box("moov", box("mvhd", mvhd), mp4Track("soun", "mp4a", 0, 0), mp4Track("vide", "avc1", 1280, 720))
+	mdat := box("mdat", bytes.Repeat([]byte{1}, 4096))
+
+	if moovFirst {
+		return bytes.Join([][]byte{ftyp, moov, mdat}, nil)
+	}
+
+	return bytes.Join([][]byte{ftyp, mdat, moov}, nil)
+}
+
+func element(id uint64, payload ...[]byte) []byte {
+	content := bytes.Join(payload, nil)
+
+	var res []byte
+	for shift := 24; shift >= 0; shift -= 8 {
+		if b := byte(id >> shift); b != 0 || len(res) > 0 {
+			res = append(res, b)
+		}
+	}
+
+	// 8 bytes size
+	res = append(res, 0x01)
+	res = append(res, binary.BigEndian.AppendUint64(nil, uint64(len(content)))[1:]...)
+
+	return append(res, content...)
+}
+
+func testWebM() []byte {
+	duration := binary.BigEndian.AppendUint64(nil, math.Float64bits(1500))
+
+	return bytes.Join(
+		[][]byte{
+			element(idEBML, element(idDocType, []byte("webm"))),
+			element(
+				idSegment,
+				element(0x114d9b74, []byte("seek head")),
+				element(idInfo, element(idTimecodeScale, []byte{0x0f, 0x42, 0x40}), element(idDuration, duration)),
+				element(
+					idTracks,
+					element(idTrackEntry, element(idTrackType, []byte{2}), element(idCodecID, []byte("A_OPUS"))),
+					element(
+						idTrackEntry,
+						element(idTrackType, []byte{1}),
+						element(idCodecID, []byte("V_VP9")),
+						element(idVideo, element(idPixelWidth, []byte{0x07, 0x80}), element(idPixelHeight, []byte{0x04, 0x38})),
+					),
+				),
+				element(idCluster, []byte("frames")),
+			),
+		}, nil,
+	)
+}
+
+func TestProbe(t *testing.T) {
+	t.Run(
+		"mp4", func(t *testing.T) {
+			for _, moovFirst := range []bool{true, false} {
+				data := testMP4(moovFirst)
+
+				info, err := Probe(bytes.NewReader(data), ContainerMP4, int64(len(data)))
+				assert.NoError(t, err)
+				assert.Equal(
+					t, &Info{
+						Container:  ContainerMP4,
+						Duration:   2500 * time.Millisecond,
+						Width:      1280,
+						Height:     720,
+						VideoCodec: "h264",
+						AudioCodec: "aac",
+						Bitrate:    int64(len(data)) * 8 * 2 / 5,
+					}, info,
+				)
+			}
+		},
+	)
+
+	t.Run(
+		"webm", func(t *testing.T) {
+			data := testWebM()
+
+			// a plain reader can't seek, unused elements are read and discarded
+			info, err := Probe(bytes.NewBuffer(data), ContainerWebM, int64(len(data)))
+			assert.NoError(t, err)
+			assert.Equal(t, 1500*time.Millisecond, info.Duration)
+			assert.Equal(t, 1920, info.Width)
+			assert.Equal(t, 1080, info.Height)
+			assert.Equal(t, "vp9", info.VideoCodec)
+			assert.Equal(t, "opus", info.AudioCodec)
+		},
+	)
+
+	t.Run(
+		"container mismatch", func(t *testing.T) {
+			_, err := Probe(bytes.NewReader(testWebM()), ContainerMP4, 0)
+			assert.ErrorIs(t, err, ErrInvalidContainer)
+
+			_, err = Probe(bytes.NewReader(testMP4(true)), ContainerWebM, 0)
+			assert.ErrorIs(t, err, ErrInvalidContainer)
+		},
+	)
+
+	t.Run(
+		"no video track", func(t *testing.T) {
+			data := bytes.Join([][]byte{box("ftyp", []byte("M4A ")), box("moov", mp4Track("soun", "mp4a", 0, 0))}, nil)
+
+			_, err := Probe(bytes.NewReader(data), ContainerMP4, 0)
+			assert.ErrorIs(t, err, ErrNoVideoTrack)
+		},
+	)
+}
@@ -0,0 +1,275 @@
+package videoprobe
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+)
+
+// EBML element IDs used by WebM, see https://www.matroska.org/technical/elements.html.
+const (
+	idEBML          = 0x1a45dfa3
+	idDocType       = 0x4282
+	idSegment       = 0x18538067
+	idInfo          = 0x1549a966
+	idTimecodeScale = 0x2ad7b1
+	idDuration      = 0x4489
+	idTracks        = 0x1654ae6b
+	idTrackEntry    = 0xae
+	idTrackType     = 0x83
+	idCodecID       = 0x86
+	idVideo         = 0xe0
+	idPixelWidth    = 0xb0
+	idPixelHeight   = 0xba
+	idCluster       = 0x1f43b675
+
+	trackTypeVideo = 1
+	trackTypeAudio = 2
+
+	defaultTimecodeScale = 1000000
+
+	// unknownSize is size of element without declared size, e.g. segment of live stream.
+	unknownSize = -1
+)
+
+// webmCodecs maps Matroska codec IDs to codec names.
+var webmCodecs = map[string]string{
+	"V_VP8":    "vp8",
+	"V_VP9":    "vp9",
+	"V_AV1":    "av1",
+	"A_OPUS":   "opus",
+	"A_VORBIS": "vorbis",
+}
+
+// probeWebM reads metadata from Info and Tracks elements of WebM segment.
+func probeWebM(r *reader) (*Info, error) {
+	id, size, err := readElementHeader(r)
+	if err != nil || id != idEBML || size == unknownSize {
+		return nil, ErrInvalidContainer
+	}
+
+	header, err := r.readFull(size)
+	if err != nil {
+		return nil, ErrInvalidContainer
+	}
+
+	if docType, _ := findElement(header, idDocType); string(docType) != ContainerWebM {
+		return nil, ErrInvalidContainer
+	}
+
+	id, size, err = readElementHeader(r)
+	if err != nil || id != idSegment {
+		return nil, ErrInvalidContainer
+	}
+
+	info := &Info{}
+	timecodeScale := uint64(defaultTimecodeScale)
+
+	var (
+		duration               float64
+		foundInfo, foundTracks bool
+	)
+
+	// top level elements of segment, metadata comes before clusters of frames in practice
+	for !foundInfo || !foundTracks {
+		id, size, err = readElementHeader(r)
+		if err != nil || id == idCluster || size == unknownSize {
+			break
+		}
+
+		if id != idInfo && id != idTracks {
+			if err = r.skip(size); err != nil {
+				return nil, ErrInvalidContainer
+			}
+
+			continue
+		}
+
+		payload, err := r.readFull(size)
+		if err != nil {
+			return nil, ErrInvalidContainer
+		}
+
+		if id == idInfo {
+			foundInfo = true
+
+			if value, ok := findElement(payload, idTimecodeScale); ok {
+				timecodeScale = readUint(value)
+			}
+
+			if value, ok := findElement(payload, idDuration); ok {
+				duration = readFloat(value)
+			}
+
+			continue
+		}
+
+		foundTracks = true
+		parseTracks(payload, info)
+	}
+
+	if !foundTracks {
+		return nil, ErrInvalidContainer
+	}
+
+	info.Duration = time.Duration(duration * float64(timecodeScale))
+
+	return info, nil
+}
+
+func parseTracks(tracks []byte, info *Info) {
+	for _, entry := range findElements(tracks, idTrackEntry) {
+		trackType, _ := findElement(entry, idTrackType)
+		codecID, _ := findElement(entry, idCodecID)
+
+		codec, ok := webmCodecs[string(codecID)]
+		if !ok {
+			codec = string(codecID)
+		}
+
+		switch readUint(trackType) {
+		case trackTypeVideo:
+			if info.VideoCodec != "" {
+				continue
+			}
+
+			info.VideoCodec = codec
+
+			video, _ := findElement(entry, idVideo)
+			width, _ := findElement(video, idPixelWidth)
+			height, _ := findElement(video, idPixelHeight)
+			info.Width, info.Height = int(readUint(width)), int(readUint(height))
+		case trackTypeAudio:
+			if info.AudioCodec == "" {
+				info.AudioCodec = codec
+			}
+		}
+	}
+}
+
+// readElementHeader reads ID and size of next element.
+func readElementHeader(r io.ByteReader) (uint64, int64, error) {
+	// IDs keep their length marker
+	id, _, _, err := readVarint(r)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	_, size, allOnes, err := readVarint(r)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if allOnes {
+		return id, unknownSize, nil
+	}
+
+	if size > math.MaxInt64 {
+		return 0, 0, ErrInvalidContainer
+	}
+
+	return id, int64(size), nil
+}
+
+// readVarint reads an EBML variable size integer, it returns raw bytes including length marker and the value.
+//
+// allOnes reports whether all value bits are set, which means unknown size.
+func readVarint(r io.ByteReader) (raw, value uint64, allOnes bool, err error) {
+	first, err := r.ReadByte()
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	length := 1
+	for mask := byte(0x80); length <= 8 && first&mask == 0; mask >>= 1 {
+		length++
+	}
+
+	if length > 8 {
+		return 0, 0, false, ErrInvalidContainer
+	}
+
+	raw = uint64(first)
+
+	for i := 1; i < length; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, 0, false, err
+		}
+
+		raw = raw<<8 | uint64(b)
+	}
+
+	valueBits := uint(7 * length)
+	value = raw & (1<<valueBits - 1)
+
+	return raw, value, value == 1<<valueBits-1, nil
+}
+
+// findElements returns payloads of all child elements of payload with id.
+func findElements(payload []byte, id uint64) [][]byte {
+	var res [][]byte
+
+	r := &byteReader{data: payload}
+
+	for r.pos < len(payload) {
+		childID, size, err := readElementHeader(r)
+		if err != nil || size == unknownSize || int64(r.pos)+size > int64(len(payload)) {
+			break
+		}
+
+		if childID == id {
+			res = append(res, payload[r.pos:r.pos+int(size)])
+		}
+
+		r.pos += int(size)
+	}
+
+	return res
+}
+
+func findElement(payload []byte, id uint64) ([]byte, bool) {
+	elements := findElements(payload, id)
+	if len(elements) == 0 {
+		return nil, false
+	}
+
+	return elements[0], true
+}
+
+func readUint(value []byte) uint64 {
+	var res uint64
+	for _, b := range value {
+		res = res<<8 | uint64(b)
+	}
+
+	return res
+}
+
+func readFloat(value []byte) float64 {
+	switch len(value) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(value)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(value))
+	default:
+		return 0
+	}
+}
+
+type byteReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *byteReader) ReadByte() (byte, error) {
+	if r.pos >= len(r.data) {
+		return 0, io.EOF
+	}
+
+	b := r.data[r.pos]
+	r.pos++
+
+	return b, nil
+}